@@ -1,17 +1,33 @@
 // icecake server CLI
 //
-// Run the werserver
+// Run the werserver, or generate the reflection-free code of the composers of a package:
+//
+//	icecake [--env dev]
+//	icecake gen [-type T1,T2] [-o ick_gen.go] [dir]
+//
+// gen is usually called with a go:generate directive in the package of the composers:
+//
+//	//go:generate go run github.com/icecake-framework/icecake/cmd/icecake gen
 package main
 
 import (
 	"flag"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/icecake-framework/icecake/internal/ickgen"
 	"github.com/icecake-framework/icecake/pkg/ickserver"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "gen" {
+		gen(os.Args[2:])
+		return
+	}
+
 	// get --env flag
 	strenv := "dev"
 	env := flag.String("env", "dev", ".env environement file to load, with the path and without the extension. dev by default.")
@@ -33,3 +49,28 @@ func main() {
 	// Let's start the server, listen requests and serve answers
 	spa.Run()
 }
+
+// gen generates the factories, the ApplyAttributes and the SchemaDoc methods of the composers of the package in dir, the current one by default.
+func gen(args []string) {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	typs := flags.String("type", "", "comma separated list of the composers to generate. The registered ones by default.")
+	output := flags.String("o", ickgen.FILENAME, "name of the generated file, in dir.")
+	flags.Parse(args)
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+	var typenames []string
+	if *typs != "" {
+		typenames = strings.Split(*typs, ",")
+	}
+
+	src, err := ickgen.Generate(dir, typenames...)
+	if err != nil {
+		log.Fatalf("icecake gen: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, *output), src, 0644); err != nil {
+		log.Fatalf("icecake gen: %s", err)
+	}
+}
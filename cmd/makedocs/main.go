@@ -22,6 +22,9 @@ func main() {
 	outpathparam := flag.String("output", "", "output path where generated html files will be saved")
 	flag.BoolVar(&verbose.IsOn, "verbose", false, "print out execution details")
 	flag.BoolVar(&verbose.IsDebugging, "debug", false, "print out debugging info")
+	strict := flag.Bool("strict", false, "fails if the rendering of any page raises diagnostics")
+	tracefile := flag.String("trace", "", "file where the rendering trace will be saved in the Chrome trace event format, to load in chrome://tracing or Perfetto")
+	schemafile := flag.String("schema", "", "file where the JSON schemas of the registered composers will be saved")
 	strenv := flag.String("env", "dev", ".env environement file to load, with the path and without the extension. dev by default.")
 	flag.Parse()
 
@@ -41,6 +44,10 @@ func main() {
 	outpath := helper.MustCheckOutputPath(outpathparam)
 
 	web := ick.NewWebSite(outpath)
+	web.Strict = *strict
+	if verbose.IsOn || *tracefile != "" {
+		web.Tracer = new(ickcore.RenderTracer)
+	}
 
 	// page index
 	pgindex := web.AddPage("en", "index")
@@ -49,7 +56,6 @@ func main() {
 	pgindex.AddHeadItem("meta", "charset=UTF-8")
 	pgindex.AddHeadItem("meta", `http-equiv="X-UA-Compatible" content="IE=edge"`)
 	pgindex.AddHeadItem("meta", `name="viewport" content="width=device-width, initial-scale=1.0"`)
-	pgindex.AddHeadItem("script", `type="text/javascript" src="`+web.ToAbsURLString("/assets/icecake.js")+`"`)
 
 	// ... with a hero section
 	hero := &ick.ICKHero{
@@ -78,38 +84,22 @@ func main() {
 	menu.AddItem("docbaresnippet", ick.MENUIT_LINK, "BareSnippet")
 	menu.AddItem("docpage", ick.MENUIT_LINK, "Page")
 	menu.AddItem("", ick.MENUIT_LABEL, "Core Snippets")
-	menu.AddItem("docbutton", ick.MENUIT_LINK, "Button").HRef = web.ToAbsURL("/docbutton.html")
-	menu.AddItem("doccard", ick.MENUIT_LINK, "Card").HRef = web.ToAbsURL("/doccard.html")
-	menu.AddItem("docdelete", ick.MENUIT_LINK, "Delete").HRef = web.ToAbsURL("/docdelete.html")
-	menu.AddItem("dochero", ick.MENUIT_LINK, "Hero").HRef = web.ToAbsURL("/dochero.html")
-	menu.AddItem("docimage", ick.MENUIT_LINK, "Image").HRef = web.ToAbsURL("/docimage.html")
-	menu.AddItem("docmenu", ick.MENUIT_LINK, "Menu").HRef = web.ToAbsURL("/docmenu.html")
-	menu.AddItem("docmessage", ick.MENUIT_LINK, "Message").HRef = web.ToAbsURL("/docmessage.html")
-	menu.AddItem("docnavbar", ick.MENUIT_LINK, "Navbar").HRef = web.ToAbsURL("/docnavbar.html")
-	menu.AddItem("docinput", ick.MENUIT_LINK, "Input").HRef = web.ToAbsURL("/docinput.html")
-	menu.AddItem("docicon", ick.MENUIT_LINK, "Icon").HRef = web.ToAbsURL("/docicon.html")
-	menu.AddItem("doctaglabel", ick.MENUIT_LINK, "Tag Label").HRef = web.ToAbsURL("/doctaglabel.html")
-	menu.AddItem("docmedia", ick.MENUIT_LINK, "media").HRef = web.ToAbsURL("/docmedia.html")
+	components := webdocs.ComponentPages()
+	for _, c := range components {
+		menu.AddItem(c.Key, ick.MENUIT_LINK, c.Label).HRef = web.ToAbsURL("/" + c.Key + ".html")
+	}
 	menu.AddItem("", ick.MENUIT_FOOTER, "Alpha 4")
 
 	// page docs
 	addPageDoc(web, menu.Clone(), "docoverview")
-	addPageDoc(web, menu.Clone(), "docbutton")
-	addPageDoc(web, menu.Clone(), "doccard")
-	addPageDoc(web, menu.Clone(), "docdelete")
-	addPageDoc(web, menu.Clone(), "dochero")
-	addPageDoc(web, menu.Clone(), "docimage")
-	addPageDoc(web, menu.Clone(), "docmenu")
-	addPageDoc(web, menu.Clone(), "docmessage")
-	addPageDoc(web, menu.Clone(), "docnavbar")
-	addPageDoc(web, menu.Clone(), "docinput")
-	addPageDoc(web, menu.Clone(), "docicon")
-	addPageDoc(web, menu.Clone(), "doctaglabel")
-	addPageDoc(web, menu.Clone(), "docmedia")
+	for _, c := range components {
+		addPageDoc(web, menu.Clone(), c.Key)
+	}
 
 	// required files
 	ickcore.RequireCSSFile("https://cdn.jsdelivr.net/npm/bootstrap-icons@1.10.5/font/bootstrap-icons.css")
 	ickcore.RequireCSSFile(web.ToAbsURLString("/assets/docs.css"))
+	ickcore.RequireJSFile(web.ToAbsURLString("/assets/icecake.js"), ickcore.JSOptions{})
 
 	// copy assets
 	err := web.CopyToAssets("./website/docs/assets/", "./website/docs/sass/docs.css", "./website/docs/sass/docs.css.map")
@@ -121,7 +111,7 @@ func main() {
 	// writing files
 	n, err := web.WriteFiles()
 	if err != nil {
-		fmt.Println("makedoc fails")
+		fmt.Println("makedoc fails:", err.Error())
 		if !verbose.IsOn {
 			fmt.Println("use the verbose flag to get more info")
 		}
@@ -129,6 +119,45 @@ func main() {
 	}
 
 	fmt.Println(n, "pages generated in ", time.Since(start))
+
+	// schemas of the registered composers
+	if *schemafile != "" {
+		data, err := ickcore.SchemasJSON()
+		if err == nil {
+			err = os.WriteFile(*schemafile, data, 0644)
+		}
+		if err != nil {
+			fmt.Println("makedoc fails to write the schemas:", err.Error())
+			os.Exit(1)
+		}
+		fmt.Println("composer schemas saved in", *schemafile)
+	}
+
+	// rendering trace
+	if verbose.IsOn {
+		fmt.Println("slowest components:")
+		web.Tracer.WriteSlowest(os.Stdout, 10)
+	}
+	if *tracefile != "" {
+		if err := writeTrace(web.Tracer, *tracefile); err != nil {
+			fmt.Println("makedoc fails to write the trace:", err.Error())
+			os.Exit(1)
+		}
+		fmt.Println("rendering trace saved in", *tracefile)
+	}
+}
+
+func writeTrace(tracer *ickcore.RenderTracer, filename string) (err error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errc := f.Close(); err == nil {
+			err = errc
+		}
+	}()
+	return tracer.WriteChromeTrace(f)
 }
 
 func addPageDoc(web *ick.WebSite, menu *ick.ICKMenu, pgkey string) {
@@ -136,7 +165,6 @@ func addPageDoc(web *ick.WebSite, menu *ick.ICKMenu, pgkey string) {
 	pg.AddHeadItem("meta", "charset=UTF-8")
 	pg.AddHeadItem("meta", `http-equiv="X-UA-Compatible" content="IE=edge"`)
 	pg.AddHeadItem("meta", `name="viewport" content="width=device-width, initial-scale=1.0"`)
-	pg.AddHeadItem("script", `type="text/javascript" src="/assets/icecake.js"`)
 
 	inside := ick.Elem("div", `class="columns is-mobile mb-0 pb-0"`,
 		ick.Elem("div", `class="column is-narrow mb-0 pb-0"`, menu.SetActiveItem(pgkey)),
@@ -0,0 +1,557 @@
+// Package ickgen generates the reflection-free code used by the registry to unfold ick-tags, see `icecake gen`.
+//
+// For every composer the generated file implements:
+//   - ickcore.ComposerFactory, instantiating the composer without reflect.New
+//   - ickcore.AttributeApplier, setting the properties from the attributes without reflect.FieldByIndex
+//   - ickcore.SchemaDocumenter, documenting the schema with the go doc of the composer and of its properties, unless the composer implements it
+//
+// Properties are mapped with the same rules as the registry, see the `ick` struct tag.
+package ickgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc/comment"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+// FILENAME is the default name of the generated file.
+const FILENAME string = "ick_gen.go"
+
+// HEADER starts the generated file. Files starting with HEADER are ignored when parsing the package.
+const HEADER string = "// Code generated by icecake gen. DO NOT EDIT."
+
+const ickcorePath string = "github.com/icecake-framework/icecake/pkg/ickcore"
+
+// registerfuncs are the functions registering a composer, scanned to find the composers of a package.
+var registerfuncs = map[string]bool{"RegisterComposer": true, "Register": true, "AddRegistryEntry": true, "AddEntry": true}
+
+// property is a property of a composer, see ickcore.ickproperty.
+type property struct {
+	name  string     // attribute's name
+	field string     // go field's name
+	path  []string   // fields selector from the composer
+	typ   types.Type // type of the field
+	pos   token.Pos  // position of the field's name
+}
+
+// generator generates the file for a single package.
+type generator struct {
+	pkg     *types.Package
+	imports map[string]string    // imported packages, name by path
+	docs    map[token.Pos]string // go doc of the types and of the fields, by position of their name
+	buf     bytes.Buffer
+}
+
+// Generate type-checks the go package in dir and returns the source of the generated file for the composers named in typenames.
+// If typenames is empty, the composers registered in the package with ickcore.RegisterComposer are generated.
+func Generate(dir string, typenames ...string) ([]byte, error) {
+	fset := token.NewFileSet()
+	files, err := parsepackage(fset, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgpath, err := importpath(dir)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(pkgpath, fset, files, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ickgen: %w", err)
+	}
+
+	if len(typenames) == 0 {
+		typenames = registered(files)
+	}
+	if len(typenames) == 0 {
+		return nil, fmt.Errorf("ickgen: no registered composer found in %s", pkg.Name())
+	}
+
+	g := &generator{pkg: pkg, imports: map[string]string{"fmt": "fmt", "strings": "strings"}, docs: godocs(files)}
+	for _, name := range typenames {
+		if err := g.composer(name); err != nil {
+			return nil, fmt.Errorf("ickgen: %s: %w", name, err)
+		}
+	}
+	return g.source()
+}
+
+// parsepackage parses the go files of the package in dir matching the build constraints, but the test files and the generated one.
+func parsepackage(fset *token.FileSet, dir string) ([]*ast.File, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ickgen: %w", err)
+	}
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("ickgen: %w", err)
+		}
+		if len(f.Comments) > 0 && strings.HasPrefix(f.Comments[0].List[0].Text, HEADER) {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// importpath returns the import path of the package in dir, according to the module path found in go.mod.
+func importpath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		f, err := os.Open(filepath.Join(root, "go.mod"))
+		if err == nil {
+			defer f.Close()
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				if module, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); found {
+					rel, _ := filepath.Rel(root, abs)
+					return path.Join(strings.Trim(strings.TrimSpace(module), `"`), filepath.ToSlash(rel)), nil
+				}
+			}
+			return "", fmt.Errorf("ickgen: module path missing in %s", f.Name())
+		}
+		if root == filepath.Dir(root) {
+			return "", fmt.Errorf("ickgen: go.mod not found for %s", dir)
+		}
+	}
+}
+
+// registered returns the names of the types registered in files, like `ickcore.RegisterComposer("ick-button", &ICKButton{})`, sorted.
+func registered(files []*ast.File) []string {
+	found := make(map[string]bool)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
+			}
+			var fname string
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				fname = fun.Sel.Name
+			case *ast.Ident:
+				fname = fun.Name
+			}
+			if !registerfuncs[fname] {
+				return true
+			}
+			switch arg := call.Args[1].(type) {
+			case *ast.UnaryExpr:
+				if lit, ok := arg.X.(*ast.CompositeLit); ok && arg.Op == token.AND {
+					if id, ok := lit.Type.(*ast.Ident); ok {
+						found[id.Name] = true
+					}
+				}
+			case *ast.CallExpr:
+				if id, ok := arg.Fun.(*ast.Ident); ok && id.Name == "new" && len(arg.Args) == 1 {
+					if tid, ok := arg.Args[0].(*ast.Ident); ok {
+						found[tid.Name] = true
+					}
+				}
+			}
+			return true
+		})
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// godocs returns the go doc of the types and of the struct fields declared in files, by position of their name.
+// The position of an embedded field is the one of the name of its type.
+func godocs(files []*ast.File) map[token.Pos]string {
+	docs := make(map[token.Pos]string)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.GenDecl:
+				for _, spec := range n.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						doc := ts.Doc
+						if doc == nil && len(n.Specs) == 1 {
+							doc = n.Doc
+						}
+						docs[ts.Name.Pos()] = doc.Text()
+					}
+				}
+			case *ast.Field:
+				doc := n.Doc.Text()
+				if doc == "" {
+					doc = n.Comment.Text()
+				}
+				for _, name := range n.Names {
+					docs[name.Pos()] = doc
+				}
+				if len(n.Names) == 0 {
+					if name := typename(n.Type); name != nil {
+						docs[name.Pos()] = doc
+					}
+				}
+			}
+			return true
+		})
+	}
+	return docs
+}
+
+// typename returns the name of the type of an embedded field, nil if not found.
+func typename(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.StarExpr:
+		return typename(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	}
+	return nil
+}
+
+// synopsis returns the first paragraph of the go doc as a single line of text, ignoring the TODO lines.
+func synopsis(doc string) string {
+	lines := strings.Split(doc, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "TODO") {
+			kept = append(kept, line)
+		}
+	}
+	var parser comment.Parser
+	for _, block := range parser.Parse(strings.Join(kept, "\n")).Content {
+		if p, ok := block.(*comment.Paragraph); ok {
+			return strings.Join(strings.Fields(plaintext(p.Text)), " ")
+		}
+	}
+	return ""
+}
+
+// plaintext returns the text of a go doc paragraph, without the link targets.
+func plaintext(text []comment.Text) string {
+	var s strings.Builder
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			s.WriteString(string(t))
+		case comment.Italic:
+			s.WriteString(string(t))
+		case *comment.Link:
+			s.WriteString(plaintext(t.Text))
+		case *comment.DocLink:
+			s.WriteString(plaintext(t.Text))
+		}
+	}
+	return s.String()
+}
+
+// properties returns the properties of the struct st, with the rules of the registry.
+func properties(st *types.Struct) []*property {
+	list := make([]*property, 0)
+	depth := make(map[string]int) // depth of the listed fields, by field's name
+	var parse func(st *types.Struct, path []string)
+	parse = func(st *types.Struct, path []string) {
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			tag, hastag := reflect.StructTag(st.Tag(i)).Lookup("ick")
+			if tag == "-" {
+				continue
+			}
+			fpath := append(append(make([]string, 0, len(path)+1), path...), f.Name())
+
+			if f.Anonymous() && !hastag {
+				if _, isptr := f.Type().(*types.Pointer); isptr {
+					continue
+				}
+				if sub, isstruct := f.Type().Underlying().(*types.Struct); isstruct {
+					parse(sub, fpath)
+					continue
+				}
+			}
+			if !f.Exported() {
+				continue
+			}
+
+			// the shallower field hides the deeper one
+			d, found := depth[f.Name()]
+			if found && d <= len(fpath) {
+				continue
+			}
+			if found {
+				for i, p := range list {
+					if p.field == f.Name() {
+						list = append(list[:i], list[i+1:]...)
+						break
+					}
+				}
+			}
+			depth[f.Name()] = len(fpath)
+			name, _, _, _ := ickcore.ParsePropertyTag(f.Name(), tag)
+			list = append(list, &property{name: name, field: f.Name(), path: fpath, typ: f.Type(), pos: f.Pos()})
+		}
+	}
+	parse(st, nil)
+	return list
+}
+
+// composer generates the factory and the ApplyAttributes method of the composer name.
+func (g *generator) composer(name string) error {
+	obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return fmt.Errorf("type not found")
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return fmt.Errorf("not a struct")
+	}
+
+	var code, docs strings.Builder
+	names := make(map[string]bool)
+	for _, p := range properties(st) {
+		if names[p.name] {
+			// the first property with the name hides the others, like in the registry
+			continue
+		}
+		names[p.name] = true
+		if doc := synopsis(g.docs[p.pos]); doc != "" {
+			fmt.Fprintf(&docs, "\t\t%q: %q,\n", p.field, doc)
+		}
+		conv, err := g.convert("cmp."+strings.Join(p.path, "."), p.typ)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.field, err)
+		}
+		fmt.Fprintf(&code, "\t\tcase %q:\n%s", p.name, conv)
+	}
+
+	core := g.pkgref(ickcorePath, "ickcore")
+	fmt.Fprintf(&g.buf, "\n// NewComposer returns a new %s, see ickcore.ComposerFactory.\n", name)
+	fmt.Fprintf(&g.buf, "func (*%s) NewComposer() %sComposer {\n\treturn new(%s)\n}\n", name, core, name)
+	fmt.Fprintf(&g.buf, "\n// ApplyAttributes sets the properties of %s from the attributes of its ick-tag, see ickcore.AttributeApplier.\n", name)
+	fmt.Fprintf(&g.buf, "func (cmp *%s) ApplyAttributes(attrs %sAttributeMap) (err error) {\n", name, core)
+	fmt.Fprintf(&g.buf, "\tfor name, value := range attrs {\n\t\tswitch strings.ToLower(name) {\n")
+	g.buf.WriteString(code.String())
+	fmt.Fprintf(&g.buf, "\t\tdefault:\n\t\t\terr = %sErrAttributeNotUnfolded\n\t\t}\n", core)
+	fmt.Fprintf(&g.buf, "\t\tif err != nil {\n\t\t\treturn fmt.Errorf(\"%%q attribute: %%w\", name, err)\n\t\t}\n\t}\n\treturn nil\n}\n")
+
+	if m, _, _ := types.LookupFieldOrMethod(types.NewPointer(obj.Type()), true, g.pkg, "SchemaDoc"); m != nil {
+		return nil
+	}
+	doc := synopsis(g.docs[obj.Pos()])
+	if doc == "" && docs.Len() == 0 {
+		return nil
+	}
+	fmt.Fprintf(&g.buf, "\n// SchemaDoc returns the doc of %s and of its properties, see ickcore.SchemaDocumenter.\n", name)
+	fmt.Fprintf(&g.buf, "func (*%s) SchemaDoc() (string, map[string]string) {\n\treturn %q, map[string]string{\n%s\t}\n}\n", name, doc, docs.String())
+	return nil
+}
+
+// convert returns the statements setting target of type typ from the string value, and setting err, like ickcore.updateproperty does.
+func (g *generator) convert(target string, typ types.Type) (string, error) {
+	core := g.pkgref(ickcorePath, "ickcore")
+	if types.Implements(types.NewPointer(typ), textunmarshaler) {
+		return fmt.Sprintf("\t\t\terr = %s.UnmarshalText([]byte(value))\n", target), nil
+	}
+
+	switch types.TypeString(typ, (*types.Package).Path) {
+	case ickcorePath + ".HTMLString":
+		return fmt.Sprintf("\t\t\t%s = *%sToHTML(value)\n", target, core), nil
+	case "time.Duration":
+		return fmt.Sprintf("\t\t\t%s, err = %sParseDuration(value)\n", target, g.pkgref("time", "time")), nil
+	case "*net/url.URL":
+		return fmt.Sprintf("\t\t\t%s, err = %sParse(value)\n", target, g.pkgref("net/url", "url")), nil
+	case "net/url.URL":
+		url := g.pkgref("net/url", "url")
+		return fmt.Sprintf("\t\t\tvar u *%sURL\n\t\t\tif u, err = %sParse(value); err == nil {\n\t\t\t\t%s = *u\n\t\t\t}\n", url, url, target), nil
+	}
+
+	tname, err := g.typename(typ)
+	if err != nil {
+		return "", err
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0:
+			if tname == "string" {
+				return fmt.Sprintf("\t\t\t%s = value\n", target), nil
+			}
+			return fmt.Sprintf("\t\t\t%s = %s(value)\n", target, tname), nil
+		case info&types.IsBoolean != 0:
+			if tname == "bool" {
+				return fmt.Sprintf("\t\t\t%s = %sUnfoldBool(value)\n", target, core), nil
+			}
+			return fmt.Sprintf("\t\t\t%s = %s(%sUnfoldBool(value))\n", target, tname, core), nil
+		case info&types.IsFloat != 0:
+			return fmt.Sprintf("\t\t\tvar f float64\n\t\t\tf, err = %sUnfoldFloat(value, %d)\n\t\t\t%s = %s\n", core, bits(u), target, conversion(tname, "float64", "f")), nil
+		case info&types.IsUnsigned != 0 && u.Kind() != types.Uintptr:
+			return fmt.Sprintf("\t\t\tvar i uint64\n\t\t\ti, err = %sUnfoldUint(value, %d)\n\t\t\t%s = %s\n", core, bits(u), target, conversion(tname, "uint64", "i")), nil
+		case info&types.IsInteger != 0 && info&types.IsUnsigned == 0:
+			return fmt.Sprintf("\t\t\tvar i int64\n\t\t\ti, err = %sUnfoldInt(value, %d)\n\t\t\t%s = %s\n", core, bits(u), target, conversion(tname, "int64", "i")), nil
+		}
+	case *types.Pointer:
+		elem, err := g.typename(u.Elem())
+		if err != nil {
+			return "", err
+		}
+		conv, err := g.convert("(*"+target+")", u.Elem())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("\t\t\tif %s == nil {\n\t\t\t\t%s = new(%s)\n\t\t\t}\n%s", target, target, elem, conv), nil
+	case *types.Interface:
+		if htmlstring := g.htmlstring(); htmlstring != nil && types.Implements(types.NewPointer(htmlstring), u) {
+			return fmt.Sprintf("\t\t\t%s = %sToHTML(value)\n", target, core), nil
+		}
+	case *types.Slice, *types.Array, *types.Map, *types.Struct:
+		return fmt.Sprintf("\t\t\terr = %sUnfoldJSON(value, %s, %q)\n", core, addr(target), types.TypeString(typ, (*types.Package).Name)), nil
+	}
+	return fmt.Sprintf("\t\t\terr = fmt.Errorf(\"unmanaged type %%s\", %q)\n", types.TypeString(typ, (*types.Package).Name)), nil
+}
+
+// conversion returns the expression converting the variable v of type vtyp to the type tname.
+func conversion(tname string, vtyp string, v string) string {
+	if tname == vtyp {
+		return v
+	}
+	return tname + "(" + v + ")"
+}
+
+// addr returns the address of target, `(*cmp.Image)` gives `cmp.Image`.
+func addr(target string) string {
+	if strings.HasPrefix(target, "(*") {
+		return strings.TrimSuffix(strings.TrimPrefix(target, "(*"), ")")
+	}
+	return "&" + target
+}
+
+// textunmarshaler is the encoding.TextUnmarshaler interface.
+var textunmarshaler = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "UnmarshalText", types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "text", types.NewSlice(types.Typ[types.Byte]))),
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Universe.Lookup("error").Type())), false)),
+}, nil).Complete()
+
+// bits returns the size of the basic numeric type, 0 for int and uint.
+func bits(b *types.Basic) int {
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	case types.Int64, types.Uint64, types.Float64:
+		return 64
+	}
+	return 0
+}
+
+// htmlstring returns the ickcore.HTMLString type, looked up in the packages imported by the generated package.
+func (g *generator) htmlstring() types.Type {
+	seen := make(map[*types.Package]bool)
+	var find func(pkgs []*types.Package) types.Type
+	find = func(pkgs []*types.Package) types.Type {
+		for _, p := range pkgs {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			if p.Path() == ickcorePath {
+				return p.Scope().Lookup("HTMLString").Type()
+			}
+			if t := find(p.Imports()); t != nil {
+				return t
+			}
+		}
+		return nil
+	}
+	return find([]*types.Package{g.pkg})
+}
+
+// pkgref returns the qualifier to reference an identifier of the package path in the generated code, empty for the generated package itself.
+// The package is added to the imports.
+func (g *generator) pkgref(path string, name string) string {
+	if path == g.pkg.Path() {
+		return ""
+	}
+	g.imports[path] = name
+	return name + "."
+}
+
+// typename returns the name of typ in the generated code, adding the required imports.
+// Returns an error if typ can not be referenced from the generated package.
+func (g *generator) typename(typ types.Type) (string, error) {
+	var err error
+	name := types.TypeString(typ, func(p *types.Package) string {
+		return strings.TrimSuffix(g.pkgref(p.Path(), p.Name()), ".")
+	})
+	if named, ok := typ.(*types.Named); ok && !named.Obj().Exported() && named.Obj().Pkg() != nil && named.Obj().Pkg() != g.pkg {
+		err = fmt.Errorf("unexported type %s", name)
+	}
+	return name, err
+}
+
+// source returns the formatted source of the generated file.
+func (g *generator) source() ([]byte, error) {
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "%s\n\npackage %s\n\nimport (\n", HEADER, g.pkg.Name())
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if isstd(paths[i]) != isstd(paths[j]) {
+			return isstd(paths[i])
+		}
+		return paths[i] < paths[j]
+	})
+	for i, p := range paths {
+		// standard packages first
+		if i > 0 && isstd(paths[i-1]) && !isstd(p) {
+			src.WriteString("\n")
+		}
+		if path.Base(p) != g.imports[p] {
+			fmt.Fprintf(&src, "\t%s %s\n", g.imports[p], strconv.Quote(p))
+		} else {
+			fmt.Fprintf(&src, "\t%s\n", strconv.Quote(p))
+		}
+	}
+	src.WriteString(")\n")
+	src.Write(g.buf.Bytes())
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("ickgen: %w", err)
+	}
+	return out, nil
+}
+
+// isstd returns true if the import path is a standard package, without a dot in its first element.
+func isstd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
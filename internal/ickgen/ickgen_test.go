@@ -0,0 +1,73 @@
+package ickgen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate("./testdata/snipgen")
+	require.NoError(t, err)
+	code := string(src)
+
+	assert.Contains(t, code, HEADER)
+	assert.Contains(t, code, "func (*SnipGen) NewComposer() ickcore.Composer {")
+	assert.Contains(t, code, "func (cmp *SnipGen) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {")
+	assert.Contains(t, code, "case \"hidden\":\n\t\t\tcmp.Base.Hidden = ickcore.UnfoldBool(value)")
+	assert.Contains(t, code, "case \"lbl\":\n\t\t\tcmp.Label = value")
+	assert.NotContains(t, code, "cmp.Base.Label")
+	assert.Contains(t, code, "i, err = ickcore.UnfoldUint(value, 8)\n\t\t\tcmp.Level = LEVEL(i)")
+	assert.Contains(t, code, "if cmp.Count == nil {\n\t\t\t\tcmp.Count = new(int)\n\t\t\t}")
+	assert.Contains(t, code, "cmp.Delay, err = time.ParseDuration(value)")
+	assert.Contains(t, code, "cmp.Ref, err = url.Parse(value)")
+	assert.Contains(t, code, "cmp.Content = ickcore.ToHTML(value)")
+	assert.Contains(t, code, "cmp.Header = *ickcore.ToHTML(value)")
+	assert.Contains(t, code, `err = ickcore.UnfoldJSON(value, &cmp.Tags, "[]string")`)
+	assert.Contains(t, code, "err = cmp.When.UnmarshalText([]byte(value))")
+	assert.NotContains(t, code, "Ignored")
+	assert.NotContains(t, code, "internal")
+
+	// the schema doc is generated from the go doc
+	assert.Contains(t, code, "func (*SnipGen) SchemaDoc() (string, map[string]string) {\n\treturn \"SnipGen is a test snippet.\", map[string]string{")
+	assert.Contains(t, code, "\"Hidden\": \"hides the snippet\",")
+	assert.Contains(t, code, "\"Label\":  \"Label is the label\",")
+	assert.NotContains(t, code, "Only the first paragraph")
+	assert.NotContains(t, code, "TODO")
+
+	// the generated code compiles with the package
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "./testdata/snipgen/snipgen.go", nil, 0)
+	require.NoError(t, err)
+	gen, err := parser.ParseFile(fset, FILENAME, src, 0)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("snipgen", fset, []*ast.File{f, gen}, nil)
+	require.NoError(t, err)
+
+	_, err = Generate("./testdata/snipgen", "Unknown")
+	assert.Error(t, err)
+
+	// composers documented by hand are kept
+	src, err = Generate("./testdata/snipgen", "SnipDoc")
+	require.NoError(t, err)
+	assert.NotContains(t, string(src), "SchemaDoc")
+}
+
+// TestGenerateIck checks the generated file of the ick package is up to date.
+func TestGenerateIck(t *testing.T) {
+	dir := filepath.Join("..", "..", "pkg", "ick")
+	src, err := Generate(dir)
+	require.NoError(t, err)
+	current, err := os.ReadFile(filepath.Join(dir, FILENAME))
+	require.NoError(t, err)
+	assert.Equal(t, string(current), string(src), "run go generate ./pkg/ick")
+}
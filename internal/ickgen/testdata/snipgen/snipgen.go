@@ -0,0 +1,55 @@
+package snipgen
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+func init() {
+	ickcore.RegisterComposer("ick-snipgen", &SnipGen{})
+}
+
+type LEVEL uint8
+
+type Base struct {
+	Hidden bool // hides the snippet
+	Label  string
+}
+
+// SnipGen is a [test] snippet.
+//
+// Only the first paragraph is documented.
+//
+// [test]: https://example.com
+type SnipGen struct {
+	ickcore.BareSnippet
+	Base
+
+	// Label is the label
+	// TODO: not documented
+	Label    string `ick:"lbl,required"`
+	Level    LEVEL  `ick:",default=1"`
+	Count    *int
+	Ratio    float32
+	Delay    time.Duration
+	Link     url.URL
+	Ref      *url.URL
+	Content  ickcore.ContentComposer
+	Header   ickcore.HTMLString
+	Tags     []string
+	When     time.Time
+	Ignored  string `ick:"-"`
+	internal string
+}
+
+// SnipDoc is documented by hand
+type SnipDoc struct {
+	ickcore.BareSnippet
+	Label string // the label
+}
+
+func (*SnipDoc) SchemaDoc() (string, map[string]string) {
+	return "documented by hand", nil
+}
@@ -60,6 +60,19 @@ func CookieEnabled() bool {
 	return Win().Get("navigator").GetBool("cookieEnabled")
 }
 
+// WriteClipboardText writes text to the system clipboard.
+// The clipboard is only available in secure contexts, errors are logged out in the console.
+//
+// https://developer.mozilla.org/en-US/docs/Web/API/Clipboard/writeText
+func WriteClipboardText(text string) {
+	clipboard := Win().Get("navigator").Get("clipboard")
+	if !clipboard.IsDefined() {
+		console.Errorf("WriteClipboardText: clipboard not available")
+		return
+	}
+	clipboard.Call("writeText", text)
+}
+
 /******************************************************************************
 * Window
 ******************************************************************************/
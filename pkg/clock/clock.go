@@ -6,6 +6,7 @@ import "time"
 type Clock struct {
 	// The TargetID will be automatically removed after Timeout duration, if not zero.
 	// The timer starts when the delete button is rendered.
+	// If zero and Tic is set, the ticker runs until the clock is stopped.
 	Timeout time.Duration
 
 	// The optional ticker step, 1s by default
@@ -16,32 +17,44 @@ type Clock struct {
 	// TODO: clock - make tic a parameter of Start
 	Tic func(*Clock)
 
-	start  time.Time    // The countdown start time
-	timer  *time.Timer  // overall timer
-	ticker *time.Ticker // internal ticker to handle time left before closing
+	start  time.Time     // The countdown start time
+	timer  *time.Timer   // overall timer
+	ticker *time.Ticker  // internal ticker to handle time left before closing
+	done   chan struct{} // closed by Stop to end the ticker loop
 }
 
 // Start starts the timer and the ticker, according to Timeout and TickerStep properties.
+// If Timeout is zero, there's no timer and the ticker runs until Stop is called.
 func (_clock *Clock) Start(_finished func()) {
-	if _clock.Timeout == 0 {
+	if _clock.Timeout == 0 && _clock.Tic == nil {
 		return
 	}
+	_clock.start = time.Now()
 
 	// Start the overall timer
-	_clock.start = time.Now()
-	_clock.timer = time.AfterFunc(_clock.Timeout, _finished)
+	if _clock.Timeout > 0 {
+		_clock.timer = time.AfterFunc(_clock.Timeout, _finished)
+	}
 
 	// Start the countdown
 	tic := _clock.Tic
-	if tic != nil && _clock.TickerStep <= _clock.Timeout {
+	if tic != nil && (_clock.Timeout == 0 || _clock.TickerStep <= _clock.Timeout) {
 		if _clock.TickerStep == 0 {
 			_clock.TickerStep = 1 * time.Second
 		}
+		ticker := time.NewTicker(_clock.TickerStep)
+		done := make(chan struct{})
+		_clock.ticker = ticker
+		_clock.done = done
 		go func() {
-			_clock.ticker = time.NewTicker(_clock.TickerStep)
 			tic(_clock)
-			for range _clock.ticker.C {
-				tic(_clock)
+			for {
+				select {
+				case <-ticker.C:
+					tic(_clock)
+				case <-done:
+					return
+				}
 			}
 		}()
 	}
@@ -52,6 +65,10 @@ func (_clock *Clock) Stop() {
 	if _clock.ticker != nil {
 		_clock.ticker.Stop()
 	}
+	if _clock.done != nil {
+		close(_clock.done)
+		_clock.done = nil
+	}
 	if _clock.timer != nil {
 		_clock.timer.Stop()
 	}
@@ -29,3 +29,21 @@ func TestClock(t *testing.T) {
 
 	<-wait
 }
+
+func TestClockWithoutTimeout(t *testing.T) {
+
+	tics := make(chan struct{}, 10)
+	c := Clock{
+		TickerStep: 10 * time.Millisecond,
+		Tic: func(cc *Clock) {
+			tics <- struct{}{}
+		},
+	}
+
+	c.Start(nil)
+	for i := 0; i < 3; i++ {
+		<-tics
+	}
+	c.Stop()
+	assert.Equal(t, time.Duration(0), c.TimeLeft())
+}
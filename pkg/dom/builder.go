@@ -0,0 +1,233 @@
+package dom
+
+import (
+	"html"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/icecake-framework/icecake/pkg/js"
+	"github.com/icecake-framework/icecake/pkg/vdom"
+)
+
+// RENDERER selects how InsertSnippetWith builds the DOM of a composer.
+type RENDERER int
+
+const (
+	// RENDERER_HTML renders the composer into an HTML string then lets the browser parse it. This is the default.
+	RENDERER_HTML RENDERER = iota
+
+	// RENDERER_DOM creates the nodes directly with createElement, setAttribute and appendChild while the composer is rendered.
+	// Elements of the TagBuilders are known at construction, so UIComposers are mounted without looking up their id.
+	RENDERER_DOM
+)
+
+const svgNamespace = "http://www.w3.org/2000/svg"
+
+// domBuilder is an ickcore.NodeBuilder creating DOM nodes into a document fragment.
+//
+// Tags of the TagBuilders are created with OpenTag and CloseTag. HTML written by the composers between two tags is
+// parsed with the same leniency as vdom.Parse: elements not closed are closed by the next CloseTag of an enclosing composer.
+// Like with insertAdjacentHTML, script elements created by the builder are inert, they're not run by the browser once inserted.
+type domBuilder struct {
+	fragment js.JSValue
+	stack    []builderNode
+	pending  strings.Builder
+	elements map[ickcore.RMetaProvider]*Element // elements created for the TagBuilders
+}
+
+type builderNode struct {
+	jsv js.JSValue
+	tag string
+	ns  string
+}
+
+// newDomBuilder returns a builder with an empty document fragment.
+func newDomBuilder() *domBuilder {
+	b := new(domBuilder)
+	b.fragment = Doc().Call("createDocumentFragment")
+	b.stack = []builderNode{{jsv: b.fragment}}
+	b.elements = make(map[ickcore.RMetaProvider]*Element)
+	return b
+}
+
+// Write buffers the HTML written by the composers until the next tag or the end of the rendering.
+func (b *domBuilder) Write(p []byte) (int, error) {
+	return b.pending.Write(p)
+}
+
+// OpenTag creates the element of cmp and appends it to the current node.
+func (b *domBuilder) OpenTag(cmp ickcore.Composer, tag ickcore.Tag) error {
+	b.flush()
+	tagname, _ := tag.TagName()
+	e := b.append(tagname)
+	for aname, avalue := range tag.AttributeMap {
+		e.jsv.Call("setAttribute", aname, ickcore.SafeAttributeValue(aname, avalue))
+	}
+	b.elements[cmp] = CastElement(e.jsv)
+	if !tag.IsSelfClosing() {
+		b.stack = append(b.stack, e)
+	}
+	return nil
+}
+
+// CloseTag closes the element of cmp and every element opened after it.
+func (b *domBuilder) CloseTag(cmp ickcore.Composer, tag ickcore.Tag) error {
+	b.flush()
+	if e, found := b.elements[cmp]; found {
+		for i := len(b.stack) - 1; i > 0; i-- {
+			if b.stack[i].jsv.Equal(e.JSValue) {
+				b.stack = b.stack[:i]
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// element returns the element created for the child, or looks it up in the DOM if the child has been rendered as HTML.
+func (b *domBuilder) element(child ickcore.RMetaProvider) (*Element, error) {
+	if e, found := b.elements[child]; found {
+		return e, nil
+	}
+	return childelement(child)
+}
+
+// current returns the node where new nodes are appended
+func (b *domBuilder) current() builderNode {
+	return b.stack[len(b.stack)-1]
+}
+
+// append creates a new element and appends it to the current node.
+// Elements within an svg element are created in the svg namespace.
+func (b *domBuilder) append(tagname string) builderNode {
+	n := builderNode{tag: strings.ToLower(tagname), ns: b.current().ns}
+	if n.tag == "svg" {
+		n.ns = svgNamespace
+	}
+	switch {
+	case n.tag == "script":
+		n.jsv = inertscript(n.ns)
+	case n.ns != "":
+		n.jsv = Doc().Call("createElementNS", n.ns, tagname)
+	default:
+		n.jsv = Doc().Call("createElement", tagname)
+	}
+	b.current().jsv.Call("appendChild", n.jsv)
+	return n
+}
+
+// inertscript returns a new script element in the namespace ns, that the browser won't run once inserted.
+// Scripts parsed with innerHTML are marked as already started, unlike the ones created with createElement.
+func inertscript(ns string) js.JSValue {
+	tmpl := CreateElement("template")
+	if ns == svgNamespace {
+		tmpl.Set("innerHTML", "<svg><script></script></svg>")
+		return tmpl.Get("content").Get("firstChild").Get("firstChild")
+	}
+	tmpl.Set("innerHTML", "<script></script>")
+	return tmpl.Get("content").Get("firstChild")
+}
+
+// appendtext appends a text node with the unescaped text to the current node
+func (b *domBuilder) appendtext(text string) {
+	if text != "" {
+		b.current().jsv.Call("appendChild", Doc().Call("createTextNode", html.UnescapeString(text)))
+	}
+}
+
+// flush creates the nodes of the pending HTML.
+func (b *domBuilder) flush() {
+	if b.pending.Len() == 0 {
+		return
+	}
+	s := b.pending.String()
+	b.pending.Reset()
+
+	i := 0
+	for i < len(s) {
+		lt := strings.IndexByte(s[i:], '<')
+		if lt < 0 {
+			b.appendtext(s[i:])
+			break
+		}
+		b.appendtext(s[i : i+lt])
+		i += lt
+
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			end := strings.Index(s[i+4:], "-->")
+			if end < 0 {
+				end = len(s) - i - 4
+			}
+			b.current().jsv.Call("appendChild", Doc().Call("createComment", s[i+4:i+4+end]))
+			i = min(i+4+end+3, len(s))
+
+		case strings.HasPrefix(s[i:], "</"):
+			gt := strings.IndexByte(s[i:], '>')
+			if gt < 0 {
+				b.appendtext(s[i:])
+				i = len(s)
+				break
+			}
+			tagname := strings.ToLower(strings.TrimSpace(s[i+2 : i+gt]))
+			i += gt + 1
+			for j := len(b.stack) - 1; j > 0; j-- {
+				if b.stack[j].tag == tagname {
+					b.stack = b.stack[:j]
+					break
+				}
+			}
+
+		case i+1 < len(s) && vdom.IsLetter(s[i+1]):
+			vn, end := vdom.ParseTag(s, i)
+			i = end
+			e := b.append(vn.Tag)
+			for _, a := range vn.Attrs {
+				e.jsv.Call("setAttribute", a.Name, html.UnescapeString(a.Value))
+			}
+			if vdom.IsVoidElement(vn.Tag) || strings.HasSuffix(s[:end], "/>") {
+				continue
+			}
+			if vdom.IsRawTextElement(vn.Tag) {
+				endraw := vdom.IndexFold(s[i:], "</"+vn.Tag)
+				if endraw < 0 {
+					endraw = len(s) - i
+				}
+				text := s[i : i+endraw]
+				if vn.Tag == "textarea" || vn.Tag == "title" {
+					text = html.UnescapeString(text)
+				}
+				e.jsv.Set("textContent", text)
+				i += endraw
+				if gt := strings.IndexByte(s[i:], '>'); gt >= 0 {
+					i += gt + 1
+				}
+				continue
+			}
+			b.stack = append(b.stack, e)
+
+		default:
+			b.appendtext("<")
+			i++
+		}
+	}
+}
+
+// insert inserts the fragment built into the DOM relatively to elem.
+func (b *domBuilder) insert(elem *Element, where INSERT_WHERE) {
+	b.flush()
+	switch where {
+	case INSERT_BEFORE_ME:
+		elem.Call("before", b.fragment)
+	case INSERT_FIRST_CHILD:
+		elem.Call("prepend", b.fragment)
+	case INSERT_LAST_CHILD:
+		elem.Call("append", b.fragment)
+	case INSERT_AFTER_ME:
+		elem.Call("after", b.fragment)
+	case INSERT_OUTER:
+		elem.Call("replaceWith", b.fragment)
+	case INSERT_BODY:
+		elem.Call("replaceChildren", b.fragment)
+	}
+}
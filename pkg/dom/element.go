@@ -754,6 +754,14 @@ func (elem *Element) SelectorQueryAll(_selectors string) []*Element {
 // Returns an error if elem in not in the DOM or the _snippet has an Id and it's already in the DOM.
 // Returns an error if WriteSnippet or mounting process fail.
 func (elem *Element) InsertSnippet(where INSERT_WHERE, cmp ickcore.Composer) (errx error) {
+	return elem.InsertSnippetWith(RENDERER_HTML, where, cmp)
+}
+
+// InsertSnippetWith works like InsertSnippet with the selected renderer.
+//
+// With RENDERER_DOM the nodes are created while the composer is rendered, then inserted at once into the DOM.
+// The UIComposers are mounted with the elements created for them, whether they have an id or not.
+func (elem *Element) InsertSnippetWith(renderer RENDERER, where INSERT_WHERE, cmp ickcore.Composer) (errx error) {
 	if !elem.IsDefined() {
 		return console.Errorf("Element:InsertSnippet failed on undefined element")
 	}
@@ -773,12 +781,26 @@ func (elem *Element) InsertSnippet(where INSERT_WHERE, cmp ickcore.Composer) (er
 		return nil
 	}
 
+	if renderer == RENDERER_DOM {
+		if errx = elem.buildSnippet(where, cmp); errx != nil {
+			console.Errorf(errx.Error())
+		}
+		return errx
+	}
+
 	// rendering html of the composers. custodian embeds all direct childrens.
 	rendering := false
 	out := new(bytes.Buffer)
+	ctx := ickcore.NewRenderContext()
 
 	var tag ickcore.Tag
 	tb, isnetb := cmp.(ickcore.TagBuilder)
+	if br, is := cmp.(ickcore.BeforeRenderer); is && isnetb {
+		if errx = br.BeforeRender(); errx != nil {
+			cmp.RMeta().RError = errx
+			return errx
+		}
+	}
 	if isnetb {
 		tag = ickcore.BuildTag(tb)
 		isnetb = !tag.IsEmpty()
@@ -796,14 +818,21 @@ func (elem *Element) InsertSnippet(where INSERT_WHERE, cmp ickcore.Composer) (er
 
 		// Render the html content
 		if cc, iscc := cmp.(ickcore.ContentComposer); iscc {
-			errx = cc.RenderContent(out)
+			cc.RMeta().GenerateVirtualId(nil, cc)
+			errx = cc.RenderContent(ickcore.WithContext(ickcore.Bind(out, cc), ctx))
 			if errx != nil {
 				cmp.RMeta().RError = errx
 			} else {
 				cc.RMeta().IsRender = true
+				if ar, is := cmp.(ickcore.AfterRenderer); is {
+					ar.AfterRender()
+				}
 				newe.Set("innerHTML", out.String())
 				elem.InsertElement(where, newe)
-				_, errx = mountSnippet(cmp, newe)
+				errp := insertportals(ctx)
+				if _, errx = mountSnippet(cmp, newe); errx == nil {
+					errx = errp
+				}
 			}
 
 			// DEBUG: verbose.Debug("InsertSnippet: %+v", cc)
@@ -812,14 +841,18 @@ func (elem *Element) InsertSnippet(where INSERT_WHERE, cmp ickcore.Composer) (er
 		// otherwise insert the rendered snippet html into the dom
 		verbose.Printf(verbose.INFO, "InsertSnippet: inserting content")
 		rendering = true
-		custodian := &ickcore.RMetaData{}
+		custodian := newcustodian(ctx)
 		errx = ickcore.RenderChild(out, custodian, cc)
+		var errp error
 		if errx == nil {
 			elem.InsertRawHTML(where, out.String())
+			errp = insertportals(ctx)
 		}
 
 		// mount every embedded components with an ID
-		_, errx = mountSnippet(custodian, nil)
+		if _, errx = mountSnippet(custodian, nil); errx == nil {
+			errx = errp
+		}
 	}
 
 	// nor a tag builder, nor a simple contentcomposer, nothing to render
@@ -833,6 +866,30 @@ func (elem *Element) InsertSnippet(where INSERT_WHERE, cmp ickcore.Composer) (er
 	return errx
 }
 
+// newcustodian returns the parent of the composers inserted into the DOM, collecting their requirements in ctx.
+// The custodian gets a unique virtual id, so the virtual ids of the inserted composers are unique within the page.
+func newcustodian(ctx *ickcore.RenderContext) *ickcore.RMetaData {
+	custodian := &ickcore.RMetaData{Context: ctx}
+	_, custodian.VirtualId = ickcore.GetUniqueId("ick")
+	return custodian
+}
+
+// buildSnippet renders cmp with a domBuilder, inserts the built nodes into the DOM then mounts cmp and its children.
+func (elem *Element) buildSnippet(where INSERT_WHERE, cmp ickcore.Composer) error {
+	verbose.Debug("InsertSnippet: building DOM of composer %s\n", reflect.TypeOf(cmp).String())
+	b := newDomBuilder()
+	custodian := newcustodian(ickcore.NewRenderContext())
+	if err := ickcore.RenderChild(b, custodian, cmp); err != nil {
+		return err
+	}
+	b.insert(elem, where)
+	errp := insertportals(custodian.Context)
+	if _, err := mountSnippetWith(custodian, nil, b.element); err != nil {
+		return err
+	}
+	return errp
+}
+
 // TODO: dom - handle Element.InsertRawHTML exceptions
 func (elem *Element) InsertRawHTML(where INSERT_WHERE, unsafeHtml string) {
 	if !elem.IsDefined() {
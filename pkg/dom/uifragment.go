@@ -0,0 +1,107 @@
+package dom
+
+import (
+	"fmt"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/icecake-framework/icecake/pkg/js"
+)
+
+// Fragments and portals render their content between two html comments, see ickcore.Markers.
+// The nodes of their content are found in the DOM with these markers.
+// Markers are unique within the page as long as virtual ids are, the composers inserted into the DOM are rendered under a custodian with a unique virtual id.
+
+// nodeFilterShowComment is the NodeFilter.SHOW_COMMENT constant of the TreeWalker API
+const nodeFilterShowComment = 128
+
+// markers are the markers of a fragment or a portal rendered in the DOM
+type markers struct {
+	start string
+	end   string
+}
+
+// markersOf returns the markers of cmp
+func markersOf(cmp ickcore.RMetaProvider) markers {
+	start, end := ickcore.Markers(cmp.RMeta().VirtualId)
+	return markers{start: start, end: end}
+}
+
+// nodes returns the nodes between the first start marker found in the document and its end marker, markers included.
+// Returns nil if the start marker is not found.
+func (m markers) nodes() []js.JSValue {
+	walker := Doc().Call("createTreeWalker", Doc().Get("documentElement"), nodeFilterShowComment)
+	for n := walker.Call("nextNode"); n.Type() == js.TYPE_OBJECT; n = walker.Call("nextNode") {
+		if n.GetString("data") != m.start {
+			continue
+		}
+		nodes := []js.JSValue{n}
+		for next := n.Get("nextSibling"); next.Type() == js.TYPE_OBJECT; next = next.Get("nextSibling") {
+			nodes = append(nodes, next)
+			if next.GetInt("nodeType") == int(NT_COMMENT) && next.GetString("data") == m.end {
+				break
+			}
+		}
+		return nodes
+	}
+	return nil
+}
+
+// remove removes the nodes between the markers and the markers from the DOM.
+func (m markers) remove() {
+	for _, n := range m.nodes() {
+		n.Call("remove")
+	}
+}
+
+// RemoveSnippet unmounts cmp and its children then removes its nodes from the DOM:
+// the element of a UIComposer or the nodes of a Fragment, and the content of the portals embedded in cmp.
+func RemoveSnippet(cmp ickcore.RMetaProvider) {
+	unmountSnippet(cmp)
+	switch c := cmp.(type) {
+	case elementer:
+		c.Element().Remove()
+	case *ickcore.Fragment, *ickcore.Portal:
+		markersOf(c).remove()
+	}
+	removeportals(cmp)
+}
+
+// collectportals returns the markers of the portals embedded in cmp, at any depth.
+func collectportals(cmp ickcore.RMetaProvider) (portals []markers) {
+	for _, child := range cmp.RMeta().Embedded() {
+		if _, is := child.(*ickcore.Portal); is {
+			portals = append(portals, markersOf(child))
+		}
+		portals = append(portals, collectportals(child)...)
+	}
+	return portals
+}
+
+// removeportals removes from the DOM the content of the portals embedded in cmp.
+// Portals render their content outside the element of their parent, so it's not removed with it.
+func removeportals(cmp ickcore.RMetaProvider) {
+	for _, m := range collectportals(cmp) {
+		m.remove()
+	}
+}
+
+// insertportals inserts the content of the portals rendered with ctx at the end of their target element.
+// The content of a portal whose target is not found is not inserted, the other ones are. Returns the first target not found.
+func insertportals(ctx *ickcore.RenderContext) (err error) {
+	for _, p := range ctx.Portals() {
+		var jstarget js.JSValue
+		if p.Target == "" {
+			jstarget = Doc().Get("body")
+		} else {
+			jstarget = Doc().Call("querySelector", p.Target)
+		}
+		if !jstarget.IsDefined() {
+			if err == nil {
+				err = fmt.Errorf("portal target %q not found", p.Target)
+			}
+			continue
+		}
+		CastElement(jstarget).InsertRawHTML(INSERT_LAST_CHILD, p.HTML)
+	}
+	return err
+}
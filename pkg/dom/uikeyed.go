@@ -0,0 +1,131 @@
+package dom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/icecake-framework/icecake/pkg/js"
+)
+
+// The keyed operations below update a keyed ContentStack and apply the change to the DOM without rendering the whole stack again.
+// The stack must be rendered by parent directly within the UI element, and its keyed composers must be TagBuilders rendering a single element.
+// The other items of the stack are left untouched, mounted children keep their listeners.
+// The next RefreshContent diffs its rendering against the DOM.
+
+// InsertChild inserts cmp in the stack at index at, renders it and inserts it into the DOM before the element of the next keyed item in the stack,
+// or at the end of the UI element if there's no next keyed item. Then cmp and its children are mounted.
+func (ui *UI) InsertChild(parent ickcore.RMetaProvider, stack *ickcore.ContentStack, at int, cmp ickcore.ContentComposer) error {
+	if _, is := cmp.(ickcore.TagBuilder); !is || cmp.RMeta().Key == "" {
+		return errors.New("InsertChild: keyed TagBuilder expected")
+	}
+	at, err := stack.Insert(at, cmp)
+	if err != nil {
+		return err
+	}
+	return ui.insertchild(parent, stack, at, cmp)
+}
+
+// RemoveChild removes the composer with the key from the stack, unmounts it and removes its element from the DOM.
+// Returns the removed composer.
+func (ui *UI) RemoveChild(parent ickcore.RMetaProvider, stack *ickcore.ContentStack, key string) (ickcore.ContentComposer, error) {
+	cmp := stack.Remove(key)
+	if cmp == nil {
+		return nil, fmt.Errorf("RemoveChild: %w: %q", ickcore.ErrKeyNotFound, key)
+	}
+	unmountSnippet(cmp)
+	ui.childelement(key).Remove()
+	removeportals(cmp)
+	ui.vtree = nil
+	parent.RMeta().Unembed(cmp)
+	return cmp, nil
+}
+
+// MoveChild moves the composer with the key to index to within the stack, and moves its element within the DOM.
+// The element is moved without rendering, it stays mounted.
+func (ui *UI) MoveChild(stack *ickcore.ContentStack, key string, to int) error {
+	e := ui.childelement(key)
+	if !e.IsDefined() {
+		return fmt.Errorf("MoveChild: element %q not found", key)
+	}
+	to, err := stack.Move(key, to)
+	if err != nil {
+		return err
+	}
+	ui.vtree = nil
+	if next := ui.nextchildelement(stack, to); next.IsDefined() {
+		next.InsertElement(INSERT_BEFORE_ME, e)
+	} else {
+		ui.DOM.InsertElement(INSERT_LAST_CHILD, e)
+	}
+	return nil
+}
+
+// ReplaceChild replaces the composer with the key by cmp in the stack, and replaces its element in the DOM.
+// The replaced composer is unmounted and cmp is mounted. Returns the replaced composer.
+func (ui *UI) ReplaceChild(parent ickcore.RMetaProvider, stack *ickcore.ContentStack, key string, cmp ickcore.ContentComposer) (ickcore.ContentComposer, error) {
+	if _, is := cmp.(ickcore.TagBuilder); !is || cmp.RMeta().Key == "" {
+		return nil, errors.New("ReplaceChild: keyed TagBuilder expected")
+	}
+	old, err := stack.Replace(key, cmp)
+	if err != nil {
+		return nil, err
+	}
+	unmountSnippet(old)
+	parent.RMeta().Unembed(old)
+	ui.childelement(key).Remove()
+	removeportals(old)
+	err = ui.insertchild(parent, stack, stack.IndexOf(cmp.RMeta().Key), cmp)
+	return old, err
+}
+
+// insertchild renders cmp, at index at within the stack, and inserts it into the DOM then mounts it.
+func (ui *UI) insertchild(parent ickcore.RMetaProvider, stack *ickcore.ContentStack, at int, cmp ickcore.ContentComposer) error {
+	ui.vtree = nil
+	out := new(bytes.Buffer)
+	ctx := ickcore.NewRenderContext()
+	if err := ickcore.RenderChild(ickcore.WithContext(ickcore.Bind(out, parent), ctx), parent, cmp); err != nil {
+		return err
+	}
+	if next := ui.nextchildelement(stack, at); next.IsDefined() {
+		next.InsertRawHTML(INSERT_BEFORE_ME, out.String())
+	} else {
+		ui.DOM.InsertRawHTML(INSERT_LAST_CHILD, out.String())
+	}
+	errp := insertportals(ctx)
+	if _, err := mountSnippet(cmp, ui.childelement(cmp.RMeta().Key)); err != nil {
+		return err
+	}
+	return errp
+}
+
+// selectorReplacer escapes a value within a double quoted css selector
+var selectorReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// childelement returns the element of the keyed child, rendered directly within the UI element.
+// Returns an undefined element if it's not found.
+func (ui *UI) childelement(key string) *Element {
+	if !ui.DOM.IsDefined() {
+		return new(Element)
+	}
+	e := ui.DOM.Call("querySelector", `:scope > [`+ickcore.KEY_DATA_ATTRIBUTE+`="`+selectorReplacer.Replace(key)+`"]`)
+	if e.Type() != js.TYPE_OBJECT {
+		return new(Element)
+	}
+	return CastElement(e)
+}
+
+// nextchildelement returns the element of the first keyed item after index at in the stack and found in the DOM.
+// Returns nil if there's none.
+func (ui *UI) nextchildelement(stack *ickcore.ContentStack, at int) (next *Element) {
+	for i := at + 1; i < stack.Len(); i++ {
+		if key := stack.At(i).RMeta().Key; key != "" {
+			if e := ui.childelement(key); e.IsDefined() {
+				return e
+			}
+		}
+	}
+	return nil
+}
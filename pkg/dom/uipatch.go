@@ -0,0 +1,127 @@
+package dom
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/icecake-framework/icecake/pkg/js"
+	"github.com/icecake-framework/icecake/pkg/vdom"
+)
+
+// elementer is implemented by the composers embedding a UI.
+type elementer interface {
+	Element() *Element
+}
+
+// childelement returns the element of a child UIComposer with an id, nil if the child is not a UIComposer or has no id.
+func childelement(child ickcore.RMetaProvider) (*Element, error) {
+	childid := child.RMeta().TagId
+	ui, ok := child.(UIComposer)
+	if childid == "" || !ok {
+		return nil, nil
+	}
+	return TryCastId(ui, childid)
+}
+
+// collectmounted adds cmp and all its embedded children to the mounted map, if they're mounted.
+func collectmounted(cmp ickcore.RMetaProvider, mounted map[ickcore.RMetaProvider]bool) {
+	if cmp.RMeta().IsMounted {
+		mounted[cmp] = true
+	}
+	for _, child := range cmp.RMeta().Embedded() {
+		collectmounted(child, mounted)
+	}
+}
+
+// remountSnippet mounts cmp and its children like mountSnippet, but keeps mounted the ones that were mounted before a refresh
+// and whose element is still in the DOM. Kept composers are removed from the mounted map.
+func remountSnippet(cmp ickcore.RMetaProvider, mounted map[ickcore.RMetaProvider]bool) (err error) {
+	if mounted[cmp] && cmp.RMeta().IsMounted {
+		if e, is := cmp.(elementer); is && e.Element().IsInDOM() {
+			delete(mounted, cmp)
+			for _, child := range cmp.RMeta().Embedded() {
+				if errm := remountSnippet(child, mounted); errm != nil && err == nil {
+					err = errm
+				}
+			}
+			return err
+		}
+	}
+
+	// the element has been replaced or it's a new composer
+	if cmp.RMeta().IsMounted {
+		unmountSnippet(cmp)
+	}
+	delete(mounted, cmp)
+	elem, err := childelement(cmp)
+	if err != nil {
+		return err
+	}
+	_, err = mountSnippet(cmp, elem)
+	return err
+}
+
+// applypatches applies the patches to the children of the UI element.
+// Returns an error as soon as a patch does not match the DOM, the DOM is then partially patched.
+func (ui *UI) applypatches(patches []vdom.Patch) error {
+	for _, p := range patches {
+		node := ui.DOM.JSValue
+		for _, idx := range p.Path {
+			childs, err := node.Check("childNodes")
+			if err != nil || idx >= childs.Length() {
+				return fmt.Errorf("patch path %v not found", p.Path)
+			}
+			node = childs.Index(idx)
+		}
+		if len(p.Path) > 0 {
+			if node.GetInt("nodeType") != domnodetype(p.Type) {
+				return fmt.Errorf("patch path %v: node type %v expected", p.Path, domnodetype(p.Type))
+			}
+			if p.Tag != "" && strings.ToLower(node.GetString("tagName")) != p.Tag {
+				return fmt.Errorf("patch path %v: %q expected", p.Path, p.Tag)
+			}
+		}
+
+		switch p.Op {
+		case vdom.PATCH_SETATTR:
+			node.Call("setAttribute", p.Name, p.Value)
+		case vdom.PATCH_REMOVEATTR:
+			node.Call("removeAttribute", p.Name)
+		case vdom.PATCH_TEXT:
+			node.Set("nodeValue", p.Value)
+		case vdom.PATCH_INSERT:
+			childs := node.Get("childNodes")
+			ref := js.ValueOf(nil)
+			if p.Index < childs.Length() {
+				ref = childs.Index(p.Index)
+			}
+			node.Call("insertBefore", htmlnode(p.HTML), ref)
+		case vdom.PATCH_REMOVE:
+			node.Get("parentNode").Call("removeChild", node)
+		case vdom.PATCH_REPLACE:
+			node.Get("parentNode").Call("replaceChild", htmlnode(p.HTML), node)
+		}
+	}
+	return nil
+}
+
+// domnodetype returns the DOM nodeType corresponding to a vdom node type.
+func domnodetype(t vdom.NODE_TYPE) int {
+	switch t {
+	case vdom.NODE_ELEMENT:
+		return 1
+	case vdom.NODE_TEXT:
+		return 3
+	case vdom.NODE_COMMENT:
+		return 8
+	}
+	return 11 // DOCUMENT_FRAGMENT_NODE
+}
+
+// htmlnode returns the first node created from the html string.
+func htmlnode(html string) js.JSValue {
+	tmpl := CreateElement("template")
+	tmpl.Set("innerHTML", html)
+	return tmpl.Get("content").Get("firstChild")
+}
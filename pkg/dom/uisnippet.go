@@ -1,14 +1,13 @@
 package dom
 
 import (
-	// "bytes"
-
 	"bytes"
 	"reflect"
 
 	"github.com/icecake-framework/icecake/pkg/console"
 	"github.com/icecake-framework/icecake/pkg/ickcore"
 	"github.com/icecake-framework/icecake/pkg/js"
+	"github.com/icecake-framework/icecake/pkg/vdom"
 	"github.com/lolorenzo777/verbose"
 )
 
@@ -23,6 +22,24 @@ type UIComposer interface {
 	RemoveListeners()
 }
 
+// Mounter can be implemented by a composer willing to start things once mounted, like timers.
+// OnMount is called once the composer has been wrapped to its element, its listeners added and its children mounted.
+type Mounter interface {
+	OnMount(*Element)
+}
+
+// Unmounter can be implemented by a composer willing to release resources when it's unmounted.
+// OnUnmount is called before the listeners are removed and before its children are unmounted.
+type Unmounter interface {
+	OnUnmount()
+}
+
+// Updater can be implemented by a composer willing to be notified when its content has been refreshed, see UI.RefreshContent.
+// OnUpdate is called instead of OnUnmount and OnMount, once the new content has been mounted.
+type Updater interface {
+	OnUpdate()
+}
+
 // type Composer interface {
 // 	ickcore.ContentComposer
 // 	UIComposer
@@ -34,6 +51,13 @@ type UIComposer interface {
 // a wrapped dom.Element allowing event listening and other direct DOM interactions.
 type UI struct {
 	DOM Element
+
+	vtree *vdom.Node // tree of the last content rendered by RefreshContent
+}
+
+// Element returns the wrapped element.
+func (ui *UI) Element() *Element {
+	return &ui.DOM
 }
 
 // AddListeners does nothing by default. Can be implemented by the component embedding UISnippet.
@@ -59,21 +83,84 @@ func (ui *UI) RemoveListeners() {
 	ui.DOM.RemoveListeners()
 }
 
-// RefreshContent renders the cmp's content and re-insert it into the DOM.
-// RefreshContent removes and re-add all listeners
+// RefreshContent renders the cmp's content again and applies the changes to the DOM.
+//
+// The new content is diffed against the previous one and only minimal patches are applied to the DOM: attribute changes, text changes,
+// nodes insertion and removal. So focus, caret position, scroll offsets and CSS transitions are preserved where the output didn't change.
+// If the DOM does not match the previous content the whole content is replaced.
+//
+// Mounted children whose element is still in the DOM keep their listeners, the others are unmounted and the new ones are mounted.
+// The content of the embedded portals is replaced.
+// Then cmp.OnUpdate is called if implemented.
 func (ui *UI) RefreshContent(cmp ickcore.ContentComposer) (errx error) {
-	unmountSnippet(cmp)
+	old := ui.vtree
+	if old == nil {
+		old = vdom.Parse(ui.DOM.InnerHTML())
+	}
+
+	// collect the mounted children before the rendering resets them
+	oldchilds := cmp.RMeta().Embedded()
+	mounted := make(map[ickcore.RMetaProvider]bool)
+	for _, child := range oldchilds {
+		collectmounted(child, mounted)
+	}
+
+	oldportals := collectportals(cmp)
+
+	cmp.RMeta().ResetEmbedded()
 	out := new(bytes.Buffer)
-	errx = cmp.RenderContent(out)
-	if errx == nil {
+	ctx := ickcore.NewRenderContext()
+	errx = cmp.RenderContent(ickcore.WithContext(ickcore.Bind(out, cmp), ctx))
+	if errx != nil {
+		// keep the DOM and the previous children unchanged
+		cmp.RMeta().ResetEmbedded()
+		for _, child := range oldchilds {
+			cmp.RMeta().Embed(child)
+		}
+		return errx
+	}
+
+	newtree := vdom.Parse(out.String())
+	if err := ui.applypatches(vdom.Diff(old, newtree)); err != nil {
+		verbose.Debug("RefreshContent: %s, replacing the whole content", err.Error())
 		ui.DOM.InsertRawHTML(INSERT_BODY, out.String())
 	}
-	_, errx = mountSnippet(cmp, &ui.DOM)
+	ui.vtree = newtree
+
+	// portals are rendered again outside the element
+	for _, m := range oldportals {
+		m.remove()
+	}
+	if errp := insertportals(ctx); errp != nil && errx == nil {
+		errx = errp
+	}
+
+	// mount the new children and unmount the ones not rendered anymore
+	for _, child := range cmp.RMeta().Embedded() {
+		if errm := remountSnippet(child, mounted); errm != nil && errx == nil {
+			errx = errm
+		}
+	}
+	for child := range mounted {
+		if child.RMeta().IsMounted {
+			unmountSnippet(child)
+		}
+	}
+
+	if up, is := cmp.(Updater); is {
+		up.OnUpdate()
+	}
 	return errx
 }
 
 // mountSnippet addlisteners to the cmp snippet and looks recursively for every childs with an id and add listeners to each of them.
+// OnMount is called for every mounted composer implementing it.
 func mountSnippet(cmp ickcore.RMetaProvider, elem *Element) (mounted int, err error) {
+	return mountSnippetWith(cmp, elem, childelement)
+}
+
+// mountSnippetWith mounts cmp like mountSnippet, using lookup to get the element of every embedded child.
+func mountSnippetWith(cmp ickcore.RMetaProvider, elem *Element, lookup func(child ickcore.RMetaProvider) (*Element, error)) (mounted int, err error) {
 	cmptype := reflect.TypeOf(cmp).String()
 	if cmp.RMeta().IsMounted {
 		verbose.Printf(verbose.ALERT, "mountSnippet: %s(vid:%q) is already mounted", cmptype, cmp.RMeta().VirtualId)
@@ -101,38 +188,41 @@ func mountSnippet(cmp ickcore.RMetaProvider, elem *Element) (mounted int, err er
 			// DEBUG: verbose.Debug("mountSnippet: %s --> %+v", reflect.TypeOf(emb).String(), emb)
 
 			var e *Element
-			if childid := emb.RMeta().TagId; childid != "" {
-				if child, ok := emb.(UIComposer); ok {
-					e, errm = TryCastId(child, childid)
-					if errm != nil && err == nil {
-						err = errm
-						continue
-					}
+			if e, errm = lookup(emb); errm != nil {
+				if err == nil {
+					err = errm
 				}
+				continue
 			}
 
 			var m int
-			m, errm = mountSnippet(emb, e)
+			m, errm = mountSnippetWith(emb, e, lookup)
 			if errm != nil && err == nil {
 				err = errm
 			}
 			mounted += m
 		}
 	}
+
+	// lifecycle hook
+	if mnt, is := cmp.(Mounter); is && cmp.RMeta().IsMounted {
+		mnt.OnMount(elem)
+	}
 	return mounted, err
 }
 
-// unmountSnippet remove listeners recusrively for every embedded child
+// unmountSnippet remove listeners recusrively for every embedded child.
+// OnUnmount is called for every mounted composer implementing it.
 func unmountSnippet(cmp ickcore.RMetaProvider) {
+	if unmnt, is := cmp.(Unmounter); is && cmp.RMeta().IsMounted {
+		unmnt.OnUnmount()
+	}
 	if ui, is := cmp.(UIComposer); is {
 		ui.RemoveListeners()
 	}
 	if embedded := cmp.RMeta().Embedded(); embedded != nil {
 		for _, sub := range embedded {
-			if child, ok := sub.(UIComposer); ok {
-				child.RemoveListeners()
-				unmountSnippet(child)
-			}
+			unmountSnippet(sub)
 		}
 	}
 	cmp.RMeta().IsMounted = false
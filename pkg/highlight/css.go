@@ -0,0 +1,96 @@
+package highlight
+
+import "strings"
+
+// lexcss tokenizes a CSS style sheet.
+//
+// At the start of every statement the lexer looks ahead: if an opening brace comes before any semicolon or closing brace,
+// the statement is a selector, otherwise it's a declaration. So a list of declarations without any rule is tokenized too.
+func lexcss(t *tokens, src string) {
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case isspace(c) || c == '{' || c == '}' || c == ';':
+			t.add(TOKEN_TEXT, src[i:i+1])
+			i++
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := closing(src, i+2, "*/")
+			t.add(TOKEN_COMMENT, src[i:end])
+			i = end
+
+		case c == '@':
+			end := ident(src, i+1, "-")
+			t.add(TOKEN_KEYWORD, src[i:end])
+			i = lexcssvalue(t, src, end, "{;")
+
+		default:
+			stop := strings.IndexAny(src[i:], "{};")
+			if stop >= 0 && src[i+stop] == '{' {
+				// selector, trailing blanks are text
+				end := i + len(strings.TrimRight(src[i:i+stop], " \t\r\n"))
+				t.add(TOKEN_TAG, src[i:end])
+				i = end
+				break
+			}
+			// declaration
+			end := len(src)
+			if stop >= 0 {
+				end = i + stop
+			}
+			colon := strings.IndexByte(src[i:end], ':')
+			if colon < 0 {
+				i = lexcssvalue(t, src[:end], i, "")
+				break
+			}
+			name := strings.TrimRight(src[i:i+colon], " \t\r\n")
+			t.add(TOKEN_PROPERTY, name)
+			t.add(TOKEN_TEXT, src[i+len(name):i+colon+1])
+			i = lexcssvalue(t, src[:end], i+colon+1, "")
+		}
+	}
+}
+
+// lexcssvalue tokenizes the value of a declaration or the prelude of an at-rule starting at src[at],
+// up to the first character of stops or the end of src. Returns the position of the end.
+func lexcssvalue(t *tokens, src string, at int, stops string) int {
+	i := at
+	for i < len(src) && strings.IndexByte(stops, src[i]) < 0 {
+		c := src[i]
+		switch {
+		case strings.HasPrefix(src[i:], "/*"):
+			end := closing(src, i+2, "*/")
+			t.add(TOKEN_COMMENT, src[i:end])
+			i = end
+		case c == '"' || c == '\'':
+			end := quoted(src, i, c)
+			t.add(TOKEN_STRING, src[i:end])
+			i = end
+		case c == '#' && i+1 < len(src) && (isdigit(src[i+1]) || isletter(src[i+1])):
+			end := ident(src, i+1, "")
+			t.add(TOKEN_NUMBER, src[i:end])
+			i = end
+		case isdigit(c) || (c == '.' && i+1 < len(src) && isdigit(src[i+1])):
+			end := number(src, i)
+			t.add(TOKEN_NUMBER, src[i:end])
+			i = end
+		case c == '!':
+			end := ident(src, i+1, "")
+			t.add(TOKEN_KEYWORD, src[i:end])
+			i = end
+		case isidentstart(src[i:]) || c == '-':
+			end := ident(src, i+1, "-")
+			if end < len(src) && src[end] == '(' {
+				t.add(TOKEN_FUNCTION, src[i:end])
+			} else {
+				t.add(TOKEN_TEXT, src[i:end])
+			}
+			i = end
+		default:
+			end := i + runelen(src[i:])
+			t.add(TOKEN_TEXT, src[i:end])
+			i = end
+		}
+	}
+	return i
+}
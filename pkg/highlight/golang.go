@@ -0,0 +1,67 @@
+package highlight
+
+import "strings"
+
+var gokeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+var gotypes = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true, "error": true,
+	"float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+var goliterals = map[string]bool{
+	"true": true, "false": true, "nil": true, "iota": true,
+}
+
+// lexgo tokenizes Go source code.
+// Identifiers followed by an opening parenthesis are functions, unless they're keywords or predeclared types.
+func lexgo(t *tokens, src string) {
+	for i := 0; i < len(src); {
+		c := src[i]
+		end := i + 1
+		typ := TOKEN_TEXT
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			end = i + len(src[i:])
+			if eol := strings.IndexByte(src[i:], '\n'); eol >= 0 {
+				end = i + eol
+			}
+			typ = TOKEN_COMMENT
+		case strings.HasPrefix(src[i:], "/*"):
+			end = closing(src, i+2, "*/")
+			typ = TOKEN_COMMENT
+		case c == '"' || c == '\'':
+			end = quoted(src, i, c)
+			typ = TOKEN_STRING
+		case c == '`':
+			end = closing(src, i+1, "`")
+			typ = TOKEN_STRING
+		case isdigit(c) || (c == '.' && i+1 < len(src) && isdigit(src[i+1])):
+			end = number(src, i)
+			typ = TOKEN_NUMBER
+		case isidentstart(src[i:]):
+			end = ident(src, i, "")
+			word := src[i:end]
+			switch {
+			case gokeywords[word]:
+				typ = TOKEN_KEYWORD
+			case gotypes[word]:
+				typ = TOKEN_TYPENAME
+			case goliterals[word]:
+				typ = TOKEN_LITERAL
+			case nextnonblank(src, end) == '(':
+				typ = TOKEN_FUNCTION
+			}
+		default:
+			end = i + runelen(src[i:])
+		}
+		t.add(typ, src[i:end])
+		i = end
+	}
+}
@@ -0,0 +1,205 @@
+// Package highlight splits source code into tokens to render it with syntax highlighting.
+//
+// Lexers are provided for Go, HTML, CSS and JSON. They are lenient: they never fail and the concatenation
+// of the texts of the tokens always gives back the source code, whatever its validity.
+package highlight
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TOKEN_TYPE is the syntactic category of a token.
+type TOKEN_TYPE int
+
+const (
+	TOKEN_TEXT      TOKEN_TYPE = iota // anything else, including white spaces, operators and punctuation
+	TOKEN_KEYWORD                     // language keywords, css at-rules and html doctype
+	TOKEN_TYPENAME                    // predeclared types
+	TOKEN_LITERAL                     // predeclared constants like true, false, nil or null, and html entities
+	TOKEN_STRING                      // string and rune literals, html attribute values
+	TOKEN_NUMBER                      // numbers, including css units and hex colors
+	TOKEN_COMMENT                     // comments
+	TOKEN_FUNCTION                    // names of called or declared functions
+	TOKEN_TAG                         // html tags and css selectors
+	TOKEN_ATTRIBUTE                   // html attribute names
+	TOKEN_PROPERTY                    // css properties and json keys
+)
+
+// Class returns the CSS class used to render the token type, "hl-keyword" for TOKEN_KEYWORD.
+// Returns an empty string for TOKEN_TEXT.
+func (typ TOKEN_TYPE) Class() string {
+	switch typ {
+	case TOKEN_KEYWORD:
+		return "hl-keyword"
+	case TOKEN_TYPENAME:
+		return "hl-type"
+	case TOKEN_LITERAL:
+		return "hl-literal"
+	case TOKEN_STRING:
+		return "hl-string"
+	case TOKEN_NUMBER:
+		return "hl-number"
+	case TOKEN_COMMENT:
+		return "hl-comment"
+	case TOKEN_FUNCTION:
+		return "hl-function"
+	case TOKEN_TAG:
+		return "hl-tag"
+	case TOKEN_ATTRIBUTE:
+		return "hl-attribute"
+	case TOKEN_PROPERTY:
+		return "hl-property"
+	}
+	return ""
+}
+
+// Token is a piece of source code with its syntactic category.
+type Token struct {
+	Type TOKEN_TYPE
+	Text string
+}
+
+// Language returns the normalized name of a supported language, or an empty string if lang is not supported.
+// Lookup is case insensitive and common aliases are accepted, like "golang" or "htm".
+func Language(lang string) string {
+	switch strings.ToLower(strings.TrimSpace(lang)) {
+	case "go", "golang":
+		return "go"
+	case "html", "htm", "xhtml", "xml", "svg":
+		return "html"
+	case "css":
+		return "css"
+	case "json":
+		return "json"
+	}
+	return ""
+}
+
+// Tokenize splits src into tokens according to the syntax of lang.
+// Consecutive tokens of the same type are merged.
+// If lang is not supported the whole source is returned within a single TOKEN_TEXT.
+func Tokenize(lang string, src string) []Token {
+	t := new(tokens)
+	switch Language(lang) {
+	case "go":
+		lexgo(t, src)
+	case "html":
+		lexhtml(t, src)
+	case "css":
+		lexcss(t, src)
+	case "json":
+		lexjson(t, src)
+	default:
+		t.add(TOKEN_TEXT, src)
+	}
+	return t.list
+}
+
+/******************************************************************************/
+
+// tokens collects tokens, merging consecutive tokens of the same type.
+type tokens struct {
+	list []Token
+}
+
+func (t *tokens) add(typ TOKEN_TYPE, text string) {
+	if text == "" {
+		return
+	}
+	if n := len(t.list); n > 0 && t.list[n-1].Type == typ {
+		t.list[n-1].Text += text
+		return
+	}
+	t.list = append(t.list, Token{Type: typ, Text: text})
+}
+
+// closing returns the position following the first delim found in src from position at, or len(src) if not found.
+func closing(src string, at int, delim string) int {
+	if end := strings.Index(src[at:], delim); end >= 0 {
+		return at + end + len(delim)
+	}
+	return len(src)
+}
+
+// quoted returns the position following the string literal starting at src[at] with the quote q.
+// Backslash escapes are skipped. An unterminated literal ends at the end of the line.
+func quoted(src string, at int, q byte) int {
+	for i := at + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case q:
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(src)
+}
+
+// number returns the position following the number starting at src[at].
+// Letters are part of the number to include prefixes, exponents, suffixes and css units.
+func number(src string, at int) int {
+	i := at
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case isdigit(c) || isletter(c) || c == '_' || c == '.' || c == '%':
+		case (c == '+' || c == '-') && i > at && strings.IndexByte("eEpP", src[i-1]) >= 0 && !strings.HasPrefix(src[at:], "0x"):
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// ident returns the position following the identifier starting at src[at].
+// extra lists the non alphanumeric characters allowed in the identifier.
+func ident(src string, at int, extra string) int {
+	i := at
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		if !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || (r < utf8.RuneSelf && strings.IndexByte(extra, byte(r)) >= 0)) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// nextnonblank returns the first character following src[at] that is not a space or a tab, 0 if there's none.
+func nextnonblank(src string, at int) byte {
+	for i := at; i < len(src); i++ {
+		if src[i] != ' ' && src[i] != '\t' {
+			return src[i]
+		}
+	}
+	return 0
+}
+
+// isidentstart returns true if the rune at the beginning of s can start an identifier.
+func isidentstart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isdigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isletter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isspace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// runelen returns the size of the rune at the beginning of s, at least 1.
+func runelen(s string) int {
+	_, size := utf8.DecodeRuneInString(s)
+	return max(size, 1)
+}
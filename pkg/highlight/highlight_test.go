@@ -0,0 +1,83 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+
+	const (
+		txt = TOKEN_TEXT
+		kw  = TOKEN_KEYWORD
+		typ = TOKEN_TYPENAME
+		lit = TOKEN_LITERAL
+		str = TOKEN_STRING
+		num = TOKEN_NUMBER
+		com = TOKEN_COMMENT
+		fn  = TOKEN_FUNCTION
+		tag = TOKEN_TAG
+		att = TOKEN_ATTRIBUTE
+		prp = TOKEN_PROPERTY
+	)
+
+	tst := []struct {
+		lang string
+		src  string
+		want []Token
+	}{
+		{"go", `func f(s string) error { return nil } // c`, []Token{
+			{kw, "func"}, {txt, " "}, {fn, "f"}, {txt, "(s "}, {typ, "string"}, {txt, ") "}, {typ, "error"}, {txt, " { "}, {kw, "return"}, {txt, " "}, {lit, "nil"}, {txt, " } "}, {com, "// c"}}},
+		{"golang", "x := `a\nb` + \"c\\\"\" + 'd' + 0x1F + 1.5e-3", []Token{
+			{txt, "x := "}, {str, "`a\nb`"}, {txt, " + "}, {str, `"c\""`}, {txt, " + "}, {str, `'d'`}, {txt, " + "}, {num, "0x1F"}, {txt, " + "}, {num, "1.5e-3"}}},
+		{"go", "/* a\nb */é := \"open\n", []Token{
+			{com, "/* a\nb */"}, {txt, "é := "}, {str, `"open`}, {txt, "\n"}}},
+		{"html", `<!DOCTYPE html><a href="/x" disabled>A &amp; B</a><!-- c --><ick-button title=ok/>`, []Token{
+			{kw, "<!DOCTYPE html>"}, {tag, "<a"}, {txt, " "}, {att, "href"}, {txt, "="}, {str, `"/x"`}, {txt, " "}, {att, "disabled"}, {tag, ">"},
+			{txt, "A "}, {lit, "&amp;"}, {txt, " B"}, {tag, "</a>"}, {com, "<!-- c -->"},
+			{tag, "<ick-button"}, {txt, " "}, {att, "title"}, {txt, "="}, {str, "ok"}, {tag, "/>"}}},
+		{"html", `<style>p{color:red}</style>a < b & c`, []Token{
+			{tag, "<style>p"}, {txt, "{"}, {prp, "color"}, {txt, ":red}"}, {tag, "</style>"}, {txt, "a < b & c"}}},
+		{"css", "@media (max-width: 600px) {\n  a:hover, .c > #d { margin: 0 1.5em !important; color: #fff; background: url('x.png') }\n}", []Token{
+			{kw, "@media"}, {txt, " (max-width: "}, {num, "600px"}, {txt, ") {\n  "}, {tag, "a:hover, .c > #d"}, {txt, " { "},
+			{prp, "margin"}, {txt, ": "}, {num, "0"}, {txt, " "}, {num, "1.5em"}, {txt, " "}, {kw, "!important"}, {txt, "; "},
+			{prp, "color"}, {txt, ": "}, {num, "#fff"}, {txt, "; "},
+			{prp, "background"}, {txt, ": "}, {fn, "url"}, {txt, "("}, {str, "'x.png'"}, {txt, ") }\n}"}}},
+		{"CSS", "color: red /* c */", []Token{
+			{prp, "color"}, {txt, ": red "}, {com, "/* c */"}}},
+		{"json", `{"a": [1, -2.5e3, "s", true, null]}`, []Token{
+			{txt, "{"}, {prp, `"a"`}, {txt, ": ["}, {num, "1"}, {txt, ", "}, {num, "-2.5e3"}, {txt, ", "}, {str, `"s"`}, {txt, ", "}, {lit, "true"}, {txt, ", "}, {lit, "null"}, {txt, "]}"}}},
+		{"python", "def f():", []Token{{txt, "def f():"}}},
+		{"go", "", nil},
+	}
+
+	for i, tc := range tst {
+		assert.Equal(t, tc.want, Tokenize(tc.lang, tc.src), "test %v", i)
+	}
+}
+
+func TestTokenizeLossless(t *testing.T) {
+	srcs := []string{
+		"<a b='c", "<", "</", "<a", "&", "&#x", "@", "{", "a{b:", "\"", "`", "/*", "0x", "-", "é\xff", "<style>a{", "<!--", "<!",
+		`{"a":`, "a: b; c", "x /* y", "#", "!", "a{b{c:d}}",
+	}
+	for _, lang := range []string{"go", "html", "css", "json"} {
+		for _, src := range srcs {
+			var sb strings.Builder
+			for _, tok := range Tokenize(lang, src) {
+				sb.WriteString(tok.Text)
+			}
+			assert.Equal(t, src, sb.String(), "%s %q", lang, src)
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	assert.Equal(t, "go", Language(" GoLang"))
+	assert.Equal(t, "html", Language("svg"))
+	assert.Equal(t, "", Language("python"))
+	assert.Equal(t, "hl-keyword", TOKEN_KEYWORD.Class())
+	assert.Equal(t, "", TOKEN_TEXT.Class())
+}
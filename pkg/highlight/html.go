@@ -0,0 +1,110 @@
+package highlight
+
+import "strings"
+
+// lexhtml tokenizes an HTML document. The content of style elements is tokenized as CSS.
+func lexhtml(t *tokens, src string) {
+	for i := 0; i < len(src); {
+		switch {
+		case strings.HasPrefix(src[i:], "<!--"):
+			end := closing(src, i+4, "-->")
+			t.add(TOKEN_COMMENT, src[i:end])
+			i = end
+
+		case strings.HasPrefix(src[i:], "<!"):
+			end := closing(src, i+2, ">")
+			t.add(TOKEN_KEYWORD, src[i:end])
+			i = end
+
+		case src[i] == '<' && (i+1 < len(src) && isletter(src[i+1]) || strings.HasPrefix(src[i:], "</") && i+2 < len(src) && isletter(src[i+2])):
+			start := i + 1
+			if src[start] == '/' {
+				start++
+			}
+			end := ident(src, start, "-:.")
+			tagname := strings.ToLower(src[start:end])
+			t.add(TOKEN_TAG, src[i:end])
+			closingtag := src[i+1] == '/'
+			var selfclosed bool
+			i, selfclosed = lexattributes(t, src, end)
+			if !closingtag && !selfclosed && (tagname == "style" || tagname == "script") {
+				rawend := len(src)
+				if idx := strings.Index(strings.ToLower(src[i:]), "</"+tagname); idx >= 0 {
+					rawend = i + idx
+				}
+				if tagname == "style" {
+					lexcss(t, src[i:rawend])
+				} else {
+					t.add(TOKEN_TEXT, src[i:rawend])
+				}
+				i = rawend
+			}
+			continue
+
+		case src[i] == '&':
+			end := ident(src[:min(len(src), i+32)], i+1, "#")
+			if end < len(src) && end > i+1 && src[end] == ';' {
+				t.add(TOKEN_LITERAL, src[i:end+1])
+				i = end + 1
+			} else {
+				t.add(TOKEN_TEXT, "&")
+				i++
+			}
+
+		default:
+			end := len(src)
+			if idx := strings.IndexAny(src[i+1:], "<&"); idx >= 0 {
+				end = i + 1 + idx
+			}
+			t.add(TOKEN_TEXT, src[i:end])
+			i = end
+		}
+	}
+}
+
+// lexattributes tokenizes the attributes of the tag starting at src[at], up to the end of the tag included.
+// Returns the position following the tag and whether the tag is selfclosed.
+func lexattributes(t *tokens, src string, at int) (int, bool) {
+	value := false // the next word is an attribute value
+	i := at
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '>':
+			t.add(TOKEN_TAG, ">")
+			return i + 1, false
+		case strings.HasPrefix(src[i:], "/>"):
+			t.add(TOKEN_TAG, "/>")
+			return i + 2, true
+		case isspace(c):
+			end := i + 1
+			for end < len(src) && isspace(src[end]) {
+				end++
+			}
+			t.add(TOKEN_TEXT, src[i:end])
+			i = end
+		case c == '=':
+			t.add(TOKEN_TEXT, "=")
+			value = true
+			i++
+		case c == '"' || c == '\'':
+			end := closing(src, i+1, string(c))
+			t.add(TOKEN_STRING, src[i:end])
+			value = false
+			i = end
+		default:
+			end := i + 1
+			for end < len(src) && !isspace(src[end]) && strings.IndexByte("=>\"'", src[end]) < 0 && !strings.HasPrefix(src[end:], "/>") {
+				end++
+			}
+			if value {
+				t.add(TOKEN_STRING, src[i:end])
+			} else {
+				t.add(TOKEN_ATTRIBUTE, src[i:end])
+			}
+			value = false
+			i = end
+		}
+	}
+	return i, false
+}
@@ -0,0 +1,35 @@
+package highlight
+
+// lexjson tokenizes a JSON document. Strings followed by a colon are object keys.
+func lexjson(t *tokens, src string) {
+	for i := 0; i < len(src); {
+		c := src[i]
+		end := i + 1
+		typ := TOKEN_TEXT
+		switch {
+		case c == '"':
+			end = quoted(src, i, c)
+			typ = TOKEN_STRING
+			j := end
+			for j < len(src) && isspace(src[j]) {
+				j++
+			}
+			if j < len(src) && src[j] == ':' {
+				typ = TOKEN_PROPERTY
+			}
+		case isdigit(c) || (c == '-' && i+1 < len(src) && isdigit(src[i+1])):
+			end = number(src, i+1)
+			typ = TOKEN_NUMBER
+		case isletter(c):
+			end = ident(src, i, "")
+			switch src[i:end] {
+			case "true", "false", "null":
+				typ = TOKEN_LITERAL
+			}
+		default:
+			end = i + runelen(src[i:])
+		}
+		t.add(typ, src[i:end])
+		i = end
+	}
+}
@@ -0,0 +1,350 @@
+// Package i18n provides message catalogs to render the same website in several languages.
+//
+// A catalog holds the messages of a language, loaded from JSON or TOML files, one file per language named after the language: en.json, fr.toml, pt-BR.json.
+// Nested objects or tables give dotted keys, and an object with plural categories as keys is a message with plural forms:
+//
+//	# fr.toml
+//	hello = "Bonjour {name}"
+//
+//	[nav]
+//	home = "Accueil"
+//
+//	[cart.items]
+//	one = "{count} article"
+//	other = "{count} articles"
+//
+// Messages are resolved by language with a fallback: the exact language "fr-CA", then its base language "fr", then the fallback language of the bundle.
+// Parameters are substituted to their {name} placeholders. The "count" parameter selects the plural form according to the plural rules of the language.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// COUNT_PARAM is the name of the parameter selecting the plural form of a message.
+const COUNT_PARAM string = "count"
+
+// DefaultBundle is the bundle used by the package level functions, with english as fallback language.
+var DefaultBundle = NewBundle("en")
+
+// Params are the parameters substituted to the placeholders of a message, by name.
+type Params map[string]any
+
+// Message is a translated message with its plural forms.
+// A message without plural has only the Other form.
+type Message struct {
+	Zero  string
+	One   string
+	Two   string
+	Few   string
+	Many  string
+	Other string
+}
+
+// Form returns the text of the plural form p, or the Other form if the message has no such form.
+func (msg Message) Form(p PLURAL) string {
+	var s string
+	switch p {
+	case PLURAL_ZERO:
+		s = msg.Zero
+	case PLURAL_ONE:
+		s = msg.One
+	case PLURAL_TWO:
+		s = msg.Two
+	case PLURAL_FEW:
+		s = msg.Few
+	case PLURAL_MANY:
+		s = msg.Many
+	}
+	if s == "" {
+		s = msg.Other
+	}
+	return s
+}
+
+// Bundle is a set of catalogs, one per language.
+// Bundle is safe for concurrent use.
+type Bundle struct {
+	mu       sync.RWMutex
+	fallback string
+	catalogs map[string]map[string]Message // messages by key by language
+}
+
+// NewBundle returns an empty bundle. fallback is the language of the messages used when a message is missing in the requested language.
+func NewBundle(fallback string) *Bundle {
+	b := new(Bundle)
+	b.fallback = NormalizeLang(fallback)
+	b.catalogs = make(map[string]map[string]Message)
+	return b
+}
+
+// Fallback returns the fallback language of the bundle.
+func (b *Bundle) Fallback() string {
+	return b.fallback
+}
+
+// Langs returns the sorted list of the languages having a catalog.
+func (b *Bundle) Langs() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	langs := make([]string, 0, len(b.catalogs))
+	for lang := range b.catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Add adds or replaces the message key in the catalog of lang.
+func (b *Bundle) Add(lang string, key string, msg Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lang = NormalizeLang(lang)
+	if b.catalogs[lang] == nil {
+		b.catalogs[lang] = make(map[string]Message)
+	}
+	b.catalogs[lang][key] = msg
+}
+
+// Load loads the messages of a catalog file content into the catalog of lang.
+// format is either "json" or "toml". Messages already in the catalog are replaced.
+func (b *Bundle) Load(lang string, format string, data []byte) error {
+	var root map[string]any
+	var err error
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "json":
+		err = json.Unmarshal(data, &root)
+	case "toml":
+		root, err = parsetoml(string(data))
+	default:
+		return fmt.Errorf("i18n: unsupported catalog format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("i18n: %s catalog: %w", lang, err)
+	}
+
+	msgs := make(map[string]Message)
+	if err = flatten(msgs, "", root); err != nil {
+		return fmt.Errorf("i18n: %s catalog: %w", lang, err)
+	}
+	for key, msg := range msgs {
+		b.Add(lang, key, msg)
+	}
+	return nil
+}
+
+// LoadFile loads a catalog file. The language is the name of the file without its extension, the format is given by the extension.
+func (b *Bundle) LoadFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	ext := filepath.Ext(filename)
+	return b.Load(strings.TrimSuffix(filepath.Base(filename), ext), ext, data)
+}
+
+// LoadFS loads all the .json and .toml catalog files found in the directory dir of fsys, like an embedded file system.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	for _, e := range entries {
+		ext := path.Ext(e.Name())
+		if e.IsDir() || (ext != ".json" && ext != ".toml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("i18n: %w", err)
+		}
+		if err = b.Load(strings.TrimSuffix(e.Name(), ext), ext, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the message key in the catalog of lang, following the fallback chain of languages.
+// Returns false if the message is not found.
+func (b *Bundle) Lookup(lang string, key string) (Message, bool) {
+	msg, found, _ := b.lookup(lang, key)
+	return msg, found
+}
+
+// lookup is Lookup returning the language of the catalog where the message has been found.
+func (b *Bundle) lookup(lang string, key string) (Message, bool, string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range b.chain(lang) {
+		if msg, found := b.catalogs[l][key]; found {
+			return msg, true, l
+		}
+	}
+	return Message{}, false, ""
+}
+
+// Has returns true if the message key is found for lang, following the fallback chain of languages.
+func (b *Bundle) Has(lang string, key string) bool {
+	_, found := b.Lookup(lang, key)
+	return found
+}
+
+// Translate returns the message key in lang with its parameters substituted.
+// If params has a count, the plural form is selected according to the plural rules of the language of the message found.
+// Returns the key itself if the message is not found.
+func (b *Bundle) Translate(lang string, key string, params Params) string {
+	msg, found, msglang := b.lookup(lang, key)
+	if !found {
+		return key
+	}
+	form := PLURAL_OTHER
+	if count, has := params[COUNT_PARAM]; has {
+		if n, isint := toint(count); isint {
+			form = Plural(msglang, n)
+		}
+	}
+	return Format(msg.Form(form), params)
+}
+
+// chain returns the languages to lookup for lang, without duplicates.
+func (b *Bundle) chain(lang string) []string {
+	chain := make([]string, 0, 4)
+	for _, l := range []string{NormalizeLang(lang), baselang(lang), b.fallback, baselang(b.fallback)} {
+		dup := l == ""
+		for _, c := range chain {
+			dup = dup || c == l
+		}
+		if !dup {
+			chain = append(chain, l)
+		}
+	}
+	return chain
+}
+
+// T returns the message key in lang from the DefaultBundle, see Bundle.Translate.
+func T(lang string, key string, params Params) string {
+	return DefaultBundle.Translate(lang, key, params)
+}
+
+// Format substitutes the params to their {name} placeholders in text. Placeholders without parameter are left as is.
+func Format(text string, params Params) string {
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	var sb strings.Builder
+	for {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(text[open:], '}')
+		if end < 0 {
+			break
+		}
+		name := text[open+1 : open+end]
+		value, found := params[name]
+		sb.WriteString(text[:open])
+		if found {
+			sb.WriteString(fmt.Sprint(value))
+		} else {
+			sb.WriteString(text[open : open+end+1])
+		}
+		text = text[open+end+1:]
+	}
+	sb.WriteString(text)
+	return sb.String()
+}
+
+// NormalizeLang returns the lowercase language tag with dashes, "pt_BR" gives "pt-br".
+func NormalizeLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// baselang returns the base language of lang, "pt" for "pt-BR".
+func baselang(lang string) string {
+	base, _, _ := strings.Cut(NormalizeLang(lang), "-")
+	return base
+}
+
+// flatten adds the messages of the tree node to msgs, prefixing their keys with prefix.
+func flatten(msgs map[string]Message, prefix string, node map[string]any) error {
+	for k, v := range node {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch value := v.(type) {
+		case string:
+			msgs[key] = Message{Other: value}
+		case map[string]any:
+			if msg, isplural := pluralmessage(value); isplural {
+				msgs[key] = msg
+			} else if err := flatten(msgs, key, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%q: string value expected", key)
+		}
+	}
+	return nil
+}
+
+// pluralmessage returns the message made of the plural forms of node,
+// if all its keys are plural categories with string values including other.
+func pluralmessage(node map[string]any) (msg Message, isplural bool) {
+	if _, hasother := node[string(PLURAL_OTHER)]; !hasother {
+		return msg, false
+	}
+	for k, v := range node {
+		s, isstring := v.(string)
+		if !isstring {
+			return msg, false
+		}
+		switch PLURAL(k) {
+		case PLURAL_ZERO:
+			msg.Zero = s
+		case PLURAL_ONE:
+			msg.One = s
+		case PLURAL_TWO:
+			msg.Two = s
+		case PLURAL_FEW:
+			msg.Few = s
+		case PLURAL_MANY:
+			msg.Many = s
+		case PLURAL_OTHER:
+			msg.Other = s
+		default:
+			return msg, false
+		}
+	}
+	return msg, true
+}
+
+// toint converts any integer, or float without fractional part, to an int.
+func toint(v any) (int, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return int(f), f == float64(int(f))
+	case reflect.String:
+		var n int
+		_, err := fmt.Sscan(rv.String(), &n)
+		return n, err == nil && fmt.Sprint(n) == strings.TrimSpace(rv.String())
+	}
+	return 0, false
+}
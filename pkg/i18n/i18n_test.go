@@ -0,0 +1,123 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle(t *testing.T) {
+	b := NewBundle("en")
+
+	err := b.Load("en", "json", []byte(`{
+		"hello": "Hello {name}",
+		"nav": {"home": "Home", "docs": "Docs"},
+		"items": {"one": "{count} item", "other": "{count} items"}
+	}`))
+	require.NoError(t, err)
+
+	err = b.Load("fr", "toml", []byte(`
+# comment
+hello = "Bonjour {name}" # trailing comment
+nav.home = 'Accueil'
+
+[items]
+one = "{count} article"
+other = """
+{count} articles"""
+
+[ru]
+"quoted key" = "д"
+`))
+	require.NoError(t, err)
+	b.Add("ru", "items", Message{One: "{count} штука", Few: "{count} штуки", Many: "{count} штук", Other: "{count} штуки"})
+
+	assert.Equal(t, []string{"en", "fr", "ru"}, b.Langs())
+
+	tst := []struct {
+		lang   string
+		key    string
+		params Params
+		want   string
+	}{
+		{"en", "hello", Params{"name": "Bob"}, "Hello Bob"},
+		{"fr", "hello", Params{"name": "Bob"}, "Bonjour Bob"},
+		{"fr-CA", "hello", nil, "Bonjour {name}"},
+		{"FR_ca", "nav.home", nil, "Accueil"},
+		{"fr", "nav.docs", nil, "Docs"}, // fallback
+		{"de", "nav.home", nil, "Home"},
+		{"en", "missing", nil, "missing"},
+		{"en", "items", Params{"count": 1}, "1 item"},
+		{"en", "items", Params{"count": 0}, "0 items"},
+		{"fr", "items", Params{"count": 0}, "0 article"},
+		{"fr", "items", Params{"count": "2"}, "2 articles"},
+		{"fr", "items", Params{"count": 1.5}, "1.5 articles"},
+		{"ru", "items", Params{"count": 21}, "21 штука"},
+		{"ru", "items", Params{"count": 3}, "3 штуки"},
+		{"ru", "items", Params{"count": 11}, "11 штук"},
+		{"fr", "ru.quoted key", nil, "д"},
+		{"de", "items", Params{"count": 0}, "0 items"}, // plural rules of the fallback language
+	}
+	for i, tc := range tst {
+		assert.Equal(t, tc.want, b.Translate(tc.lang, tc.key, tc.params), "test %v", i)
+	}
+
+	assert.True(t, b.Has("fr", "nav.docs"))
+	assert.False(t, b.Has("fr", "nav"))
+}
+
+func TestLoadErrors(t *testing.T) {
+	b := NewBundle("en")
+	assert.Error(t, b.Load("en", "yaml", []byte(``)))
+	assert.Error(t, b.Load("en", "json", []byte(`{"a": 1}`)))
+	assert.Error(t, b.Load("en", "toml", []byte(`a = 1`)))
+	assert.Error(t, b.Load("en", "toml", []byte(`a = "x`)))
+	assert.Error(t, b.Load("en", "toml", []byte("a = \"x\"\na.b = \"y\"")))
+	assert.Error(t, b.Load("en", "toml", []byte(`[[a]]`)))
+	assert.Error(t, b.Load("en", "toml", []byte(`a = "\q"`)))
+}
+
+func TestLoadFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"hi": "Hi"}`)},
+		"locales/pt-BR.toml": {Data: []byte(`hi = "Oi"`)},
+		"locales/readme.md":  {Data: []byte(`ignored`)},
+	}
+	b := NewBundle("en")
+	require.NoError(t, b.LoadFS(fsys, "locales"))
+	assert.Equal(t, []string{"en", "pt-br"}, b.Langs())
+	assert.Equal(t, "Oi", b.Translate("pt-BR", "hi", nil))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.toml"), []byte(`hi = "Hallo"`), 0644))
+	require.NoError(t, b.LoadFile(filepath.Join(dir, "de.toml")))
+	assert.Equal(t, "Hallo", b.Translate("de", "hi", nil))
+}
+
+func TestPlural(t *testing.T) {
+	tst := []struct {
+		lang string
+		n    int
+		want PLURAL
+	}{
+		{"en", 1, PLURAL_ONE}, {"en", 0, PLURAL_OTHER}, {"en-US", 2, PLURAL_OTHER},
+		{"fr", 0, PLURAL_ONE}, {"fr", 2, PLURAL_OTHER},
+		{"ja", 1, PLURAL_OTHER},
+		{"ru", 1, PLURAL_ONE}, {"ru", 11, PLURAL_MANY}, {"ru", 22, PLURAL_FEW}, {"ru", 5, PLURAL_MANY},
+		{"pl", 1, PLURAL_ONE}, {"pl", 21, PLURAL_MANY}, {"pl", 24, PLURAL_FEW},
+		{"cs", 3, PLURAL_FEW}, {"cs", 5, PLURAL_OTHER},
+		{"ar", 0, PLURAL_ZERO}, {"ar", 2, PLURAL_TWO}, {"ar", 105, PLURAL_FEW}, {"ar", 111, PLURAL_MANY}, {"ar", 100, PLURAL_OTHER},
+	}
+	for _, tc := range tst {
+		assert.Equal(t, tc.want, Plural(tc.lang, tc.n), "%s %v", tc.lang, tc.n)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "a 1 {b} {", Format("a {a} {b} {", Params{"a": 1}))
+	assert.Equal(t, "{a}", Format("{a}", nil))
+}
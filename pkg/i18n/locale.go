@@ -0,0 +1,365 @@
+package i18n
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DATE_STYLE is the length of a formatted date.
+type DATE_STYLE string
+
+const (
+	DATE_NONE   DATE_STYLE = "none"   // no date
+	DATE_SHORT  DATE_STYLE = "short"  // 1/2/2006
+	DATE_MEDIUM DATE_STYLE = "medium" // Jan 2, 2006
+	DATE_LONG   DATE_STYLE = "long"   // January 2, 2006
+)
+
+// Locale holds the formatting conventions of a language.
+//
+// Date and time patterns are made of the following fields, other letters must be quoted with single quotes:
+//
+//	d, dd        day of the month: 2, 02
+//	M, MM        month: 1, 01
+//	MMM, MMMM    month name: ShortMonths, Months
+//	yyyy         year
+//	H, HH        hour in 24 hours format: 3, 03
+//	h            hour in 12 hours format
+//	mm           minutes
+//	a            AM or PM
+type Locale struct {
+	Decimal string // decimal separator
+	Group   string // digits group separator
+
+	CurrencyBefore bool   // the currency symbol is written before the amount
+	CurrencySpace  string // the separator between the currency symbol and the amount
+
+	Months      [12]string
+	ShortMonths [12]string
+
+	DateShort  string // pattern of the DATE_SHORT style
+	DateMedium string // pattern of the DATE_MEDIUM style
+	DateLong   string // pattern of the DATE_LONG style
+	Time       string // pattern of the time
+	DateTime   string // how to join a date and a time, {date} and {time} are the placeholders
+
+	Now    string // relative time of less than 10 seconds
+	Past   string // relative time in the past, {time} is the placeholder for the amount of time
+	Future string // relative time in the future, {time} is the placeholder for the amount of time
+
+	// plural forms of the amounts of time, {count} is the placeholder of the number of units
+	Seconds, Minutes, Hours, Days, Weeks, MonthsCount, Years Message
+}
+
+var (
+	_localesmu sync.RWMutex
+	_locales   = map[string]*Locale{
+		"en": &localeEN, "fr": &localeFR, "de": &localeDE, "es": &localeES, "it": &localeIT, "pt": &localePT, "nl": &localeNL,
+	}
+)
+
+// RegisterLocale registers or replaces the formatting conventions of the language lang.
+func RegisterLocale(lang string, l Locale) {
+	_localesmu.Lock()
+	defer _localesmu.Unlock()
+	_locales[NormalizeLang(lang)] = &l
+}
+
+// LocaleOf returns the formatting conventions of lang, or the ones of its base language.
+// English, German, Spanish, French, Italian, Dutch and Portuguese are provided, English is returned for any other language.
+func LocaleOf(lang string) *Locale {
+	_localesmu.RLock()
+	defer _localesmu.RUnlock()
+	if l, found := _locales[NormalizeLang(lang)]; found {
+		return l
+	}
+	if l, found := _locales[baselang(lang)]; found {
+		return l
+	}
+	return _locales["en"]
+}
+
+// FormatNumber formats v with the separators of lang. decimals is the number of digits after the decimal separator,
+// if negative the smallest number of digits necessary to represent v is used.
+func FormatNumber(lang string, v float64, decimals int) string {
+	return LocaleOf(lang).FormatNumber(v, decimals)
+}
+
+// FormatCurrency formats the amount v with two decimals and the currency symbol, like "$1,234.50" or "1 234,50 €".
+func FormatCurrency(lang string, v float64, symbol string) string {
+	return LocaleOf(lang).FormatCurrency(v, symbol)
+}
+
+// FormatDateTime formats t according to the conventions of lang. The time is omitted if withtime is false.
+func FormatDateTime(lang string, t time.Time, style DATE_STYLE, withtime bool) string {
+	return LocaleOf(lang).FormatDateTime(t, style, withtime)
+}
+
+// FormatRelativeTime formats the time elapsed between now and t, like "3 minutes ago" or "in 2 days".
+func FormatRelativeTime(lang string, t time.Time, now time.Time) string {
+	return LocaleOf(lang).FormatRelativeTime(lang, t, now)
+}
+
+// FormatNumber formats v with the separators of the locale, see the package level FormatNumber.
+func (l *Locale) FormatNumber(v float64, decimals int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	intpart, fracpart, hasfrac := strings.Cut(s, ".")
+
+	var sb strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		sb.WriteString("-")
+	}
+	for i, c := range intpart {
+		if i > 0 && (len(intpart)-i)%3 == 0 {
+			sb.WriteString(l.Group)
+		}
+		sb.WriteRune(c)
+	}
+	if hasfrac {
+		sb.WriteString(l.Decimal + fracpart)
+	}
+	return sb.String()
+}
+
+// FormatCurrency formats the amount v with the currency symbol, see the package level FormatCurrency.
+func (l *Locale) FormatCurrency(v float64, symbol string) string {
+	amount := l.FormatNumber(v, 2)
+	if l.CurrencyBefore {
+		return symbol + l.CurrencySpace + amount
+	}
+	return amount + l.CurrencySpace + symbol
+}
+
+// FormatDateTime formats t according to the locale, see the package level FormatDateTime.
+func (l *Locale) FormatDateTime(t time.Time, style DATE_STYLE, withtime bool) string {
+	var date string
+	switch style {
+	case DATE_SHORT:
+		date = l.format(t, l.DateShort)
+	case DATE_MEDIUM:
+		date = l.format(t, l.DateMedium)
+	case DATE_LONG:
+		date = l.format(t, l.DateLong)
+	}
+	switch {
+	case !withtime:
+		return date
+	case date == "":
+		return l.format(t, l.Time)
+	}
+	return Format(l.DateTime, Params{"date": date, "time": l.format(t, l.Time)})
+}
+
+// FormatRelativeTime formats the time elapsed between now and t, see the package level FormatRelativeTime.
+// lang selects the plural rules.
+func (l *Locale) FormatRelativeTime(lang string, t time.Time, now time.Time) string {
+	d := t.Sub(now)
+	pattern := l.Future
+	if d < 0 {
+		d = -d
+		pattern = l.Past
+	}
+
+	var n int
+	var unit Message
+	switch {
+	case d < 10*time.Second:
+		return l.Now
+	case d < time.Minute:
+		n, unit = int(d/time.Second), l.Seconds
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), l.Minutes
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), l.Hours
+	case d < 7*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), l.Days
+	case d < 30*24*time.Hour:
+		n, unit = int(d/(7*24*time.Hour)), l.Weeks
+	case d < 365*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), l.MonthsCount
+	default:
+		n, unit = int(d/(365*24*time.Hour)), l.Years
+	}
+	amount := Format(unit.Form(Plural(lang, n)), Params{COUNT_PARAM: n})
+	return Format(pattern, Params{"time": amount})
+}
+
+// format formats t with the pattern
+func (l *Locale) format(t time.Time, pattern string) string {
+	var sb strings.Builder
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		if c == '\'' {
+			end := strings.IndexByte(pattern[i+1:], '\'')
+			if end < 0 {
+				end = len(pattern) - i - 1
+			}
+			sb.WriteString(pattern[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		n := 1
+		for i+n < len(pattern) && pattern[i+n] == c {
+			n++
+		}
+		field := pattern[i : i+n]
+		i += n
+		switch field {
+		case "d":
+			sb.WriteString(strconv.Itoa(t.Day()))
+		case "dd":
+			sb.WriteString(twodigits(t.Day()))
+		case "M":
+			sb.WriteString(strconv.Itoa(int(t.Month())))
+		case "MM":
+			sb.WriteString(twodigits(int(t.Month())))
+		case "MMM":
+			sb.WriteString(l.ShortMonths[t.Month()-1])
+		case "MMMM":
+			sb.WriteString(l.Months[t.Month()-1])
+		case "yyyy":
+			sb.WriteString(strconv.Itoa(t.Year()))
+		case "H":
+			sb.WriteString(strconv.Itoa(t.Hour()))
+		case "HH":
+			sb.WriteString(twodigits(t.Hour()))
+		case "h":
+			h := t.Hour() % 12
+			if h == 0 {
+				h = 12
+			}
+			sb.WriteString(strconv.Itoa(h))
+		case "mm":
+			sb.WriteString(twodigits(t.Minute()))
+		case "a":
+			if t.Hour() < 12 {
+				sb.WriteString("AM")
+			} else {
+				sb.WriteString("PM")
+			}
+		default:
+			sb.WriteString(field)
+		}
+	}
+	return sb.String()
+}
+
+func twodigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+/******************************************************************************/
+
+var localeEN = Locale{
+	Decimal: ".", Group: ",", CurrencyBefore: true,
+	Months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	ShortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	DateShort:   "M/d/yyyy", DateMedium: "MMM d, yyyy", DateLong: "MMMM d, yyyy", Time: "h:mm a", DateTime: "{date}, {time}",
+	Now: "now", Past: "{time} ago", Future: "in {time}",
+	Seconds:     Message{One: "{count} second", Other: "{count} seconds"},
+	Minutes:     Message{One: "{count} minute", Other: "{count} minutes"},
+	Hours:       Message{One: "{count} hour", Other: "{count} hours"},
+	Days:        Message{One: "{count} day", Other: "{count} days"},
+	Weeks:       Message{One: "{count} week", Other: "{count} weeks"},
+	MonthsCount: Message{One: "{count} month", Other: "{count} months"},
+	Years:       Message{One: "{count} year", Other: "{count} years"},
+}
+
+var localeFR = Locale{
+	Decimal: ",", Group: "\u202f", CurrencySpace: "\u00a0",
+	Months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	ShortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+	DateShort:   "dd/MM/yyyy", DateMedium: "d MMM yyyy", DateLong: "d MMMM yyyy", Time: "HH:mm", DateTime: "{date} {time}",
+	Now: "maintenant", Past: "il y a {time}", Future: "dans {time}",
+	Seconds:     Message{One: "{count} seconde", Other: "{count} secondes"},
+	Minutes:     Message{One: "{count} minute", Other: "{count} minutes"},
+	Hours:       Message{One: "{count} heure", Other: "{count} heures"},
+	Days:        Message{One: "{count} jour", Other: "{count} jours"},
+	Weeks:       Message{One: "{count} semaine", Other: "{count} semaines"},
+	MonthsCount: Message{Other: "{count} mois"},
+	Years:       Message{One: "{count} an", Other: "{count} ans"},
+}
+
+var localeDE = Locale{
+	Decimal: ",", Group: ".", CurrencySpace: "\u00a0",
+	Months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	ShortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+	DateShort:   "dd.MM.yyyy", DateMedium: "dd.MM.yyyy", DateLong: "d. MMMM yyyy", Time: "HH:mm", DateTime: "{date}, {time}",
+	Now: "jetzt", Past: "vor {time}", Future: "in {time}",
+	Seconds:     Message{One: "{count} Sekunde", Other: "{count} Sekunden"},
+	Minutes:     Message{One: "{count} Minute", Other: "{count} Minuten"},
+	Hours:       Message{One: "{count} Stunde", Other: "{count} Stunden"},
+	Days:        Message{One: "{count} Tag", Other: "{count} Tagen"},
+	Weeks:       Message{One: "{count} Woche", Other: "{count} Wochen"},
+	MonthsCount: Message{One: "{count} Monat", Other: "{count} Monaten"},
+	Years:       Message{One: "{count} Jahr", Other: "{count} Jahren"},
+}
+
+var localeES = Locale{
+	Decimal: ",", Group: ".", CurrencySpace: "\u00a0",
+	Months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	ShortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+	DateShort:   "d/M/yyyy", DateMedium: "d MMM yyyy", DateLong: "d 'de' MMMM 'de' yyyy", Time: "H:mm", DateTime: "{date}, {time}",
+	Now: "ahora", Past: "hace {time}", Future: "dentro de {time}",
+	Seconds:     Message{One: "{count} segundo", Other: "{count} segundos"},
+	Minutes:     Message{One: "{count} minuto", Other: "{count} minutos"},
+	Hours:       Message{One: "{count} hora", Other: "{count} horas"},
+	Days:        Message{One: "{count} día", Other: "{count} días"},
+	Weeks:       Message{One: "{count} semana", Other: "{count} semanas"},
+	MonthsCount: Message{One: "{count} mes", Other: "{count} meses"},
+	Years:       Message{One: "{count} año", Other: "{count} años"},
+}
+
+var localeIT = Locale{
+	Decimal: ",", Group: ".", CurrencySpace: "\u00a0",
+	Months:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	ShortMonths: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+	DateShort:   "dd/MM/yyyy", DateMedium: "d MMM yyyy", DateLong: "d MMMM yyyy", Time: "HH:mm", DateTime: "{date}, {time}",
+	Now: "ora", Past: "{time} fa", Future: "tra {time}",
+	Seconds:     Message{One: "{count} secondo", Other: "{count} secondi"},
+	Minutes:     Message{One: "{count} minuto", Other: "{count} minuti"},
+	Hours:       Message{One: "{count} ora", Other: "{count} ore"},
+	Days:        Message{One: "{count} giorno", Other: "{count} giorni"},
+	Weeks:       Message{One: "{count} settimana", Other: "{count} settimane"},
+	MonthsCount: Message{One: "{count} mese", Other: "{count} mesi"},
+	Years:       Message{One: "{count} anno", Other: "{count} anni"},
+}
+
+var localePT = Locale{
+	Decimal: ",", Group: ".", CurrencyBefore: true, CurrencySpace: "\u00a0",
+	Months:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	ShortMonths: [12]string{"jan.", "fev.", "mar.", "abr.", "mai.", "jun.", "jul.", "ago.", "set.", "out.", "nov.", "dez."},
+	DateShort:   "dd/MM/yyyy", DateMedium: "d 'de' MMM 'de' yyyy", DateLong: "d 'de' MMMM 'de' yyyy", Time: "HH:mm", DateTime: "{date} {time}",
+	Now: "agora", Past: "há {time}", Future: "em {time}",
+	Seconds:     Message{One: "{count} segundo", Other: "{count} segundos"},
+	Minutes:     Message{One: "{count} minuto", Other: "{count} minutos"},
+	Hours:       Message{One: "{count} hora", Other: "{count} horas"},
+	Days:        Message{One: "{count} dia", Other: "{count} dias"},
+	Weeks:       Message{One: "{count} semana", Other: "{count} semanas"},
+	MonthsCount: Message{One: "{count} mês", Other: "{count} meses"},
+	Years:       Message{One: "{count} ano", Other: "{count} anos"},
+}
+
+var localeNL = Locale{
+	Decimal: ",", Group: ".", CurrencyBefore: true, CurrencySpace: "\u00a0",
+	Months:      [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+	ShortMonths: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+	DateShort:   "dd-MM-yyyy", DateMedium: "d MMM yyyy", DateLong: "d MMMM yyyy", Time: "HH:mm", DateTime: "{date} {time}",
+	Now: "nu", Past: "{time} geleden", Future: "over {time}",
+	Seconds:     Message{One: "{count} seconde", Other: "{count} seconden"},
+	Minutes:     Message{One: "{count} minuut", Other: "{count} minuten"},
+	Hours:       Message{Other: "{count} uur"},
+	Days:        Message{One: "{count} dag", Other: "{count} dagen"},
+	Weeks:       Message{One: "{count} week", Other: "{count} weken"},
+	MonthsCount: Message{One: "{count} maand", Other: "{count} maanden"},
+	Years:       Message{Other: "{count} jaar"},
+}
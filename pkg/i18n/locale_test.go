@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tst := []struct {
+		lang     string
+		v        float64
+		decimals int
+		want     string
+	}{
+		{"en", 1234567.891, 2, "1,234,567.89"},
+		{"en-US", 1234.5, -1, "1,234.5"},
+		{"en", 123, 0, "123"},
+		{"en", -1234, 0, "-1,234"},
+		{"en", -0.001, 2, "0.00"},
+		{"fr", 1234567.5, 1, "1\u202f234\u202f567,5"},
+		{"de", 1234.5, 2, "1.234,50"},
+		{"ja", 1234.5, 2, "1,234.50"},
+		{"en", math.NaN(), 2, "NaN"},
+	}
+	for i, tc := range tst {
+		assert.Equal(t, tc.want, FormatNumber(tc.lang, tc.v, tc.decimals), "test %v", i)
+	}
+
+	assert.Equal(t, "$1,234.50", FormatCurrency("en", 1234.5, "$"))
+	assert.Equal(t, "1\u202f234,50\u00a0€", FormatCurrency("fr", 1234.5, "€"))
+	assert.Equal(t, "R$\u00a010,00", FormatCurrency("pt-BR", 10, "R$"))
+}
+
+func TestFormatDateTime(t *testing.T) {
+	d := time.Date(2023, time.March, 5, 14, 7, 0, 0, time.UTC)
+	tst := []struct {
+		lang     string
+		style    DATE_STYLE
+		withtime bool
+		want     string
+	}{
+		{"en", DATE_SHORT, false, "3/5/2023"},
+		{"en", DATE_MEDIUM, false, "Mar 5, 2023"},
+		{"en", DATE_LONG, true, "March 5, 2023, 2:07 PM"},
+		{"en", DATE_NONE, true, "2:07 PM"},
+		{"fr", DATE_SHORT, true, "05/03/2023 14:07"},
+		{"fr", DATE_LONG, false, "5 mars 2023"},
+		{"de", DATE_LONG, false, "5. März 2023"},
+		{"es", DATE_LONG, false, "5 de marzo de 2023"},
+		{"xx", DATE_NONE, false, ""},
+	}
+	for i, tc := range tst {
+		assert.Equal(t, tc.want, FormatDateTime(tc.lang, d, tc.style, tc.withtime), "test %v", i)
+	}
+
+	midnight := time.Date(2023, time.March, 5, 0, 30, 0, 0, time.UTC)
+	assert.Equal(t, "12:30 AM", FormatDateTime("en", midnight, DATE_NONE, true))
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2023, time.March, 5, 14, 0, 0, 0, time.UTC)
+	tst := []struct {
+		lang string
+		d    time.Duration
+		want string
+	}{
+		{"en", -5 * time.Second, "now"},
+		{"en", -30 * time.Second, "30 seconds ago"},
+		{"en", -time.Minute, "1 minute ago"},
+		{"en", 3 * time.Hour, "in 3 hours"},
+		{"en", -2 * 24 * time.Hour, "2 days ago"},
+		{"en", 14 * 24 * time.Hour, "in 2 weeks"},
+		{"en", -90 * 24 * time.Hour, "3 months ago"},
+		{"en", -800 * 24 * time.Hour, "2 years ago"},
+		{"fr", -3 * time.Minute, "il y a 3 minutes"},
+		{"fr", -90 * 24 * time.Hour, "il y a 3 mois"},
+		{"de", -3 * time.Minute, "vor 3 Minuten"},
+		{"it", 24 * time.Hour, "tra 1 giorno"},
+		{"nl", -2 * time.Hour, "2 uur geleden"},
+	}
+	for i, tc := range tst {
+		assert.Equal(t, tc.want, FormatRelativeTime(tc.lang, now.Add(tc.d), now), "test %v", i)
+	}
+}
+
+func TestRegisterLocale(t *testing.T) {
+	l := *LocaleOf("en")
+	l.Group = "'"
+	RegisterLocale("en-CH", l)
+	assert.Equal(t, "1'234", FormatNumber("en_CH", 1234, 0))
+	assert.Equal(t, "1,234", FormatNumber("en", 1234, 0))
+}
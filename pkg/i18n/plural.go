@@ -0,0 +1,81 @@
+package i18n
+
+// PLURAL is a plural category, as defined by the Unicode CLDR.
+type PLURAL string
+
+const (
+	PLURAL_ZERO  PLURAL = "zero"
+	PLURAL_ONE   PLURAL = "one"
+	PLURAL_TWO   PLURAL = "two"
+	PLURAL_FEW   PLURAL = "few"
+	PLURAL_MANY  PLURAL = "many"
+	PLURAL_OTHER PLURAL = "other"
+)
+
+// Plural returns the plural category of the count n in the language lang.
+//
+// Rules of the Unicode CLDR are implemented for integer counts for the most common languages:
+// Arabic, Czech, French, Hebrew, Polish, Portuguese, Russian and Ukrainian, and languages without plural like Chinese, Japanese or Korean.
+// Other languages follow the English rule: one for 1, other for anything else.
+func Plural(lang string, n int) PLURAL {
+	if n < 0 {
+		n = -n
+	}
+	mod10, mod100 := n%10, n%100
+	switch baselang(lang) {
+	case "ja", "zh", "ko", "th", "vi", "id", "ms", "lo", "my":
+		return PLURAL_OTHER
+	case "fr", "pt", "hi", "bn", "fa":
+		if n == 0 || n == 1 {
+			return PLURAL_ONE
+		}
+	case "ru", "uk", "be":
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return PLURAL_ONE
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return PLURAL_FEW
+		}
+		return PLURAL_MANY
+	case "pl":
+		switch {
+		case n == 1:
+			return PLURAL_ONE
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return PLURAL_FEW
+		}
+		return PLURAL_MANY
+	case "cs", "sk":
+		switch {
+		case n == 1:
+			return PLURAL_ONE
+		case n >= 2 && n <= 4:
+			return PLURAL_FEW
+		}
+	case "ar":
+		switch {
+		case n == 0:
+			return PLURAL_ZERO
+		case n == 1:
+			return PLURAL_ONE
+		case n == 2:
+			return PLURAL_TWO
+		case mod100 >= 3 && mod100 <= 10:
+			return PLURAL_FEW
+		case mod100 >= 11:
+			return PLURAL_MANY
+		}
+	case "he":
+		switch n {
+		case 1:
+			return PLURAL_ONE
+		case 2:
+			return PLURAL_TWO
+		}
+	default:
+		if n == 1 {
+			return PLURAL_ONE
+		}
+	}
+	return PLURAL_OTHER
+}
@@ -0,0 +1,208 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parsetoml parses the subset of TOML used by message catalogs into nested maps:
+// comments, tables and dotted keys, bare and quoted keys, basic, literal and multi-line strings.
+// Other types of values are not allowed.
+func parsetoml(data string) (map[string]any, error) {
+	root := make(map[string]any)
+	table := root
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		lineno := i + 1
+
+		// table header
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %v: invalid table header", lineno)
+			}
+			keys, rest, err := tomlkeys(line[1:end])
+			if err != nil || strings.TrimSpace(rest) != "" || !iscomment(line[end+1:]) {
+				return nil, fmt.Errorf("line %v: invalid table header", lineno)
+			}
+			if table, err = subtable(root, keys); err != nil {
+				return nil, fmt.Errorf("line %v: %w", lineno, err)
+			}
+			continue
+		}
+
+		// key = value
+		keys, rest, err := tomlkeys(line)
+		if err != nil || !strings.HasPrefix(rest, "=") {
+			return nil, fmt.Errorf("line %v: key = value expected", lineno)
+		}
+		rest = strings.TrimSpace(rest[1:])
+		var value string
+		if strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, `'''`) {
+			// multi-line string, a newline immediately following the opening delimiter is trimmed
+			delim := rest[:3]
+			text := rest[3:]
+			for !strings.Contains(text, delim) && i+1 < len(lines) {
+				i++
+				text += "\n" + lines[i]
+			}
+			end := strings.Index(text, delim)
+			if end < 0 || !iscomment(text[end+3:]) {
+				return nil, fmt.Errorf("line %v: unterminated string", lineno)
+			}
+			value = strings.TrimPrefix(text[:end], "\n")
+			if delim == `"""` {
+				if value, err = unescapetoml(value); err != nil {
+					return nil, fmt.Errorf("line %v: %w", lineno, err)
+				}
+			}
+		} else if value, rest, err = tomlstring(rest); err != nil || !iscomment(rest) {
+			return nil, fmt.Errorf("line %v: string value expected", lineno)
+		}
+		parent, err := subtable(table, keys[:len(keys)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", lineno, err)
+		}
+		last := keys[len(keys)-1]
+		if _, found := parent[last]; found {
+			return nil, fmt.Errorf("line %v: duplicate key %q", lineno, last)
+		}
+		parent[last] = value
+	}
+	return root, nil
+}
+
+// subtable returns the table at the path keys from table, creating missing tables.
+func subtable(table map[string]any, keys []string) (map[string]any, error) {
+	for _, k := range keys {
+		switch sub := table[k].(type) {
+		case nil:
+			t := make(map[string]any)
+			table[k] = t
+			table = t
+		case map[string]any:
+			table = sub
+		default:
+			return nil, fmt.Errorf("key %q is not a table", k)
+		}
+	}
+	return table, nil
+}
+
+// tomlkeys parses a dotted list of bare or quoted keys at the beginning of s.
+// Returns the keys and what follows them, without leading blanks.
+func tomlkeys(s string) (keys []string, rest string, err error) {
+	rest = strings.TrimSpace(s)
+	for {
+		var key string
+		if strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, `'`) {
+			if key, rest, err = tomlstring(rest); err != nil {
+				return nil, "", err
+			}
+		} else {
+			end := 0
+			for end < len(rest) && (isalnum(rest[end]) || rest[end] == '-' || rest[end] == '_') {
+				end++
+			}
+			if end == 0 {
+				return nil, "", fmt.Errorf("key expected")
+			}
+			key, rest = rest[:end], rest[end:]
+		}
+		keys = append(keys, key)
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(rest, ".") {
+			return keys, rest, nil
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+}
+
+// tomlstring parses the basic or literal string at the beginning of s.
+// Returns the string and what follows it, without leading blanks.
+func tomlstring(s string) (value string, rest string, err error) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", "", fmt.Errorf("string expected")
+	}
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && q == '"':
+			i++
+		case s[i] == q:
+			value = s[1:i]
+			if q == '"' {
+				if value, err = unescapetoml(value); err != nil {
+					return "", "", err
+				}
+			}
+			return value, strings.TrimSpace(s[i+1:]), nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+// unescapetoml processes the escape sequences of a basic string.
+func unescapetoml(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", fmt.Errorf("invalid escape sequence")
+		}
+		switch s[i] {
+		case 'b':
+			sb.WriteByte('\b')
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'r':
+			sb.WriteByte('\r')
+		case '"', '\\':
+			sb.WriteByte(s[i])
+		case 'u', 'U':
+			size := 4
+			if s[i] == 'U' {
+				size = 8
+			}
+			if i+size >= len(s)+0 && i+size > len(s)-1+1 {
+				return "", fmt.Errorf("invalid unicode escape sequence")
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return "", fmt.Errorf("invalid unicode escape sequence")
+			}
+			sb.WriteRune(rune(r))
+			i += size
+		default:
+			return "", fmt.Errorf("invalid escape sequence \\%c", s[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// iscomment returns true if s is blank or a comment.
+func iscomment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s[0] == '#'
+}
+
+func isalnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
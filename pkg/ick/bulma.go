@@ -1,6 +1,20 @@
 package ick
 
-import "strings"
+// ick_gen.go provides the reflection-free factories and ApplyAttributes of the registered composers.
+//go:generate go run github.com/icecake-framework/icecake/cmd/icecake gen
+
+import (
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+func init() {
+	ickcore.RegisterEnum(COLOR_NONE, COLOR_OPTIONS)
+	ickcore.RegisterEnum(TXTCOLOR_WHITE, TXTCOLOR_OPTIONS)
+	ickcore.RegisterEnum(BKGCOLOR_WHITE, BKGCOLOR_OPTIONS)
+	ickcore.RegisterEnum(SIZE_STD, SIZE_OPTIONS)
+}
 
 type COLOR string
 
@@ -14,18 +14,18 @@ func init() {
 // ICKButton is an UISnippet registered with the ick-tag `ick-button`.
 //
 // According to the ButtonType property, ICKButton can be used either as a standard <button> element but also as an anchor link or a submit or reset form input.
-// The core text is handle with the Title html property, allowing simple text or complex rendering.
+// The core text is handle with the Title text property.
 //
 // The IsDisabled property is directly handled by the embedded UISnippet.
 type ICKButton struct {
 	ickcore.BareSnippet
 
 	OpeningIcon ICKIcon // optional opening icon
-	Title       string  // html string
+	Title       string  // text, escaped when rendered in safe mode
 	ClosingIcon ICKIcon // optional closing icon
 
 	// HRef defines the associated url link. HRef can be nil. If HRef is defined then the rendered element is a <a> tag, otherwise it's a <button> tag.
-	HRef *url.URL
+	HRef *url.URL `ick:"href"`
 
 	IsOutlined bool // Outlined button style
 	IsRounded  bool // Rounded button style
@@ -42,6 +42,23 @@ type ICKButton struct {
 // Ensuring ICKButton implements the right interface
 var _ ickcore.ContentComposer = (*ICKButton)(nil)
 var _ ickcore.TagBuilder = (*ICKButton)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKButton)(nil)
+
+// SchemaDoc documents the ick-tag of ICKButton, see ickcore.SchemaDocumenter.
+func (*ICKButton) SchemaDoc() (string, map[string]string) {
+	return "ICKButton renders a button, or an anchor link if HRef is set.", map[string]string{
+		"OpeningIcon": "optional opening icon",
+		"Title":       "text, escaped when rendered in safe mode",
+		"ClosingIcon": "optional closing icon",
+		"HRef":        "the associated url link, renders an <a> element instead of a <button>",
+		"IsOutlined":  "outlined button style",
+		"IsRounded":   "rounded button style",
+		"COLOR":       "rendering color",
+		"SIZE":        "button size",
+		"IsDisabled":  "disabled state",
+		"IsLoading":   "loading button state",
+	}
+}
 
 func Button(htmltitle string, attrs ...string) *ICKButton {
 	btn := new(ICKButton)
@@ -158,7 +175,7 @@ func (btn *ICKButton) RenderContent(out io.Writer) error {
 		ickcore.RenderChild(out, btn, &btn.OpeningIcon)
 	}
 	ickcore.RenderStringIf(has && btn.Title != "", out, "<span>")
-	ickcore.RenderStringIf(btn.Title != "", out, btn.Title)
+	ickcore.RenderTextIf(btn.Title != "", out, btn.Title)
 	ickcore.RenderStringIf(has && btn.Title != "", out, "</span>")
 	if btn.ClosingIcon.NeedRendering() {
 		ickcore.RenderChild(out, btn, &btn.ClosingIcon)
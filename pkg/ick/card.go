@@ -1,6 +1,7 @@
 package ick
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/icecake-framework/icecake/pkg/ickcore"
@@ -11,6 +12,10 @@ func init() {
 }
 
 // The card is an HTMLSnippet. Use AddContent to setup the content of the card
+//
+// ICKCard can be unfolded from a paired ick-tag. The default content goes into the Body, the title and the footer items are named slots:
+//
+//	<ick-card><ick-slot name="title">Hello</ick-slot><p>body</p><ick-slot name="footer">Footer</ick-slot></ick-card>
 type ICKCard struct {
 	ickcore.BareSnippet
 
@@ -30,6 +35,17 @@ type ICKCard struct {
 // Ensuring ICKCard implements the right interface
 var _ ickcore.ContentComposer = (*ICKCard)(nil)
 var _ ickcore.TagBuilder = (*ICKCard)(nil)
+var _ ickcore.SlotReceiver = (*ICKCard)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKCard)(nil)
+
+// SchemaDoc documents the ick-tag of ICKCard, see ickcore.SchemaDocumenter.
+func (*ICKCard) SchemaDoc() (string, map[string]string) {
+	return "ICKCard renders a bulma card with an optional title, image and footer items. The content of a paired ick-tag goes into the body, the title and the footer items are named slots.", map[string]string{
+		"Title": "optional title to display in the head of the card",
+		"Body":  "the body of the card",
+		"Image": "optional image to display on top of the card",
+	}
+}
 
 // Card main factory
 func Card(content ickcore.ContentComposer, attrs ...string) *ICKCard {
@@ -41,7 +57,7 @@ func Card(content ickcore.ContentComposer, attrs ...string) *ICKCard {
 }
 
 func (card *ICKCard) SetTitle(title string) *ICKCard {
-	card.Title = *ickcore.ToHTML(title)
+	card.Title = *ickcore.ToText(title)
 	return card
 }
 func (card *ICKCard) SetImage(image ICKImage) *ICKCard {
@@ -53,6 +69,22 @@ func (card *ICKCard) AddFooterItem(item ickcore.HTMLString) *ICKCard {
 	return card
 }
 
+// SetSlot receives the inner content of a paired ick-card tag.
+// The default content is appended to the Body, the "title" slot sets the Title and each "footer" slot adds a footer item.
+func (card *ICKCard) SetSlot(name string, content *ickcore.HTMLString) error {
+	switch name {
+	case "":
+		card.Body.Append(content)
+	case "title":
+		card.Title = *content
+	case "footer":
+		card.AddFooterItem(*content)
+	default:
+		return fmt.Errorf("unknown slot")
+	}
+	return nil
+}
+
 /******************************************************************************/
 
 // BuildTag builds the tag used to render the html element.
@@ -0,0 +1,34 @@
+package ick
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardSlots(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	html := ickcore.ToHTML(`<ick-card><ick-slot name="title">Hello</ick-slot><p>body</p><ick-slot name="footer">F</ick-slot></ick-card>`)
+	err := ickcore.RenderChild(out, nil, html)
+	require.NoError(t, err)
+	assert.Equal(t, `<div name="ickcard" class="card"><header class="card-header"><p class="card-header-title">Hello</p></header><div class="card-content"><p>body</p></div><div class="card-footer"><span class="card-footer-item">F</span></div></div>`, out.String())
+
+	out.Reset()
+	html = ickcore.ToHTML(`<ick-hero><ick-slot name="title">Title</ick-slot>body</ick-hero>`)
+	err = ickcore.RenderChild(out, nil, html)
+	require.NoError(t, err)
+	assert.Equal(t, `<section name="ickhero" class="hero"><div class="hero-body"><div class="container"><p name="icktitle" class="title is-1">Title</p><p name="icktitle" class="title is-1"></p>body</div></div></section>`, out.String())
+}
+
+func TestCardData(t *testing.T) {
+	out := new(bytes.Buffer)
+	data := struct{ Name string }{Name: `<script>alert(1)</script>`}
+	err := ickcore.RenderData(out, nil, data, ickcore.ToHTML(`<ick-card Title="{{ .Name }}"/>`))
+	require.NoError(t, err)
+	assert.NotContains(t, out.String(), `<script>`)
+	assert.Contains(t, out.String(), `<p class="card-header-title">&lt;script&gt;alert(1)&lt;&#47;script&gt;</p>`)
+}
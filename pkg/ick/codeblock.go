@@ -0,0 +1,259 @@
+package ick
+
+import (
+	"errors"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/highlight"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+func init() {
+	ickcore.RegisterComposer("ick-codeblock", &ICKCodeBlock{})
+	ickcore.RequireComposerCSSStyle("ick-codeblock", codeblockStyle)
+}
+
+// ICKCodeBlock is an icecake snippet rendering source code with syntax highlighting.
+// Highlighting is made at render time by the highlight package, no javascript is required.
+// Go, HTML, CSS and JSON are highlighted, other languages are rendered as plain text.
+//
+// The code can be the inner content of a paired ick-tag, its common indentation is removed and HTML entities are unescaped:
+//
+//	<ick-codeblock language="go" line-numbers highlight="2,4-5">
+//		func main() {
+//			fmt.Println("hello")
+//		}
+//	</ick-codeblock>
+//
+// The style of the tokens is required with RequireComposerCSSStyle, so it's added to the pages rendering a code block.
+type ICKCodeBlock struct {
+	ickcore.BareSnippet
+
+	// Code is the source code to render
+	Code string
+
+	// Language of the source code: go, html, css or json
+	Language string
+
+	// LineNumbers renders the number of every line in a gutter
+	LineNumbers bool
+
+	// Highlight lists the ranges of lines to highlight, line numbers starting at 1.
+	// The ick-tag attribute is a comma separated list of numbers or ranges like "2,4-5".
+	Highlight []LineRange
+
+	// CanCopy renders a button to copy the code to the clipboard. The button is handled by the ickui.ICKCodeBlock.
+	CanCopy bool
+}
+
+// Ensuring ICKCodeBlock implements the right interface
+var _ ickcore.ContentComposer = (*ICKCodeBlock)(nil)
+var _ ickcore.TagBuilder = (*ICKCodeBlock)(nil)
+var _ ickcore.SlotReceiver = (*ICKCodeBlock)(nil)
+var _ ickcore.AttributeUnfolder = (*ICKCodeBlock)(nil)
+var _ ickcore.AttributeDescriber = (*ICKCodeBlock)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKCodeBlock)(nil)
+
+// SchemaDoc documents the ick-tag of ICKCodeBlock, see ickcore.SchemaDocumenter.
+func (*ICKCodeBlock) SchemaDoc() (string, map[string]string) {
+	return "ICKCodeBlock renders source code with syntax highlighting, made at render time without javascript. Go, HTML, CSS and JSON are highlighted, other languages are rendered as plain text. The code is the content of a paired ick-tag.", map[string]string{
+		"Code":        "the source code to render",
+		"Language":    "language of the source code: go, html, css or json",
+		"LineNumbers": "renders the number of every line in a gutter",
+		"CanCopy":     "renders a button to copy the code to the clipboard, handled by the ickui.ICKCodeBlock",
+	}
+}
+
+// LineRange is a range of lines, from First to Last included.
+type LineRange struct {
+	First int
+	Last  int
+}
+
+// Contains returns true if the line number n is within the range.
+func (r LineRange) Contains(n int) bool {
+	return n >= r.First && n <= r.Last
+}
+
+// CodeBlock returns a new ICKCodeBlock rendering the code written in language.
+func CodeBlock(language string, code string, attrs ...string) *ICKCodeBlock {
+	cb := new(ICKCodeBlock)
+	cb.Language = language
+	cb.Code = code
+	cb.Tag().ParseAttributes(attrs...)
+	return cb
+}
+
+// SetLineNumbers renders or hides the line numbers
+func (cb *ICKCodeBlock) SetLineNumbers(f bool) *ICKCodeBlock {
+	cb.LineNumbers = f
+	return cb
+}
+
+// SetHighlight sets the numbers of the lines to highlight, starting at 1.
+func (cb *ICKCodeBlock) SetHighlight(lines ...int) *ICKCodeBlock {
+	cb.Highlight = make([]LineRange, len(lines))
+	for i, n := range lines {
+		cb.Highlight[i] = LineRange{First: n, Last: n}
+	}
+	return cb
+}
+
+// SetCanCopy renders or hides the copy button
+func (cb *ICKCodeBlock) SetCanCopy(f bool) *ICKCodeBlock {
+	cb.CanCopy = f
+	return cb
+}
+
+// UnfoldAttribute parses the highlight attribute of the ick-tag. Other attributes get the default mapping.
+func (cb *ICKCodeBlock) UnfoldAttribute(name string, value string) (err error) {
+	if !strings.EqualFold(name, "highlight") {
+		return ickcore.ErrAttributeNotUnfolded
+	}
+	cb.Highlight, err = ParseLineRanges(value)
+	return err
+}
+
+// DescribeAttributes describes the highlight attribute unfolded by UnfoldAttribute.
+func (cb *ICKCodeBlock) DescribeAttributes() []ickcore.PropertySchema {
+	return []ickcore.PropertySchema{
+		{Name: "highlight", Kind: "string", Doc: `comma separated list of the lines to highlight, numbers or ranges like "2,4-5"`},
+	}
+}
+
+// SetSlot handles the inner content of an ick-codeblock paired tag, the source code.
+func (cb *ICKCodeBlock) SetSlot(name string, content *ickcore.HTMLString) error {
+	if name != "" {
+		return errors.New("unknown slot")
+	}
+	cb.Code = strings.TrimRight(html.UnescapeString(dedent(string(content.Bytes()))), " \t\r\n")
+	return nil
+}
+
+// NeedRendering returns true if there's some code.
+func (cb *ICKCodeBlock) NeedRendering() bool {
+	return cb.Code != ""
+}
+
+/******************************************************************************/
+
+// BuildTag returns tag <div class="ick-codeblock" {attributes}>
+func (cb *ICKCodeBlock) BuildTag() ickcore.Tag {
+	cb.Tag().
+		SetTagName("div").
+		AddClass("ick-codeblock").
+		SetClassIf(cb.LineNumbers, "has-line-numbers")
+	return *cb.Tag()
+}
+
+// RenderContent writes the copy button, if any, and the code within a <pre><code> element.
+// Every line is rendered within a <span class="line"> element, tokens within a <span class="hl-{type}"> element.
+func (cb *ICKCodeBlock) RenderContent(out io.Writer) error {
+	if cb.CanCopy {
+		ickcore.RenderChild(out, cb, Button("", `class="ick-codeblock-copy" title="Copy" aria-label="copy"`).
+			SetSize(SIZE_SMALL).
+			SetIcon(*Icon("bi bi-clipboard"), false))
+	}
+
+	ickcore.RenderString(out, `<pre><code`)
+	if lang := highlight.Language(cb.Language); lang != "" {
+		ickcore.RenderString(out, ` class="language-`, lang, `"`)
+	}
+	ickcore.RenderString(out, `>`)
+
+	highlighted := func(n int) bool {
+		for _, r := range cb.Highlight {
+			if r.Contains(n) {
+				return true
+			}
+		}
+		return false
+	}
+
+	lines := splitlines(highlight.Tokenize(cb.Language, strings.ReplaceAll(cb.Code, "\r\n", "\n")))
+	for i, line := range lines {
+		ickcore.RenderString(out, `<span class="line`)
+		if highlighted(i + 1) {
+			ickcore.RenderString(out, ` is-highlighted`)
+		}
+		ickcore.RenderString(out, `">`)
+		if cb.LineNumbers {
+			ickcore.RenderString(out, `<span class="line-number">`, strconv.Itoa(i+1), `</span>`)
+		}
+		for _, tok := range line {
+			text := ickcore.EscapeString(ickcore.ESCCTX_CODE, tok.Text)
+			if class := tok.Type.Class(); class != "" {
+				ickcore.RenderString(out, `<span class="`, class, `">`, text, `</span>`)
+			} else {
+				ickcore.RenderString(out, text)
+			}
+		}
+		ickcore.RenderString(out, "</span>\n")
+	}
+
+	ickcore.RenderString(out, `</code></pre>`)
+	return nil
+}
+
+// ParseLineRanges parses a comma separated list of line numbers or ranges of line numbers, like "2,4-5".
+// Returns the list of the ranges, [{2 2} {4 5}].
+func ParseLineRanges(s string) ([]LineRange, error) {
+	ranges := make([]LineRange, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		from, to, isrange := strings.Cut(item, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, errors.New("invalid line number " + strconv.Quote(item))
+		}
+		last := first
+		if isrange {
+			if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || last < first {
+				return nil, errors.New("invalid range of lines " + strconv.Quote(item))
+			}
+		}
+		ranges = append(ranges, LineRange{First: first, Last: last})
+	}
+	return ranges, nil
+}
+
+// splitlines splits the tokens into lines, tokens spanning several lines are split too.
+func splitlines(tokens []highlight.Token) [][]highlight.Token {
+	lines := [][]highlight.Token{nil}
+	for _, tok := range tokens {
+		parts := strings.Split(tok.Text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], highlight.Token{Type: tok.Type, Text: part})
+			}
+		}
+	}
+	return lines
+}
+
+const codeblockStyle string = `.ick-codeblock { position: relative; }
+.ick-codeblock pre { padding: 1em 0; }
+.ick-codeblock .line { display: inline-block; box-sizing: border-box; min-width: 100%; padding: 0 1.5em; }
+.ick-codeblock .line.is-highlighted { background-color: #fff5b1; }
+.ick-codeblock .line-number { display: inline-block; min-width: 2em; margin-right: 1em; text-align: right; color: #999; user-select: none; }
+.ick-codeblock .ick-codeblock-copy { position: absolute; top: 0.5em; right: 0.5em; }
+.ick-codeblock .hl-keyword { color: #d73a49; }
+.ick-codeblock .hl-type { color: #6f42c1; }
+.ick-codeblock .hl-literal { color: #005cc5; }
+.ick-codeblock .hl-string { color: #032f62; }
+.ick-codeblock .hl-number { color: #005cc5; }
+.ick-codeblock .hl-comment { color: #6a737d; font-style: italic; }
+.ick-codeblock .hl-function { color: #6f42c1; }
+.ick-codeblock .hl-tag { color: #22863a; }
+.ick-codeblock .hl-attribute { color: #6f42c1; }
+.ick-codeblock .hl-property { color: #005cc5; }
+`
@@ -0,0 +1,63 @@
+package ick
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeBlock(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	err := ickcore.RenderChild(out, nil, CodeBlock("go", "/* a\nb */\nx := {{.y}} < 1").SetLineNumbers(true).SetHighlight(2))
+	require.NoError(t, err)
+	assert.Equal(t, `<div name="ickcodeblock" class="ick-codeblock has-line-numbers"><pre><code class="language-go">`+
+		`<span class="line"><span class="line-number">1</span><span class="hl-comment">/* a</span></span>`+"\n"+
+		`<span class="line is-highlighted"><span class="line-number">2</span><span class="hl-comment">b */</span></span>`+"\n"+
+		`<span class="line"><span class="line-number">3</span>x := &#123;&#123;.y}} &lt; <span class="hl-number">1</span></span>`+"\n"+
+		`</code></pre></div>`, out.String())
+
+	// paired ick-tag
+	out.Reset()
+	html := ickcore.ToHTML("<ick-codeblock language=\"html\" highlight=\"1, 2-3\" can-copy>\n\t\t&lt;b&gt;\n\t\t  x\n\t</ick-codeblock>")
+	err = ickcore.RenderChild(out, nil, html)
+	require.NoError(t, err)
+	assert.Equal(t, `<div name="ickcodeblock" class="ick-codeblock">`+
+		`<button name="ickbutton" class="ick-codeblock-copy button is-small" aria-label="copy" title="Copy"><span name="ickicon" class="icon"><i class="bi bi-clipboard"></i></span></button>`+
+		`<pre><code class="language-html">`+
+		`<span class="line is-highlighted"><span class="hl-tag">&lt;b&gt;</span></span>`+"\n"+
+		`<span class="line is-highlighted">  x</span>`+"\n"+
+		`</code></pre></div>`, out.String())
+
+	// unsupported language
+	out.Reset()
+	err = ickcore.RenderChild(out, nil, CodeBlock("python", "def f():"))
+	require.NoError(t, err)
+	assert.Equal(t, `<div name="ickcodeblock" class="ick-codeblock"><pre><code><span class="line">def f():</span>`+"\n"+`</code></pre></div>`, out.String())
+
+	assert.False(t, CodeBlock("go", "").NeedRendering())
+
+	// schema
+	hl := ickcore.Schemas()["ick-codeblock"].Property("highlight")
+	require.NotNil(t, hl)
+	assert.Equal(t, "string", hl.Kind)
+	assert.Contains(t, hl.Doc, `"2,4-5"`)
+}
+
+func TestParseLineRanges(t *testing.T) {
+	lines, err := ParseLineRanges(" 2,4-6,, 9 ")
+	require.NoError(t, err)
+	assert.Equal(t, []LineRange{{2, 2}, {4, 6}, {9, 9}}, lines)
+
+	lines, err = ParseLineRanges("1-1000000000")
+	require.NoError(t, err)
+	assert.Equal(t, []LineRange{{1, 1000000000}}, lines)
+
+	_, err = ParseLineRanges("3-1")
+	assert.Error(t, err)
+	_, err = ParseLineRanges("a")
+	assert.Error(t, err)
+}
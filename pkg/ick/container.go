@@ -1,5 +1,11 @@
 package ick
 
+import "github.com/icecake-framework/icecake/pkg/ickcore"
+
+func init() {
+	ickcore.RegisterEnum(CONTWIDTH_NONE, string(CONTWIDTH_OPTIONS))
+}
+
 type CONTAINER_WIDTH string
 
 const (
@@ -9,6 +9,7 @@ import (
 
 func init() {
 	ickcore.RegisterComposer("ick-delete", &ICKDelete{})
+	ickcore.RegisterEnum(DLTTYP_BUTTON, string(DLTTYP_BUTTON), string(DLTTYP_ANCHOR))
 }
 
 type DELETE_TYPE string
@@ -33,6 +34,16 @@ type ICKDelete struct {
 // Ensuring ICKDelete implements the right interface
 var _ ickcore.ContentComposer = (*ICKDelete)(nil)
 var _ ickcore.TagBuilder = (*ICKDelete)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKDelete)(nil)
+
+// SchemaDoc documents the ick-tag of ICKDelete, see ickcore.SchemaDocumenter.
+func (*ICKDelete) SchemaDoc() (string, map[string]string) {
+	return "ICKDelete renders a bulma delete button removing the element TargetId from the DOM when clicked.", map[string]string{
+		"DELETE_TYPE": "renders a <button> or an <a> element",
+		"TargetId":    "the element id to remove from the DOM when the delete button is clicked",
+		"SIZE":        "delete button size",
+	}
+}
 
 func Delete(id string, targetid string) *ICKDelete {
 	del := new(ICKDelete)
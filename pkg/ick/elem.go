@@ -6,7 +6,8 @@ import (
 	"github.com/icecake-framework/icecake/pkg/ickcore"
 )
 
-// ICKElem implements the Composer interface for a basic element
+// ICKElem implements the Composer interface for a basic element.
+// If the tag name is empty only the body is rendered, use ickcore.Fragment to handle several composers as a single unit.
 type ICKElem struct {
 	ickcore.BareSnippet
 
@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrBadHtmlFileExtention = errors.New("bad html file extension")
 	ErrMissingFileName      = errors.New("missing file name")
+	ErrRenderDiagnostics    = errors.New("rendering diagnostics")
 )
@@ -0,0 +1,287 @@
+package ick
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/icecake-framework/icecake/pkg/i18n"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+func init() {
+	ickcore.RegisterComposer("ick-number", &ICKNumber{})
+	ickcore.RegisterComposer("ick-datetime", &ICKDateTime{})
+	ickcore.RegisterComposer("ick-relativetime", &ICKRelativeTime{})
+	ickcore.RegisterEnum(i18n.DATE_NONE, string(i18n.DATE_NONE), string(i18n.DATE_SHORT), string(i18n.DATE_MEDIUM), string(i18n.DATE_LONG))
+}
+
+// ICKNumber is a composer rendering a number, or an amount of money, formatted according to the language of the rendering.
+// The language is the one of the page being rendered, unless Lang is set.
+//
+//	<ick-number value="1234.5" decimals="2"/>
+//	<ick-number value="9.9" currency="€" lang="fr"/>
+type ICKNumber struct {
+	meta ickcore.RMetaData
+
+	Value    float64
+	Decimals int    `ick:",default=-1"` // number of decimals, -1 renders as many decimals as necessary
+	Currency string // optional currency symbol, renders an amount with 2 decimals
+	Lang     string `ick:"lang"` // optional language, overrides the one of the rendering
+}
+
+// Ensuring ICKNumber implements the right interface
+var _ ickcore.ContentComposer = (*ICKNumber)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKNumber)(nil)
+
+// SchemaDoc documents the ick-tag of ICKNumber, see ickcore.SchemaDocumenter.
+func (*ICKNumber) SchemaDoc() (string, map[string]string) {
+	return "ICKNumber renders a number, or an amount of money, formatted according to the language of the rendering.", map[string]string{
+		"Value":    "the number to render",
+		"Decimals": "number of decimals, -1 renders as many decimals as necessary",
+		"Currency": "optional currency symbol, renders an amount with 2 decimals",
+		"Lang":     "optional language, overrides the one of the rendering",
+	}
+}
+
+// Number returns a new ICKNumber rendering v with as many decimals as necessary.
+func Number(v float64) *ICKNumber {
+	n := new(ICKNumber)
+	n.Value = v
+	n.Decimals = -1
+	return n
+}
+
+// SetDecimals sets the number of decimals to render
+func (n *ICKNumber) SetDecimals(decimals int) *ICKNumber {
+	n.Decimals = decimals
+	return n
+}
+
+// SetCurrency renders the number as an amount of money in the currency symbol
+func (n *ICKNumber) SetCurrency(symbol string) *ICKNumber {
+	n.Currency = symbol
+	return n
+}
+
+// SetLang forces the language of the formatting
+func (n *ICKNumber) SetLang(lang string) *ICKNumber {
+	n.Lang = lang
+	return n
+}
+
+// RMeta returns a reference to the rendering metadata
+func (n *ICKNumber) RMeta() *ickcore.RMetaData {
+	return &n.meta
+}
+
+// NeedRendering always returns true, zero is a number.
+func (n *ICKNumber) NeedRendering() bool {
+	return true
+}
+
+// RenderContent renders the formatted number.
+func (n *ICKNumber) RenderContent(out io.Writer) error {
+	lang := currentlang(n.Lang, out)
+	s := i18n.FormatNumber(lang, n.Value, n.Decimals)
+	if n.Currency != "" {
+		s = i18n.FormatCurrency(lang, n.Value, n.Currency)
+	}
+	_, err := ickcore.RenderText(out, s)
+	return err
+}
+
+/******************************************************************************/
+
+// timeattributes describes the time attribute of ICKDateTime and ICKRelativeTime.
+var timeattributes = []ickcore.PropertySchema{
+	{Name: "time", Kind: "string", Doc: `RFC 3339 time, local time like "2006-01-02T15:04", or date like "2006-01-02"`},
+}
+
+// ICKDateTime is an icecake snippet rendering a date and a time formatted according to the language of the rendering,
+// within a <time> element with a machine readable datetime attribute.
+// The time attribute of the ick-tag accepts RFC 3339 times, or dates like "2006-01-02".
+//
+//	<ick-datetime time="2023-03-05T14:07:00Z" date-style="long" with-time/>
+type ICKDateTime struct {
+	ickcore.BareSnippet
+
+	Time      time.Time
+	DateStyle i18n.DATE_STYLE `ick:",default=medium"` // none, short, medium or long
+	WithTime  bool            // renders the time after the date
+	Lang      string          `ick:"lang"` // optional language, overrides the one of the rendering
+}
+
+// Ensuring ICKDateTime implements the right interface
+var _ ickcore.ContentComposer = (*ICKDateTime)(nil)
+var _ ickcore.TagBuilder = (*ICKDateTime)(nil)
+var _ ickcore.AttributeUnfolder = (*ICKDateTime)(nil)
+var _ ickcore.AttributeDescriber = (*ICKDateTime)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKDateTime)(nil)
+
+// SchemaDoc documents the ick-tag of ICKDateTime, see ickcore.SchemaDocumenter.
+func (*ICKDateTime) SchemaDoc() (string, map[string]string) {
+	return "ICKDateTime renders a date and a time formatted according to the language of the rendering, within a <time> element.", map[string]string{
+		"DateStyle": "none, short, medium or long",
+		"WithTime":  "renders the time after the date",
+		"Lang":      "optional language, overrides the one of the rendering",
+	}
+}
+
+// DateTime returns a new ICKDateTime rendering the date of t with the style.
+func DateTime(t time.Time, style i18n.DATE_STYLE, attrs ...string) *ICKDateTime {
+	dt := new(ICKDateTime)
+	dt.Time = t
+	dt.DateStyle = style
+	dt.Tag().ParseAttributes(attrs...)
+	return dt
+}
+
+// SetWithTime renders or hides the time
+func (dt *ICKDateTime) SetWithTime(f bool) *ICKDateTime {
+	dt.WithTime = f
+	return dt
+}
+
+// SetLang forces the language of the formatting
+func (dt *ICKDateTime) SetLang(lang string) *ICKDateTime {
+	dt.Lang = lang
+	return dt
+}
+
+// UnfoldAttribute parses the time attribute of the ick-tag. Other attributes get the default mapping.
+func (dt *ICKDateTime) UnfoldAttribute(name string, value string) (err error) {
+	if !strings.EqualFold(name, "time") {
+		return ickcore.ErrAttributeNotUnfolded
+	}
+	dt.Time, err = parsetime(value)
+	return err
+}
+
+// DescribeAttributes describes the time attribute unfolded by UnfoldAttribute.
+func (dt *ICKDateTime) DescribeAttributes() []ickcore.PropertySchema {
+	return timeattributes
+}
+
+// NeedRendering returns true if the time is set.
+func (dt *ICKDateTime) NeedRendering() bool {
+	return !dt.Time.IsZero()
+}
+
+// BuildTag returns tag <time datetime="{rfc3339}" {attributes}>
+func (dt *ICKDateTime) BuildTag() ickcore.Tag {
+	dt.Tag().
+		SetTagName("time").
+		SetAttribute("datetime", dt.Time.Format(time.RFC3339))
+	return *dt.Tag()
+}
+
+// RenderContent renders the formatted date and time.
+func (dt *ICKDateTime) RenderContent(out io.Writer) error {
+	_, err := ickcore.RenderText(out, i18n.FormatDateTime(currentlang(dt.Lang, out), dt.Time, dt.DateStyle, dt.WithTime))
+	return err
+}
+
+/******************************************************************************/
+
+// ICKRelativeTime is an icecake snippet rendering the time elapsed since, or until, a time, like "3 minutes ago",
+// according to the language of the rendering.
+// The rendering is static, use the ickui.ICKRelativeTime to update it while the page is displayed.
+//
+//	<ick-relativetime time="2023-03-05T14:07:00Z"/>
+type ICKRelativeTime struct {
+	ickcore.BareSnippet
+
+	Time time.Time
+	Now  time.Time `ick:"-"`    // the reference time, time.Now() if zero
+	Lang string    `ick:"lang"` // optional language, overrides the one of the rendering
+}
+
+// Ensuring ICKRelativeTime implements the right interface
+var _ ickcore.ContentComposer = (*ICKRelativeTime)(nil)
+var _ ickcore.TagBuilder = (*ICKRelativeTime)(nil)
+var _ ickcore.AttributeUnfolder = (*ICKRelativeTime)(nil)
+var _ ickcore.AttributeDescriber = (*ICKRelativeTime)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKRelativeTime)(nil)
+
+// SchemaDoc documents the ick-tag of ICKRelativeTime, see ickcore.SchemaDocumenter.
+func (*ICKRelativeTime) SchemaDoc() (string, map[string]string) {
+	return "ICKRelativeTime renders the time elapsed since, or until, a time, like \"3 minutes ago\", according to the language of the rendering.", map[string]string{
+		"Lang": "optional language, overrides the one of the rendering",
+	}
+}
+
+// RelativeTime returns a new ICKRelativeTime rendering the time elapsed since, or until, t.
+func RelativeTime(t time.Time, attrs ...string) *ICKRelativeTime {
+	rt := new(ICKRelativeTime)
+	rt.Time = t
+	rt.Tag().ParseAttributes(attrs...)
+	return rt
+}
+
+// SetLang forces the language of the formatting
+func (rt *ICKRelativeTime) SetLang(lang string) *ICKRelativeTime {
+	rt.Lang = lang
+	return rt
+}
+
+// UnfoldAttribute parses the time attribute of the ick-tag. Other attributes get the default mapping.
+func (rt *ICKRelativeTime) UnfoldAttribute(name string, value string) (err error) {
+	if !strings.EqualFold(name, "time") {
+		return ickcore.ErrAttributeNotUnfolded
+	}
+	rt.Time, err = parsetime(value)
+	return err
+}
+
+// DescribeAttributes describes the time attribute unfolded by UnfoldAttribute.
+func (rt *ICKRelativeTime) DescribeAttributes() []ickcore.PropertySchema {
+	return timeattributes
+}
+
+// NeedRendering returns true if the time is set.
+func (rt *ICKRelativeTime) NeedRendering() bool {
+	return !rt.Time.IsZero()
+}
+
+// BuildTag returns tag <time datetime="{rfc3339}" {attributes}>
+func (rt *ICKRelativeTime) BuildTag() ickcore.Tag {
+	rt.Tag().
+		SetTagName("time").
+		SetAttribute("datetime", rt.Time.Format(time.RFC3339))
+	return *rt.Tag()
+}
+
+// RenderContent renders the formatted relative time.
+func (rt *ICKRelativeTime) RenderContent(out io.Writer) error {
+	now := rt.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	_, err := ickcore.RenderText(out, i18n.FormatRelativeTime(currentlang(rt.Lang, out), rt.Time, now))
+	return err
+}
+
+/******************************************************************************/
+
+// currentlang returns lang if set, otherwise the language of the rendering into out, otherwise the fallback language of the i18n.DefaultBundle.
+func currentlang(lang string, out io.Writer) string {
+	if lang != "" {
+		return lang
+	}
+	if lang = ickcore.CurrentContext(out).Lang(); lang != "" {
+		return lang
+	}
+	return i18n.DefaultBundle.Fallback()
+}
+
+// parsetime parses a RFC 3339 time, a local time without seconds "2006-01-02T15:04", or a date "2006-01-02".
+func parsetime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
@@ -0,0 +1,61 @@
+package ick
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/icecake-framework/icecake/pkg/i18n"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	d := time.Date(2023, time.March, 5, 14, 7, 0, 0, time.UTC)
+
+	// go api
+	tst := []struct {
+		cmp  ickcore.Composer
+		want string
+	}{
+		{Number(1234.5), `1,234.5`},
+		{Number(1234.5).SetDecimals(2).SetLang("de"), `1.234,50`},
+		{Number(3).SetCurrency("<$>"), `&lt;$&gt;3.00`},
+		{DateTime(d, i18n.DATE_LONG), `<time name="ickdatetime" datetime="2023-03-05T14:07:00Z">March 5, 2023</time>`},
+		{DateTime(d, i18n.DATE_SHORT).SetWithTime(true).SetLang("fr"), `<time name="ickdatetime" datetime="2023-03-05T14:07:00Z">05/03/2023 14:07</time>`},
+		{DateTime(time.Time{}, i18n.DATE_LONG), ``},
+		{&ICKRelativeTime{Time: d, Now: d.Add(3 * time.Minute)}, `<time name="ickrelativetime" datetime="2023-03-05T14:07:00Z">3 minutes ago</time>`},
+		{RelativeTime(time.Now().Add(-time.Hour)), `1 hour ago`},
+	}
+	for i, tc := range tst {
+		out := new(bytes.Buffer)
+		err := ickcore.RenderChild(out, nil, tc.cmp)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), tc.want, "test %v", i)
+	}
+
+	// ick-tags with the language of the context
+	out := new(bytes.Buffer)
+	root := ickcore.ToHTML(`<ick-number value="1234.5"/> <ick-number value="2" decimals="1" currency="€" lang="en"/> ` +
+		`<ick-datetime time="2023-03-05" class="date"/> <ick-datetime time="2023-03-05T14:07:00Z" date-style="none" with-time/> ` +
+		`<ick-relativetime time="2023-03-05"/>`)
+	root.RMeta().Context = ickcore.NewRenderContext()
+	root.RMeta().Context.SetLang("fr")
+	err := ickcore.RenderChild(out, nil, root)
+	require.NoError(t, err)
+	assert.Regexp(t, "^1\u202f234,5 €2.00 "+
+		`<time name="ickdatetime" class="date" datetime="2023-03-05T00:00:00Z">5 mars 2023</time> `+
+		`<time name="ickdatetime" datetime="2023-03-05T14:07:00Z">14:07</time> `+
+		`<time name="ickrelativetime" datetime="2023-03-05T00:00:00Z">il y a \d+ ans</time>$`,
+		out.String())
+
+	// invalid time
+	out.Reset()
+	report := new(ickcore.RenderReport)
+	root = ickcore.ToHTML(`<ick-datetime time="yesterday"/>`)
+	root.RMeta().Report = report
+	err = ickcore.RenderChild(out, nil, root)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Len())
+}
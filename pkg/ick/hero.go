@@ -1,6 +1,7 @@
 package ick
 
 import (
+	"errors"
 	"io"
 
 	"github.com/icecake-framework/icecake/pkg/ickcore"
@@ -8,6 +9,7 @@ import (
 
 func init() {
 	ickcore.RegisterComposer("ick-hero", &ICKHero{})
+	ickcore.RegisterEnum(HH_SMALL, HH_OPTIONS)
 }
 
 // The height of the hero section
@@ -24,6 +26,13 @@ const (
 	HH_OPTIONS                 string      = string(HH_SMALL + " " + HH_MEDIUM + " " + HH_LARGE + " " + HH_HALFHEIGHT + " " + HH_FULLFHEIGHT + " " + HH_FULLFHEIGHT_WITH_NAVBAR)
 )
 
+// ICKHero is an icecake snippet providing the HTML rendering for a [bulma hero].
+//
+// ICKHero can be unfolded from a paired ick-tag. The default content is rendered in the hero body after the title, and the following slots are available:
+//
+//	<ick-hero><ick-slot name="head">...</ick-slot><ick-slot name="title">...</ick-slot><ick-slot name="subtitle">...</ick-slot><ick-slot name="foot">...</ick-slot></ick-hero>
+//
+// [bulma hero]: https://bulma.io/documentation/layout/hero/
 type ICKHero struct {
 	ickcore.BareSnippet
 
@@ -34,22 +43,59 @@ type ICKHero struct {
 	Title    ICKTitle
 	Subtitle ICKTitle
 	Centered bool
-	CWidth   CONTAINER_WIDTH
+	CWidth   CONTAINER_WIDTH `ick:"width"`
 
 	CTA ICKButton
 
+	Body ickcore.ContentStack // optional content rendered in the hero body, after the CTA
+
 	InsideFoot ickcore.ContentComposer
 }
 
 // Ensuring Hero implements the right interface
 var _ ickcore.ContentComposer = (*ICKHero)(nil)
 var _ ickcore.TagBuilder = (*ICKHero)(nil)
+var _ ickcore.SlotReceiver = (*ICKHero)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKHero)(nil)
+
+// SchemaDoc documents the ick-tag of ICKHero, see ickcore.SchemaDocumenter.
+func (*ICKHero) SchemaDoc() (string, map[string]string) {
+	return "ICKHero renders a bulma hero section. The content of a paired ick-tag goes into the hero body, head, title, subtitle and foot are named slots.", map[string]string{
+		"Height":   "the height of the hero section",
+		"Title":    "the title of the hero body",
+		"Subtitle": "the subtitle of the hero body",
+		"Centered": "centers the content of the hero body",
+		"CWidth":   "the width of the container of the hero body",
+		"CTA":      "optional call to action button, rendered after the subtitle",
+	}
+}
 
 func Hero() *ICKHero {
 	hero := new(ICKHero)
 	return hero
 }
 
+// SetSlot handles the inner content of an ick-hero paired tag.
+// title and subtitle slots are rendered as text.
+func (h *ICKHero) SetSlot(name string, content *ickcore.HTMLString) error {
+	switch name {
+	case "":
+		h.Body.Push(content)
+	case "head":
+		h.InsideHead = content
+	case "foot":
+		h.InsideFoot = content
+	case "title":
+		h.Title.Title = string(content.Bytes())
+	case "subtitle":
+		h.Subtitle.Title = string(content.Bytes())
+		h.Subtitle.IsSubtitle = true
+	default:
+		return errors.New("unknown slot")
+	}
+	return nil
+}
+
 // Tag Builder used by the rendering functions.
 func (h *ICKHero) BuildTag() ickcore.Tag {
 	h.Tag().SetTagName("section").AddClass("hero").PickClass(HH_OPTIONS, string(h.Height))
@@ -68,6 +114,7 @@ func (h *ICKHero) RenderContent(out io.Writer) error {
 	c := Container(h.CWidth)
 	c.Tag().AddClassIf(h.Centered, "has-text-centered")
 	c.Append(&h.Title, &h.Subtitle, &h.CTA)
+	c.Append(h.Body.Stack...)
 	ickcore.RenderChild(out, h, c)
 
 	ickcore.RenderString(out, `</div>`)
@@ -0,0 +1,427 @@
+// Code generated by icecake gen. DO NOT EDIT.
+
+package ick
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/i18n"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+// NewComposer returns a new ICKButton, see ickcore.ComposerFactory.
+func (*ICKButton) NewComposer() ickcore.Composer {
+	return new(ICKButton)
+}
+
+// ApplyAttributes sets the properties of ICKButton from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKButton) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "opening-icon":
+			err = ickcore.UnfoldJSON(value, &cmp.OpeningIcon, "ick.ICKIcon")
+		case "title":
+			cmp.Title = value
+		case "closing-icon":
+			err = ickcore.UnfoldJSON(value, &cmp.ClosingIcon, "ick.ICKIcon")
+		case "href":
+			cmp.HRef, err = url.Parse(value)
+		case "is-outlined":
+			cmp.IsOutlined = ickcore.UnfoldBool(value)
+		case "is-rounded":
+			cmp.IsRounded = ickcore.UnfoldBool(value)
+		case "color":
+			cmp.COLOR = COLOR(value)
+		case "size":
+			cmp.SIZE = SIZE(value)
+		case "is-disabled":
+			cmp.IsDisabled = ickcore.UnfoldBool(value)
+		case "is-loading":
+			cmp.IsLoading = ickcore.UnfoldBool(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKCard, see ickcore.ComposerFactory.
+func (*ICKCard) NewComposer() ickcore.Composer {
+	return new(ICKCard)
+}
+
+// ApplyAttributes sets the properties of ICKCard from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKCard) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "title":
+			cmp.Title = *ickcore.ToHTML(value)
+		case "body":
+			err = ickcore.UnfoldJSON(value, &cmp.Body, "ick.ICKElem")
+		case "image":
+			if cmp.Image == nil {
+				cmp.Image = new(ICKImage)
+			}
+			err = ickcore.UnfoldJSON(value, cmp.Image, "ick.ICKImage")
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKCodeBlock, see ickcore.ComposerFactory.
+func (*ICKCodeBlock) NewComposer() ickcore.Composer {
+	return new(ICKCodeBlock)
+}
+
+// ApplyAttributes sets the properties of ICKCodeBlock from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKCodeBlock) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "code":
+			cmp.Code = value
+		case "language":
+			cmp.Language = value
+		case "line-numbers":
+			cmp.LineNumbers = ickcore.UnfoldBool(value)
+		case "highlight":
+			err = ickcore.UnfoldJSON(value, &cmp.Highlight, "[]ick.LineRange")
+		case "can-copy":
+			cmp.CanCopy = ickcore.UnfoldBool(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKDateTime, see ickcore.ComposerFactory.
+func (*ICKDateTime) NewComposer() ickcore.Composer {
+	return new(ICKDateTime)
+}
+
+// ApplyAttributes sets the properties of ICKDateTime from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKDateTime) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "time":
+			err = cmp.Time.UnmarshalText([]byte(value))
+		case "date-style":
+			cmp.DateStyle = i18n.DATE_STYLE(value)
+		case "with-time":
+			cmp.WithTime = ickcore.UnfoldBool(value)
+		case "lang":
+			cmp.Lang = value
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKDelete, see ickcore.ComposerFactory.
+func (*ICKDelete) NewComposer() ickcore.Composer {
+	return new(ICKDelete)
+}
+
+// ApplyAttributes sets the properties of ICKDelete from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKDelete) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "delete-type":
+			cmp.DELETE_TYPE = DELETE_TYPE(value)
+		case "target-id":
+			cmp.TargetId = value
+		case "size":
+			cmp.SIZE = SIZE(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKHero, see ickcore.ComposerFactory.
+func (*ICKHero) NewComposer() ickcore.Composer {
+	return new(ICKHero)
+}
+
+// ApplyAttributes sets the properties of ICKHero from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKHero) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "height":
+			cmp.Height = HERO_HEIGHT(value)
+		case "inside-head":
+			cmp.InsideHead = ickcore.ToHTML(value)
+		case "title":
+			err = ickcore.UnfoldJSON(value, &cmp.Title, "ick.ICKTitle")
+		case "subtitle":
+			err = ickcore.UnfoldJSON(value, &cmp.Subtitle, "ick.ICKTitle")
+		case "centered":
+			cmp.Centered = ickcore.UnfoldBool(value)
+		case "width":
+			cmp.CWidth = CONTAINER_WIDTH(value)
+		case "cta":
+			err = ickcore.UnfoldJSON(value, &cmp.CTA, "ick.ICKButton")
+		case "body":
+			err = ickcore.UnfoldJSON(value, &cmp.Body, "ickcore.ContentStack")
+		case "inside-foot":
+			cmp.InsideFoot = ickcore.ToHTML(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKImage, see ickcore.ComposerFactory.
+func (*ICKImage) NewComposer() ickcore.Composer {
+	return new(ICKImage)
+}
+
+// ApplyAttributes sets the properties of ICKImage from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKImage) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "src":
+			cmp.Src, err = url.Parse(value)
+		case "alt":
+			cmp.Alt = value
+		case "size":
+			cmp.Size = IMG_SIZE(value)
+		case "is-rounded":
+			cmp.IsRounded = ickcore.UnfoldBool(value)
+		case "no-crop":
+			cmp.NoCrop = ickcore.UnfoldBool(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKInputField, see ickcore.ComposerFactory.
+func (*ICKInputField) NewComposer() ickcore.Composer {
+	return new(ICKInputField)
+}
+
+// ApplyAttributes sets the properties of ICKInputField from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKInputField) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "label":
+			cmp.Label = value
+		case "opening-icon":
+			err = ickcore.UnfoldJSON(value, &cmp.OpeningIcon, "ick.ICKIcon")
+		case "value":
+			cmp.Value = value
+		case "is-hidden":
+			cmp.IsHidden = ickcore.UnfoldBool(value)
+		case "closing-icon":
+			err = ickcore.UnfoldJSON(value, &cmp.ClosingIcon, "ick.ICKIcon")
+		case "place-holder":
+			cmp.PlaceHolder = value
+		case "help":
+			cmp.Help = value
+		case "state":
+			cmp.State = INPUT_STATE(value)
+		case "is-read-only":
+			cmp.IsReadOnly = ickcore.UnfoldBool(value)
+		case "can-toggle-visibility":
+			cmp.CanToggleVisibility = ickcore.UnfoldBool(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKMarkdown, see ickcore.ComposerFactory.
+func (*ICKMarkdown) NewComposer() ickcore.Composer {
+	return new(ICKMarkdown)
+}
+
+// ApplyAttributes sets the properties of ICKMarkdown from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKMarkdown) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "source":
+			cmp.Source = value
+		case "size":
+			cmp.SIZE = SIZE(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKMessage, see ickcore.ComposerFactory.
+func (*ICKMessage) NewComposer() ickcore.Composer {
+	return new(ICKMessage)
+}
+
+// ApplyAttributes sets the properties of ICKMessage from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKMessage) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "header":
+			cmp.Header = *ickcore.ToHTML(value)
+		case "msg":
+			err = ickcore.UnfoldJSON(value, &cmp.Msg, "ickcore.ContentStack")
+		case "can-delete":
+			cmp.CanDelete = ickcore.UnfoldBool(value)
+		case "color":
+			cmp.COLOR = COLOR(value)
+		case "size":
+			cmp.SIZE = SIZE(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKNumber, see ickcore.ComposerFactory.
+func (*ICKNumber) NewComposer() ickcore.Composer {
+	return new(ICKNumber)
+}
+
+// ApplyAttributes sets the properties of ICKNumber from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKNumber) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "value":
+			var f float64
+			f, err = ickcore.UnfoldFloat(value, 64)
+			cmp.Value = f
+		case "decimals":
+			var i int64
+			i, err = ickcore.UnfoldInt(value, 0)
+			cmp.Decimals = int(i)
+		case "currency":
+			cmp.Currency = value
+		case "lang":
+			cmp.Lang = value
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKRelativeTime, see ickcore.ComposerFactory.
+func (*ICKRelativeTime) NewComposer() ickcore.Composer {
+	return new(ICKRelativeTime)
+}
+
+// ApplyAttributes sets the properties of ICKRelativeTime from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKRelativeTime) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "time":
+			err = cmp.Time.UnmarshalText([]byte(value))
+		case "lang":
+			cmp.Lang = value
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKTagLabel, see ickcore.ComposerFactory.
+func (*ICKTagLabel) NewComposer() ickcore.Composer {
+	return new(ICKTagLabel)
+}
+
+// ApplyAttributes sets the properties of ICKTagLabel from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKTagLabel) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "header":
+			cmp.Header = value
+		case "text":
+			cmp.Text = value
+		case "can-delete":
+			cmp.CanDelete = ickcore.UnfoldBool(value)
+		case "is-rounded":
+			cmp.IsRounded = ickcore.UnfoldBool(value)
+		case "header-color":
+			cmp.HeaderColor = COLOR(value)
+		case "text-color":
+			cmp.TextColor = COLOR(value)
+		case "taglabel-size":
+			cmp.TAGLABEL_SIZE = TAGLABEL_SIZE(value)
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewComposer returns a new ICKTranslation, see ickcore.ComposerFactory.
+func (*ICKTranslation) NewComposer() ickcore.Composer {
+	return new(ICKTranslation)
+}
+
+// ApplyAttributes sets the properties of ICKTranslation from the attributes of its ick-tag, see ickcore.AttributeApplier.
+func (cmp *ICKTranslation) ApplyAttributes(attrs ickcore.AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "key":
+			cmp.Key = value
+		case "lang":
+			cmp.Lang = value
+		default:
+			err = ickcore.ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package ickui
+
+import (
+	"github.com/icecake-framework/icecake/pkg/browser"
+	"github.com/icecake-framework/icecake/pkg/dom"
+	"github.com/icecake-framework/icecake/pkg/event"
+	"github.com/icecake-framework/icecake/pkg/ick"
+)
+
+type ICKCodeBlock struct {
+	ick.ICKCodeBlock
+	dom.UI
+
+	// OnCopy, if it is set, it's called once the code has been copied to the clipboard
+	OnCopy func(*ICKCodeBlock)
+}
+
+// Ensure ICKCodeBlock implements UIComposer interface
+var _ dom.UIComposer = (*ICKCodeBlock)(nil)
+
+// CodeBlock factory, the copy button is rendered.
+func CodeBlock(language string, code string, attrs ...string) *ICKCodeBlock {
+	cb := new(ICKCodeBlock)
+	cb.ICKCodeBlock = *ick.CodeBlock(language, code, attrs...)
+	cb.CanCopy = true
+	return cb
+}
+
+/******************************************************************************/
+
+// AddListeners listens to the clicks on the copy button, if any.
+// The listener is added to the code block element so the button does not need an id.
+func (cb *ICKCodeBlock) AddListeners() {
+	if !cb.CanCopy {
+		return
+	}
+	cb.DOM.AddMouseEvent(event.MOUSE_ONCLICK, func(evt *event.MouseEvent, _ *dom.Element) {
+		if evt.Target().Call("closest", ".ick-codeblock-copy").Truthy() {
+			cb.Copy()
+		}
+	})
+}
+
+// Copy writes the code to the clipboard
+func (cb *ICKCodeBlock) Copy() {
+	browser.WriteClipboardText(cb.Code)
+	if cb.OnCopy != nil {
+		cb.OnCopy(cb)
+	}
+}
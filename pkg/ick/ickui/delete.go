@@ -16,7 +16,7 @@ type ICKDelete struct {
 	dom.UI
 
 	// The TargetID will be automatically removed after the clock Timeout duration if not zero.
-	// The timer starts when the delete button is rendered (call to addlisteners).
+	// The timer starts when the delete button is mounted, and stops when it's unmounted.
 	clock.Clock
 
 	// OnDelete, if it is set, it's called when the deletion occurs and after the targetId has been removed.
@@ -25,6 +25,8 @@ type ICKDelete struct {
 
 // Ensure Button implements UIComposer interface
 var _ dom.UIComposer = (*ICKDelete)(nil)
+var _ dom.Mounter = (*ICKDelete)(nil)
+var _ dom.Unmounter = (*ICKDelete)(nil)
 
 func Delete(id string, targetid string) *ICKDelete {
 	del := new(ICKDelete)
@@ -63,12 +65,23 @@ func (del *ICKDelete) AddListeners() {
 		del.DOM.AddMouseEvent(event.MOUSE_ONCLICK, func(*event.MouseEvent, *dom.Element) {
 			del.RemoveTarget()
 		})
-		del.Clock.Start(del.RemoveTarget)
 	} else {
 		verbose.Println(verbose.WARNING, "ICKDelete.AddListeners: missing TargetID")
 	}
 }
 
+// OnMount starts the clock, if there's a target to remove.
+func (del *ICKDelete) OnMount(*dom.Element) {
+	if del.TargetId != "" {
+		del.Clock.Start(del.RemoveTarget)
+	}
+}
+
+// OnUnmount stops the clock.
+func (del *ICKDelete) OnUnmount() {
+	del.Clock.Stop()
+}
+
 func (del *ICKDelete) RemoveListeners() {
 	del.DOM.RemoveListeners()
 }
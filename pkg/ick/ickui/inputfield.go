@@ -171,14 +171,14 @@ func (in *ICKInputField) RefreshLabel() {
 		dom.Id(lblid).Remove()
 	} else if !dom.Id(lblid).IsDefined() {
 		// label not yet in the dom = insert it before the control
-		sublbl := ick.Elem("label", `class="label"`, ickcore.ToHTML(in.Label))
+		sublbl := ick.Elem("label", `class="label"`, ickcore.ToText(in.Label))
 		sublbl.SetId(lblid)
 		dom.Id(in.Tag().SubId("control")).InsertSnippet(dom.INSERT_BEFORE_ME, sublbl)
 	} else {
 		// label already in the dom = update it
 		isin := dom.Id(lblid).InnerHTML()
 		if isin != in.Label {
-			dom.Id(lblid).InsertSnippet(dom.INSERT_BODY, ickcore.ToHTML(in.Label))
+			dom.Id(lblid).InsertSnippet(dom.INSERT_BODY, ickcore.ToText(in.Label))
 		}
 	}
 }
@@ -190,14 +190,14 @@ func (in *ICKInputField) RefreshHelp(help string) {
 		dom.Id(helpid).Remove()
 	} else if !dom.Id(helpid).IsDefined() {
 		// help not yet in the dom = insert it after the control
-		subhelp := ick.Elem("p", `class="help"`, ickcore.ToHTML(help))
+		subhelp := ick.Elem("p", `class="help"`, ickcore.ToText(help))
 		subhelp.SetId(helpid)
 		dom.Id(in.Tag().SubId("control")).InsertSnippet(dom.INSERT_AFTER_ME, subhelp)
 	} else {
 		// help already in the dom = update it
 		isin := dom.Id(helpid).InnerHTML()
 		if isin != help {
-			dom.Id(helpid).InsertSnippet(dom.INSERT_BODY, ickcore.ToHTML(help))
+			dom.Id(helpid).InsertSnippet(dom.INSERT_BODY, ickcore.ToText(help))
 		}
 	}
 }
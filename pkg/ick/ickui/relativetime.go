@@ -0,0 +1,73 @@
+package ickui
+
+import (
+	"time"
+
+	"github.com/icecake-framework/icecake/pkg/clock"
+	"github.com/icecake-framework/icecake/pkg/dom"
+	"github.com/icecake-framework/icecake/pkg/ick"
+	"github.com/icecake-framework/icecake/pkg/js"
+	"github.com/lolorenzo777/verbose"
+)
+
+type ICKRelativeTime struct {
+	ick.ICKRelativeTime
+	dom.UI
+
+	// The relative time is rendered again at every tic of the clock, every minute by default.
+	// The clock starts when the relative time is mounted, and stops when it's unmounted.
+	clock.Clock
+}
+
+// Ensure ICKRelativeTime implements UIComposer interface
+var _ dom.UIComposer = (*ICKRelativeTime)(nil)
+var _ dom.Mounter = (*ICKRelativeTime)(nil)
+var _ dom.Unmounter = (*ICKRelativeTime)(nil)
+
+// RelativeTime factory, the rendering is updated every minute.
+func RelativeTime(t time.Time, attrs ...string) *ICKRelativeTime {
+	rt := new(ICKRelativeTime)
+	rt.ICKRelativeTime = *ick.RelativeTime(t, attrs...)
+	rt.TickerStep = time.Minute
+	return rt
+}
+
+/******************************************************************************/
+
+// Wrap implements the JSValueWrapper to enable wrapping of a dom.Element usually
+// to wrap embedded component instantiated during unfolding an html string.
+// The time is read from the datetime attribute, and the language from the document if not set.
+func (rt *ICKRelativeTime) Wrap(jsvp js.JSValueProvider) {
+	if rt.UI.Wrap(jsvp); !rt.DOM.IsInDOM() {
+		return
+	}
+	if dt, has := rt.DOM.Attribute("datetime"); has {
+		t, err := time.Parse(time.RFC3339, dt)
+		if err != nil {
+			verbose.Error("ICKRelativeTime.Wrap:", err)
+		}
+		rt.Time = t
+	}
+	if rt.Lang == "" {
+		rt.Lang, _ = dom.Doc().RootElement().Attribute("lang")
+	}
+}
+
+// OnMount starts the clock refreshing the relative time.
+func (rt *ICKRelativeTime) OnMount(*dom.Element) {
+	if rt.TickerStep == 0 {
+		rt.TickerStep = time.Minute
+	}
+	rt.Tic = func(*clock.Clock) {
+		rt.Now = time.Time{}
+		if err := rt.RefreshContent(rt); err != nil {
+			verbose.Error("ICKRelativeTime.Tic:", err)
+		}
+	}
+	rt.Clock.Start(nil)
+}
+
+// OnUnmount stops the clock.
+func (rt *ICKRelativeTime) OnUnmount() {
+	rt.Clock.Stop()
+}
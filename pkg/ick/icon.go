@@ -72,12 +72,14 @@ func (icon *ICKIcon) BuildTag() ickcore.Tag {
 
 // RenderContent writes the HTML string corresponding to the content of the HTML element.
 func (icon *ICKIcon) RenderContent(out io.Writer) error {
+	key := ickcore.EscapeString(ickcore.ESCCTX_ATTRIBUTE, icon.Key)
 	if icon.Text == "" {
-		ickcore.RenderString(out, `<i class="`, icon.Key, `"></i>`)
+		ickcore.RenderString(out, `<i class="`, key, `"></i>`)
 	} else {
-		ickcore.RenderChild(out, icon,
-			Elem("span", `class="icon"`, ickcore.ToHTML(`<i class="`+icon.Key+`"></i>`)))
-		ickcore.RenderString(out, `<span>`+icon.Text+`</span>`)
+		ickcore.RenderChild(out, icon, Elem("span", `class="icon"`, ickcore.ToHTML(`<i class="`+key+`"></i>`)))
+		ickcore.RenderString(out, `<span>`)
+		ickcore.RenderText(out, icon.Text)
+		ickcore.RenderString(out, `</span>`)
 	}
 	return nil
 }
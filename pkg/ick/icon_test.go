@@ -0,0 +1,28 @@
+package ick
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIcon(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	err := ickcore.RenderChild(out, nil, Icon(`bi bi-book`))
+	require.NoError(t, err)
+	assert.Equal(t, `<span name="ickicon" class="icon"><i class="bi bi-book"></i></span>`, out.String())
+
+	out.Reset()
+	err = ickcore.RenderChild(out, nil, Icon(`x" onmouseover="alert(1)`))
+	require.NoError(t, err)
+	assert.Equal(t, `<span name="ickicon" class="icon"><i class="x&#34; onmouseover=&#34;alert(1)"></i></span>`, out.String())
+
+	out.Reset()
+	err = ickcore.RenderChild(out, nil, Icon(`bi bi-book`).SetText(`<b>docs</b>`))
+	require.NoError(t, err)
+	assert.Equal(t, `<span name="ickicon" class="icon-text"><span class="icon"><i class="bi bi-book"></i></span><span>&lt;b&gt;docs&lt;/b&gt;</span></span>`, out.String())
+}
@@ -14,6 +14,7 @@ import (
 
 func init() {
 	ickcore.RegisterComposer("ick-image", &ICKImage{})
+	ickcore.RegisterEnum(IMG_16x16, IMG_SIZE_OPTIONS)
 }
 
 type IMG_SIZE string
@@ -66,6 +67,18 @@ type ICKImage struct {
 // Ensuring ICKImage implements the right interface
 var _ ickcore.ContentComposer = (*ICKImage)(nil)
 var _ ickcore.TagBuilder = (*ICKImage)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKImage)(nil)
+
+// SchemaDoc documents the ick-tag of ICKImage, see ickcore.SchemaDocumenter.
+func (*ICKImage) SchemaDoc() (string, map[string]string) {
+	return "ICKImage renders an img element embedded into a figure container specifying the image size.", map[string]string{
+		"Src":       "the url for the source of the image",
+		"Alt":       "the alternative text",
+		"Size":      "the size or the ratio of the image",
+		"IsRounded": "rounded image style",
+		"NoCrop":    "avoids to crop the image if its size does not fit the Size property, the image may be reduced or distorted",
+	}
+}
 
 func Image(rawUrl string, alt string, size IMG_SIZE, attrs ...string) *ICKImage {
 	img := new(ICKImage)
@@ -8,6 +8,7 @@ import (
 
 func init() {
 	ickcore.RegisterComposer("ick-input", &ICKInputField{})
+	ickcore.RegisterEnum(INPUT_STD, string(INPUT_SUCCESS), string(INPUT_WARNING), string(INPUT_ERROR), string(INPUT_LOADING), string(INPUT_DISABLED), string(INPUT_STATIC))
 }
 
 var (
@@ -62,6 +63,23 @@ type ICKInputField struct {
 // Ensuring InputField implements the right interface
 var _ ickcore.ContentComposer = (*ICKInputField)(nil)
 var _ ickcore.TagBuilder = (*ICKInputField)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKInputField)(nil)
+
+// SchemaDoc documents the ick-tag of ICKInputField, see ickcore.SchemaDocumenter.
+func (*ICKInputField) SchemaDoc() (string, map[string]string) {
+	return "ICKInputField renders an input within a field, with an optional label and help text.", map[string]string{
+		"Label":               "optional label above the value",
+		"OpeningIcon":         "optional opening icon",
+		"Value":               "the input value",
+		"IsHidden":            "entered characters are hidden",
+		"ClosingIcon":         "optional closing icon",
+		"PlaceHolder":         "optional placeholder",
+		"Help":                "optional help text",
+		"State":               "input state",
+		"IsReadOnly":          "read only input field",
+		"CanToggleVisibility": "renders a button toggling the visibility of the entered characters",
+	}
+}
 
 func InputField(id string, value string, placeholder string, attrs ...string) *ICKInputField {
 	n := new(ICKInputField)
@@ -132,7 +150,7 @@ func (in *ICKInputField) RenderContent(out io.Writer) error {
 
 	// <label>
 	if in.Label != "" {
-		sublbl := Elem("label", `class="label mb-1"`, ickcore.ToHTML(in.Label))
+		sublbl := Elem("label", `class="label mb-1"`, ickcore.ToText(in.Label))
 		sublbl.SetId(in.Tag().SubId("label"))
 		ickcore.RenderChild(out, in, sublbl)
 	}
@@ -194,7 +212,7 @@ func (in *ICKInputField) RenderContent(out io.Writer) error {
 
 	// <p help>
 	if in.Help != "" {
-		subhelp := Elem("p", `class="help"`, ickcore.ToHTML(in.Help))
+		subhelp := Elem("p", `class="help"`, ickcore.ToText(in.Help))
 		subhelp.Tag().
 			SetId(in.Tag().SubId("help")).
 			SetClassIf(in.State == INPUT_SUCCESS, "is-success").
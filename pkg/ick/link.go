@@ -15,7 +15,7 @@ type ICKLink struct {
 	// HRef defines the associated url link.
 	// if nil the <a> tag is rendered without href attribute.
 	// Usually HRef is created calling TryParseHRef
-	HRef *url.URL
+	HRef *url.URL `ick:"href"`
 
 	Body ickcore.ContentStack // HTML Element body. A stack of content composers to render.
 }
@@ -0,0 +1,126 @@
+package ick
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/icecake-framework/icecake/pkg/markdown"
+)
+
+func init() {
+	ickcore.RegisterComposer("ick-markdown", &ICKMarkdown{})
+}
+
+// ICKMarkdown is an icecake snippet rendering a markdown document within a [bulma content] element.
+// The document is converted to HTML with the markdown package, then rendered like an HTMLString so ick-tags it may contain are unfolded.
+//
+// The document can be the inner content of a paired ick-tag, its common indentation is removed:
+//
+//	<ick-markdown>
+//		# Title
+//		Some **markdown** text with an <ick-tag text="new"/> tag.
+//	</ick-markdown>
+//
+// [bulma content]: https://bulma.io/documentation/elements/content/
+type ICKMarkdown struct {
+	ickcore.BareSnippet
+
+	// Source is the markdown document
+	Source string
+
+	// SIZE define the size of the content
+	SIZE
+}
+
+// Ensuring ICKMarkdown implements the right interface
+var _ ickcore.ContentComposer = (*ICKMarkdown)(nil)
+var _ ickcore.TagBuilder = (*ICKMarkdown)(nil)
+var _ ickcore.SlotReceiver = (*ICKMarkdown)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKMarkdown)(nil)
+
+// SchemaDoc documents the ick-tag of ICKMarkdown, see ickcore.SchemaDocumenter.
+func (*ICKMarkdown) SchemaDoc() (string, map[string]string) {
+	return "ICKMarkdown renders a markdown document within a bulma content element. The document is the content of a paired ick-tag, the ick-tags it contains are unfolded.", map[string]string{
+		"Source": "the markdown document",
+		"SIZE":   "the size of the content",
+	}
+}
+
+// Markdown returns a new ICKMarkdown rendering the markdown document src.
+func Markdown(src string) *ICKMarkdown {
+	md := new(ICKMarkdown)
+	md.Source = src
+	return md
+}
+
+// SetSize set the size of the content
+func (md *ICKMarkdown) SetSize(s SIZE) *ICKMarkdown {
+	md.SIZE = s
+	return md
+}
+
+// SetSlot handles the inner content of an ick-markdown paired tag, the markdown document.
+func (md *ICKMarkdown) SetSlot(name string, content *ickcore.HTMLString) error {
+	if name != "" {
+		return errors.New("unknown slot")
+	}
+	md.Source = dedent(string(content.Bytes()))
+	return nil
+}
+
+// NeedRendering returns true if the document is not blank.
+func (md *ICKMarkdown) NeedRendering() bool {
+	return strings.TrimSpace(md.Source) != ""
+}
+
+/******************************************************************************/
+
+// BuildTag returns tag <div class="content {classes}" {attributes}>
+func (md *ICKMarkdown) BuildTag() ickcore.Tag {
+	md.Tag().
+		SetTagName("div").
+		AddClass("content").
+		PickClass(SIZE_OPTIONS, string(md.SIZE))
+	return *md.Tag()
+}
+
+// RenderContent writes the HTML string converted from the markdown document.
+func (md *ICKMarkdown) RenderContent(out io.Writer) error {
+	return ickcore.RenderChild(out, md, ickcore.ToHTML(markdown.ToHTML(md.Source)))
+}
+
+// dedent removes the blank lines at the beginning of s and the indentation common to all its non-blank lines.
+func dedent(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	prefix := ""
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if i == 0 || !strings.HasPrefix(indent, prefix) {
+			prefix = commonprefix(prefix, indent, i == 0)
+		}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commonprefix returns the common prefix of a and b, or b if first is true.
+func commonprefix(a string, b string, first bool) string {
+	if first {
+		return b
+	}
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
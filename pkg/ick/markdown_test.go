@@ -0,0 +1,27 @@
+package ick
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdown(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	err := ickcore.RenderChild(out, nil, Markdown("# Hello\nSome *text* <ick-tag text=\"new\"/>").SetSize(SIZE_SMALL))
+	require.NoError(t, err)
+	assert.Equal(t, `<div name="ickmarkdown" class="content is-small"><h1 id="hello">Hello</h1>`+"\n"+`<p>Some <em>text</em> <span name="icktaglabel" class="tag">new</span></p>`+"\n</div>", out.String())
+
+	// inner content of a paired ick-tag
+	out.Reset()
+	html := ickcore.ToHTML("<ick-markdown>\n\t\t# T\n\t\t- a\n\t\t  - b\n\t</ick-markdown>")
+	err = ickcore.RenderChild(out, nil, html)
+	require.NoError(t, err)
+	assert.Equal(t, `<div name="ickmarkdown" class="content"><h1 id="t">T</h1>`+"\n<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n</ul>\n</div>", out.String())
+
+	assert.False(t, Markdown(" \n").NeedRendering())
+}
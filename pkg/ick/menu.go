@@ -41,7 +41,7 @@ type IckMenuItem struct {
 	// HRef defines the optional associated url link.
 	// If HRef is defined the item become an anchor link <a>, otherwise it's a <div>
 	// HRef can be nil. Usually it's created calling MenuItem.ParseHRef
-	HRef *url.URL
+	HRef *url.URL `ick:"href"`
 
 	// Highlight this item
 	IsActive bool
@@ -88,11 +88,11 @@ func (mnui *IckMenuItem) BuildTag() ickcore.Tag {
 func (mnui *IckMenuItem) RenderContent(out io.Writer) error {
 	switch mnui.Type {
 	case MENUIT_LABEL:
-		ickcore.RenderString(out, mnui.Text)
+		ickcore.RenderText(out, mnui.Text)
 	case MENUIT_FOOTER:
-		ickcore.RenderChild(out, mnui, ickcore.ToHTML(mnui.Text))
+		ickcore.RenderChild(out, mnui, ickcore.ToText(mnui.Text))
 	default:
-		item := Link(ickcore.ToHTML(mnui.Text)).SetHRef(mnui.HRef)
+		item := Link(ickcore.ToText(mnui.Text)).SetHRef(mnui.HRef)
 		item.Tag().SetClassIf(mnui.IsActive, "is-active")
 		ickcore.RenderChild(out, mnui, item)
 	}
@@ -37,6 +37,18 @@ type ICKMessage struct {
 // Ensuring ICKMessage implements the right interface
 var _ ickcore.ContentComposer = (*ICKMessage)(nil)
 var _ ickcore.TagBuilder = (*ICKMessage)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKMessage)(nil)
+
+// SchemaDoc documents the ick-tag of ICKMessage, see ickcore.SchemaDocumenter.
+func (*ICKMessage) SchemaDoc() (string, map[string]string) {
+	return "ICKMessage renders a bulma message.", map[string]string{
+		"Header":    "optional header to display on top of the message",
+		"Msg":       "the body of the message",
+		"CanDelete": "displays the delete button allowing the user to delete the message",
+		"COLOR":     "the color of the message",
+		"SIZE":      "the size of the message",
+	}
+}
 
 func Message(cnt ickcore.ContentComposer) *ICKMessage {
 	msg := new(ICKMessage)
@@ -44,6 +56,19 @@ func Message(cnt ickcore.ContentComposer) *ICKMessage {
 	return msg
 }
 
+// ErrorMessage returns a message in danger color displaying err.
+// It can be used as the fallback of an ickcore.ErrorBoundary:
+//
+//	eb := ickcore.NewErrorBoundary(nil, content)
+//	eb.FallbackFunc = func(err error) ickcore.ContentComposer { return ick.ErrorMessage(err) }
+func ErrorMessage(err error) *ICKMessage {
+	txt := "unknown error"
+	if err != nil {
+		txt = err.Error()
+	}
+	return Message(ickcore.ToText(txt)).SetColor(COLOR_DANGER)
+}
+
 // SetHeader set a message header
 func (msg *ICKMessage) SetHeader(header ickcore.HTMLString) *ICKMessage {
 	msg.Header = header
@@ -0,0 +1,28 @@
+package ick
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorMessage(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	err := ickcore.RenderChild(out, nil, ErrorMessage(errors.New("<oops>")))
+	require.NoError(t, err)
+	assert.Equal(t, `<div name="ickmessage" class="message is-danger"><div class="message-body pr-4 is-flex is-align-items-top is-justify-content-space-between"><span>&lt;oops&gt;</span></div></div>`, out.String())
+
+	// fallback of an error boundary
+	out.Reset()
+	eb := ickcore.NewErrorBoundary(nil, ickcore.ToHTML(`<ick-button>`))
+	eb.FallbackFunc = func(err error) ickcore.ContentComposer { return ErrorMessage(err) }
+	err = ickcore.RenderChild(out, nil, Elem("div", "", eb))
+	require.NoError(t, err)
+	assert.Equal(t, `<div><div name="ickmessage" class="message is-danger"><div class="message-body pr-4 is-flex is-align-items-top is-justify-content-space-between"><span>ick-button: closing tag missing</span></div></div></div>`, out.String())
+	assert.Error(t, eb.RMeta().RError)
+}
@@ -36,7 +36,7 @@ type ICKNavbarItem struct {
 	// HRef defines the optional associated url link.
 	// If HRef is defined the item become an anchor link <a>, otherwise it's a <div>
 	// HRef can be nil. Usually it's created calling NavbarItem.TryParseHRef
-	HRef *url.URL
+	HRef *url.URL `ick:"href"`
 
 	// ImageSrc defines an optional image to display at the begining of the Item
 	ImageSrc *url.URL // the url for the source of the image
@@ -1,6 +1,7 @@
 package ick
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/url"
@@ -8,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/icecake-framework/icecake/pkg/i18n"
 	"github.com/icecake-framework/icecake/pkg/ickcore"
 	"github.com/lolorenzo777/verbose"
 )
@@ -38,8 +40,10 @@ type Page struct {
 	Title       string     // the html "head/title" value.
 	Description string     // the html "head/meta description" value.
 	HeadItems   []HeadItem // the list of tags in the section <head>
+	Data        any        // the data context template expressions of the body are resolved against, see ickcore.RenderData
 
-	body ICKElem // The tagname is forced to "body" during rendering.
+	body ICKElem                // The body tag is rendered by the page, the ICKElem renders its content only.
+	ctx  *ickcore.RenderContext // the render context created by the last rendering, if not set by the user.
 
 	url  *url.URL // relative url of the html page.
 	wasm *url.URL // relative url of the html page.
@@ -102,7 +106,7 @@ func (pg *Page) ParseURL(rawHTMLUrl string) (err error) {
 			relpath = relpath[:extpos]
 		}
 		if relpath != "" {
-			pg.url.Path += ".html"
+			pg.url.Path = relpath + ".html"
 			pg.wasm, _ = url.Parse(relpath + ".wasm")
 		}
 	}
@@ -110,7 +114,7 @@ func (pg *Page) ParseURL(rawHTMLUrl string) (err error) {
 }
 
 // Body returns the HTMLSnippet used to render the body tag.
-// Attributes can be setup. The tag will be forced to body during rendering.
+// Attributes can be setup. The body tag is rendered by the page with these attributes.
 func (pg *Page) Body() *ICKElem {
 	return &pg.body
 }
@@ -135,6 +139,11 @@ func (pg Page) AbsURL() *url.URL {
 	return u
 }
 
+// T returns the message key of the i18n.DefaultBundle in the language of the page, see i18n.Bundle.Translate.
+func (pg *Page) T(key string, params i18n.Params) string {
+	return i18n.T(pg.Lang, key, params)
+}
+
 // AddHeadItem add a line in the <head> section of the HtmlFile
 func (f *Page) AddHeadItem(tagname string, attributes string) *Page {
 	item := NewHeadItem(tagname)
@@ -172,6 +181,9 @@ func (pg Page) WriteFile(outputpath string) (err error) {
 	}
 
 	// write it to the disk
+	if erro := os.MkdirAll(filepath.Dir(absfilename), os.ModePerm); erro != nil {
+		return erro
+	}
 	f, erro := os.OpenFile(absfilename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if erro != nil {
 		return erro
@@ -192,55 +204,135 @@ func (pg Page) WriteFile(outputpath string) (err error) {
 }
 
 // Render turns HtmlFile into a valid HTML syntax and write it to the output stream.
-// Declared required CSS files and styles are automatically added.
+//
+// The requirements of the composers are collected in a new RenderContext at every rendering, available with RMeta().Context.
+// A context set by the user with RMeta().Context is used as is, and its requirements are kept from one rendering to the next.
+// Required CSS files and the CSS styles of the composers actually rendered in the page are automatically added to the head.
+// Required scripts are added to the head or to the end of the body, according to their placement, before or after the wasm bootstrap script.
 func (pg *Page) RenderContent(out io.Writer) (err error) {
+	pg.meta.ResetEmbedded()
+	if pg.meta.Context == nil || pg.meta.Context == pg.ctx {
+		pg.ctx = ickcore.NewRenderContext()
+		pg.meta.Context = pg.ctx
+	}
+	if pg.meta.Context.Lang() == "" {
+		pg.meta.Context.SetLang(pg.Lang)
+	}
+	out = ickcore.Bind(out, pg)
+	if tracer := ickcore.CurrentTracer(out); tracer != nil {
+		var span *ickcore.TraceSpan
+		span, out = tracer.Begin(pg.RelURL().String(), pg, out)
+		defer tracer.End(span, pg)
+	}
+
+	// render the head items and the body first to collect the requirements
+	headitems := new(bytes.Buffer)
+	for i := range pg.HeadItems {
+		if err = ickcore.RenderChild(ickcore.Redirect(headitems, out), pg, &pg.HeadItems[i]); err != nil {
+			return err
+		}
+	}
+	// the body tag is rendered by the page, so the end of the page is rendered within it
+	body := new(bytes.Buffer)
+	bodytag := pg.body.BuildTag()
+	bodytag.SetTagName("body")
+	pg.body.Tag().SetTagName("")
+	if _, err = bodytag.RenderOpening(body); err != nil {
+		return err
+	}
+	if err = ickcore.RenderData(ickcore.Redirect(body, out), pg, pg.Data, &pg.body); err != nil {
+		return err
+	}
+	if err = pg.renderBodyEnd(ickcore.Redirect(body, out)); err != nil {
+		return err
+	}
+	if err = bodytag.RenderClosing(body); err != nil {
+		return err
+	}
 
 	// <!doctype>
-	ickcore.RenderString(out, `<!doctype html><html lang="`, pg.Lang, `">`)
+	head := new(bytes.Buffer)
+	ickcore.RenderString(head, `<!doctype html><html lang="`, ickcore.EscapeString(ickcore.ESCCTX_ATTRIBUTE, pg.Lang), `">`)
 
 	// <head>
-	ickcore.RenderString(out, `<head>`)
-	ickcore.RenderStringIf(pg.Title != "", out, "<title>", pg.Title, "</title>")
-	ickcore.RenderStringIf(pg.Description != "", out, `<meta name="description" content="`+pg.Description+`">`)
-	for _, item := range pg.HeadItems {
-		if err = ickcore.RenderChild(out, nil, &item); err != nil {
-			return err
-		}
+	ickcore.RenderString(head, `<head>`)
+	if pg.Title != "" {
+		ickcore.RenderString(head, "<title>")
+		ickcore.RenderText(head, pg.Title)
+		ickcore.RenderString(head, "</title>")
 	}
+	ickcore.RenderStringIf(pg.Description != "", head, `<meta name="description" content="`+ickcore.EscapeString(ickcore.ESCCTX_ATTRIBUTE, pg.Description)+`">`)
+	head.Write(headitems.Bytes())
 
 	// required css files, checking for duplicate
-	rcssfs := ickcore.RequiredCSSFile()
+	reg := ickcore.CurrentRegistry(out)
+	rcssfs := pg.meta.Context.CSSFiles(reg)
 	for _, rcssf := range rcssfs {
 		strrcssf := rcssf.String()
-		duplicate := false
-		for _, hi := range pg.HeadItems {
-			if hitn, _ := hi.Tag().TagName(); hitn == "link" {
-				href, found := hi.Tag().Attribute("href")
-				if found && href == strrcssf {
-					duplicate = true
-					break
-				}
-			}
-		}
-		ickcore.RenderStringIf(!duplicate, out, `<link rel="stylesheet" href="`+strrcssf+`">`)
+		duplicate := pg.hasHeadItem("link", "href", strrcssf)
+		ickcore.RenderStringIf(!duplicate, head, `<link rel="stylesheet" href="`+ickcore.EscapeString(ickcore.ESCCTX_URL, strrcssf)+`">`)
 	}
 
 	// required css styles
-	rcssstyle := ickcore.RequiredCSSStyle()
-	ickcore.RenderStringIf(rcssstyle != "", out, `<style>`, rcssstyle, `</style>`)
-
-	ickcore.RenderString(out, "</head>")
-
-	// <body>
-	pg.body.Tag().SetTagName("body")
-	ickcore.RenderChild(out, pg, &pg.body)
+	rcssstyle := pg.meta.Context.CSSStyle(reg)
+	if rcssstyle != "" && ickcore.AutoEscape {
+		rcssstyle = ickcore.EscapeString(ickcore.ESCCTX_STYLE, rcssstyle)
+	}
+	ickcore.RenderStringIf(rcssstyle != "", head, `<style>`, rcssstyle, `</style>`)
 
-	// wasm script, if any
-	// must be loaded at the end of the page because the wasm code interacts with the loading/loaded )DOM
-	ickcore.RenderChild(out, nil, pg.WasmScript())
+	// required scripts in the head, checking for duplicate with head items
+	for _, s := range pg.meta.Context.Scripts(reg, ickcore.JSPLACE_HEAD) {
+		if s.Src != nil && pg.hasHeadItem("script", "src", s.Src.String()) {
+			continue
+		}
+		s.Render(head)
+	}
+	ickcore.RenderString(head, "</head>")
 
-	// <closing>
-	ickcore.RenderString(out, "</html>")
+	// write the page
+	for _, b := range [][]byte{head.Bytes(), body.Bytes(), []byte("</html>")} {
+		if _, err = out.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// renderBodyEnd renders the content of the portals, the required scripts at the end of the body and the wasm script if any.
+// The wasm script must be loaded at the end of the page because the wasm code interacts with the loading/loaded DOM.
+func (pg *Page) renderBodyEnd(out io.Writer) (err error) {
+	ctx := ickcore.CurrentContext(out)
+	reg := ickcore.CurrentRegistry(out)
+	for _, p := range ctx.Portals() {
+		if _, err = ickcore.RenderString(out, p.HTML); err != nil {
+			return err
+		}
+	}
+	for _, s := range ctx.Scripts(reg, ickcore.JSPLACE_BODY) {
+		if err = s.Render(out); err != nil {
+			return err
+		}
+	}
+	if err = ickcore.RenderChild(out, pg, pg.WasmScript()); err != nil {
+		return err
+	}
+	for _, s := range ctx.Scripts(reg, ickcore.JSPLACE_AFTERWASM) {
+		if err = s.Render(out); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// hasHeadItem returns true if the page has a head item with the tagname and the attribute value.
+func (pg *Page) hasHeadItem(tagname string, aname string, avalue string) bool {
+	for _, hi := range pg.HeadItems {
+		if hitn, _ := hi.Tag().TagName(); hitn == tagname {
+			v, found := hi.Tag().Attribute(aname)
+			if found && v == avalue {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -2,8 +2,12 @@ package ick
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"testing"
 
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,4 +19,150 @@ func TestCoreHtmlFile(t *testing.T) {
 	err := dft.RenderContent(out)
 	require.NoError(t, err)
 	assert.Equal(t, `<!doctype html><html lang="en"><head></head><body></body></html>`, out.String())
+
+	// write errors are returned
+	err = dft.RenderContent(failwriter{})
+	assert.ErrorIs(t, err, errfail)
+}
+
+var errfail = errors.New("write failed")
+
+type failwriter struct{}
+
+func (failwriter) Write(p []byte) (int, error) { return 0, errfail }
+
+func TestPageParseURL(t *testing.T) {
+
+	tst := []struct {
+		raw  string
+		url  string
+		wasm string
+	}{
+		{"index.html", "index.html", "index.wasm"},
+		{"index", "index.html", "index.wasm"},
+		{"fr/docs.html", "fr/docs.html", "fr/docs.wasm"},
+		{"", "", ""},
+	}
+	for _, tc := range tst {
+		pg := NewPage(nil, "en", tc.raw)
+		require.NotNil(t, pg, tc.raw)
+		assert.Equal(t, tc.url, pg.RelURL().String(), tc.raw)
+		if tc.wasm != "" {
+			assert.Equal(t, tc.wasm, pg.wasm.Path, tc.raw)
+		}
+	}
+	assert.ErrorIs(t, new(Page).ParseURL("index.txt"), ErrBadHtmlFileExtention)
+}
+
+func TestPageEscaping(t *testing.T) {
+
+	pg := NewPage(nil, "en", "")
+	pg.Title = `Tom & "Jerry" <script>`
+	pg.Description = `say "hello"`
+	out := new(bytes.Buffer)
+	err := pg.RenderContent(out)
+	require.NoError(t, err)
+	assert.Equal(t, `<!doctype html><html lang="en"><head><title>Tom &amp; &#34;Jerry&#34; &lt;script&gt;</title><meta name="description" content="say &#34;hello&#34;"></head><body></body></html>`, out.String())
+}
+
+func TestPageData(t *testing.T) {
+
+	pg := NewPage(nil, "en", "")
+	pg.Data = struct {
+		Name  string
+		Items []string
+	}{Name: "<Bob>", Items: []string{"a", "b"}}
+	pg.Body().Append(ickcore.ToHTML(`<p>{{ .Name }}</p>{{ range .Items }}<ick-button Title='{{ . }}'/>{{ end }}`))
+	out := new(bytes.Buffer)
+	err := pg.RenderContent(out)
+	require.NoError(t, err)
+	assert.Equal(t, `<!doctype html><html lang="en"><head></head><body><p>&lt;Bob&gt;</p><button name="ickbutton" class="button">a</button><button name="ickbutton" class="button">b</button></body></html>`, out.String())
+}
+
+type testcsscmp struct {
+	ickcore.BareSnippet
+}
+
+func (*testcsscmp) RenderContent(out io.Writer) error { return nil }
+
+func TestPageRequiredCSS(t *testing.T) {
+
+	ickcore.RequireComposerCSSStyle("testcsscmp", ".testcss{}")
+	ickcore.RequireComposerCSSStyle("testcssnotrendered", ".notrendered{}")
+
+	// the style is not added to a page not rendering the composer
+	pg := NewPage(nil, "en", "")
+	out := new(bytes.Buffer)
+	err := pg.RenderContent(out)
+	require.NoError(t, err)
+	assert.Equal(t, `<!doctype html><html lang="en"><head></head><body></body></html>`, out.String())
+
+	// the style is added once to a page rendering the composer
+	pg.Body().Append(&testcsscmp{}, &testcsscmp{})
+	out.Reset()
+	err = pg.RenderContent(out)
+	require.NoError(t, err)
+	assert.Equal(t, `<!doctype html><html lang="en"><head><style>/* testcsscmp */
+.testcss{}</style></head><body></body></html>`, out.String())
+
+	// requirements of the page rendering
+	pg.RMeta().Context.RequireCSSFile("/should/be/reset.css")
+	out.Reset()
+	err = pg.RenderContent(out)
+	require.NoError(t, err)
+	assert.NotContains(t, out.String(), "reset.css")
+
+	// a context set by the user is kept
+	ctx := ickcore.NewRenderContext()
+	ctx.RequireCSSFile("/user.css")
+	pg.RMeta().Context = ctx
+	for i := 0; i < 2; i++ {
+		out.Reset()
+		err = pg.RenderContent(out)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), `<link rel="stylesheet" href="/user.css">`)
+		assert.Same(t, ctx, pg.RMeta().Context)
+	}
+}
+
+type testjscmp struct {
+	ickcore.BareSnippet
+}
+
+func (cmp *testjscmp) RenderContent(out io.Writer) error {
+	ickcore.CurrentContext(out).RequireJSFile("/assets/head.js", ickcore.JSOptions{})
+	ickcore.CurrentContext(out).RequireJSFile("/assets/body.js", ickcore.JSOptions{Placement: ickcore.JSPLACE_BODY})
+	return nil
+}
+
+func TestPageRequiredScripts(t *testing.T) {
+
+	pg := NewPage(nil, "en", "index")
+	pg.AddHeadItem("script", `src="/assets/head.js"`)
+	pg.Body().Append(&testjscmp{})
+	ickcore.RequireJSScript("testjscmp", `start()`, ickcore.JSOptions{Placement: ickcore.JSPLACE_AFTERWASM})
+	out := new(bytes.Buffer)
+	err := pg.RenderContent(out)
+	require.NoError(t, err)
+	html := out.String()
+	assert.Contains(t, html, `<head><script src="/assets/head.js"></script></head>`)
+	assert.Regexp(t, `<body><script src="/assets/body.js"></script><script src="/assets/wasm_exec.js"></script>(?s:.*)</script><script>start\(\)</script></body></html>$`, html)
+}
+
+func TestPagePortal(t *testing.T) {
+
+	pg := NewPage(nil, "en", "index")
+	pg.Body().Append(ickcore.NewPortal("", ickcore.ToHTML("<div>modal</div>")), ickcore.ToHTML("<p>content</p>"))
+	out := new(bytes.Buffer)
+	err := pg.RenderContent(out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `<body><p>content</p><!--ick:ickelem0.portal0--><div>modal</div><!--/ick:ickelem0.portal0--><script`)
+
+	// the body keeps its attributes and the virtual id of its ICKElem
+	pg.Body().Tag().AddClass("dark")
+	out.Reset()
+	err = pg.RenderContent(out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `<body class="dark"><p>content</p><!--ick:ickelem0.portal0-->`)
+	assert.Equal(t, "ickelem0", pg.Body().RMeta().VirtualId)
 }
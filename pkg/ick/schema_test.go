@@ -0,0 +1,24 @@
+package ick
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSchemaDocs checks every composer of the package is documented, and only its properties are.
+func TestSchemaDocs(t *testing.T) {
+	for name, entry := range ickcore.DefaultRegistry.Map() {
+		schema := entry.Schema()
+		if !strings.HasPrefix(schema.Type, "*ick.") {
+			continue
+		}
+		assert.NotEmpty(t, schema.Doc, name)
+		_, fields := entry.Component().(ickcore.SchemaDocumenter).SchemaDoc()
+		for field := range fields {
+			assert.NotNil(t, schema.Property(field), "%s: %s", name, field)
+		}
+	}
+}
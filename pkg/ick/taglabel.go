@@ -8,6 +8,7 @@ import (
 
 func init() {
 	ickcore.RegisterComposer("ick-tag", &ICKTagLabel{})
+	ickcore.RegisterEnum(TAGLBLSZ_STD, TAGLBLSZ_OPTIONS)
 }
 
 type TAGLABEL_SIZE string
@@ -38,6 +39,20 @@ type ICKTagLabel struct {
 // Ensuring ICKTag implements the right interface
 var _ ickcore.ContentComposer = (*ICKTagLabel)(nil)
 var _ ickcore.TagBuilder = (*ICKTagLabel)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKTagLabel)(nil)
+
+// SchemaDoc documents the ick-tag of ICKTagLabel, see ickcore.SchemaDocumenter.
+func (*ICKTagLabel) SchemaDoc() (string, map[string]string) {
+	return "ICKTagLabel renders a bulma tag with an optional header.", map[string]string{
+		"Header":        "optional text to render on the left side of the tag",
+		"Text":          "the tag text",
+		"CanDelete":     "displays the delete button",
+		"IsRounded":     "rounded tag style",
+		"HeaderColor":   "color of the header",
+		"TextColor":     "color of the text",
+		"TAGLABEL_SIZE": "tag size",
+	}
+}
 
 func TagLabel(text string, c COLOR, attrs ...string) *ICKTagLabel {
 	n := new(ICKTagLabel)
@@ -103,12 +118,12 @@ func (t *ICKTagLabel) BuildTag() ickcore.Tag {
 func (t *ICKTagLabel) RenderContent(out io.Writer) error {
 	if t.jointed {
 		if t.Header != "" {
-			cmp := Elem("span", `class="tag"`, ickcore.ToHTML(t.Header))
+			cmp := Elem("span", `class="tag"`, ickcore.ToText(t.Header))
 			cmp.Tag().PickClass(COLOR_OPTIONS, string(t.HeaderColor))
 			ickcore.RenderChild(out, t, cmp)
 		}
 		if t.Text != "" {
-			cmp := Elem("span", `class="tag"`, ickcore.ToHTML(t.Text))
+			cmp := Elem("span", `class="tag"`, ickcore.ToText(t.Text))
 			cmp.Tag().PickClass(COLOR_OPTIONS, string(t.TextColor))
 			ickcore.RenderChild(out, t, cmp)
 		}
@@ -120,9 +135,9 @@ func (t *ICKTagLabel) RenderContent(out io.Writer) error {
 
 	} else {
 		if t.Text != "" {
-			ickcore.RenderString(out, t.Text)
+			ickcore.RenderText(out, t.Text)
 		} else if t.Header != "" {
-			ickcore.RenderString(out, t.Header)
+			ickcore.RenderText(out, t.Header)
 		}
 		if t.CanDelete {
 			btndel := Delete(t.Tag().SubId("btndel"), t.Tag().Id())
@@ -71,6 +71,6 @@ func (t *ICKTitle) BuildTag() ickcore.Tag {
 }
 
 func (t *ICKTitle) RenderContent(out io.Writer) error {
-	_, err := ickcore.RenderString(out, t.Title)
+	_, err := ickcore.RenderText(out, t.Title)
 	return err
 }
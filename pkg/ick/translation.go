@@ -0,0 +1,128 @@
+package ick
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/i18n"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+func init() {
+	ickcore.RegisterComposer("ick-t", &ICKTranslation{})
+}
+
+// ICKTranslation is a composer rendering a message of an i18n catalog, in the language of the rendering.
+// The language is the one of the page being rendered, unless Lang is set.
+// It's registered with the ick-tag `ick-t`, every attribute other than key and lang is a parameter of the message:
+//
+//	<ick-t key="cart.items" count="3"/>
+//	<ick-t key="hello" name="Bob"/>
+//
+// Messages are trusted HTML and may embed ick-tags, parameter values are escaped.
+// A missing message is rendered with its key and raises a warning diagnostic.
+type ICKTranslation struct {
+	meta ickcore.RMetaData
+
+	Key    string      // the key of the message in the catalog
+	Lang   string      `ick:"lang"` // optional language of the message, overrides the one of the rendering
+	Params i18n.Params `ick:"-"`    // optional parameters of the message, "count" selects the plural form
+
+	Bundle *i18n.Bundle `ick:"-"` // the bundle of the catalogs, i18n.DefaultBundle if nil
+}
+
+// Ensuring ICKTranslation implements the right interface
+var _ ickcore.ContentComposer = (*ICKTranslation)(nil)
+var _ ickcore.AttributeUnfolder = (*ICKTranslation)(nil)
+var _ ickcore.AttributeDescriber = (*ICKTranslation)(nil)
+var _ ickcore.SchemaDocumenter = (*ICKTranslation)(nil)
+
+// SchemaDoc documents the ick-tag of ICKTranslation, see ickcore.SchemaDocumenter.
+func (*ICKTranslation) SchemaDoc() (string, map[string]string) {
+	return "ICKTranslation renders a message of an i18n catalog in the language of the rendering. Messages are trusted HTML, parameter values are escaped.", map[string]string{
+		"Key":  "the key of the message in the catalog",
+		"Lang": "optional language of the message, overrides the one of the rendering",
+	}
+}
+
+// T returns a new ICKTranslation rendering the message key with its params.
+func T(key string, params i18n.Params) *ICKTranslation {
+	t := new(ICKTranslation)
+	t.Key = key
+	t.Params = params
+	return t
+}
+
+// SetLang forces the language of the message
+func (t *ICKTranslation) SetLang(lang string) *ICKTranslation {
+	t.Lang = lang
+	return t
+}
+
+// RMeta returns a reference to the rendering metadata
+func (t *ICKTranslation) RMeta() *ickcore.RMetaData {
+	return &t.meta
+}
+
+// NeedRendering returns true if there's a key
+func (t *ICKTranslation) NeedRendering() bool {
+	return t.Key != ""
+}
+
+// UnfoldAttribute maps the attributes of the ick-tag other than key and lang to the parameters of the message.
+func (t *ICKTranslation) UnfoldAttribute(name string, value string) error {
+	if strings.EqualFold(name, "key") || strings.EqualFold(name, "lang") {
+		return ickcore.ErrAttributeNotUnfolded
+	}
+	if t.Params == nil {
+		t.Params = make(i18n.Params)
+	}
+	t.Params[strings.ToLower(name)] = value
+	return nil
+}
+
+// DescribeAttributes describes the parameters unfolded by UnfoldAttribute.
+func (t *ICKTranslation) DescribeAttributes() []ickcore.PropertySchema {
+	return []ickcore.PropertySchema{
+		{Name: "*", Field: "Params", Type: "i18n.Params", Kind: "string", Doc: `any other attribute is a parameter of the message, "count" selects the plural form`},
+	}
+}
+
+// CurrentLang returns the language of the message: Lang if set, otherwise the language of the rendering into out,
+// otherwise the fallback language of the bundle.
+func (t *ICKTranslation) CurrentLang(out io.Writer) string {
+	if t.Lang != "" {
+		return t.Lang
+	}
+	if lang := ickcore.CurrentContext(out).Lang(); lang != "" {
+		return lang
+	}
+	return t.bundle().Fallback()
+}
+
+// RenderContent renders the translated message.
+func (t *ICKTranslation) RenderContent(out io.Writer) error {
+	lang := t.CurrentLang(out)
+	if !t.bundle().Has(lang, t.Key) {
+		if report := ickcore.CurrentReport(out); report != nil {
+			report.Add(ickcore.Diagnostic{Level: ickcore.DIAG_WARNING, Path: t.meta.VirtualId, IckTagName: "ick-t", Err: fmt.Errorf("%q message missing for %q", t.Key, lang)})
+		}
+	}
+
+	params := make(i18n.Params, len(t.Params))
+	for k, v := range t.Params {
+		if s, ok := v.(string); ok {
+			v = ickcore.EscapeString(ickcore.ESCCTX_CODE, s)
+		}
+		params[k] = v
+	}
+	return ickcore.RenderChild(out, t, ickcore.ToHTML(t.bundle().Translate(lang, t.Key, params)))
+}
+
+func (t *ICKTranslation) bundle() *i18n.Bundle {
+	if t.Bundle != nil {
+		return t.Bundle
+	}
+	return i18n.DefaultBundle
+}
@@ -0,0 +1,99 @@
+package ick
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/icecake-framework/icecake/pkg/i18n"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslation(t *testing.T) {
+	i18n.DefaultBundle.Add("en", "test.hello", i18n.Message{Other: "Hello <b>{name}</b>"})
+	i18n.DefaultBundle.Add("fr", "test.hello", i18n.Message{Other: "Bonjour <b>{name}</b>"})
+	i18n.DefaultBundle.Add("en", "test.items", i18n.Message{One: "{count} item", Other: "{count} items"})
+
+	// go api, fallback language without context
+	out := new(bytes.Buffer)
+	err := ickcore.RenderChild(out, nil, T("test.hello", i18n.Params{"name": "<Bob>"}))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello <b>&lt;Bob&gt;</b>", out.String())
+
+	out.Reset()
+	err = ickcore.RenderChild(out, nil, T("test.hello", nil).SetLang("fr"))
+	require.NoError(t, err)
+	assert.Equal(t, "Bonjour <b>{name}</b>", out.String())
+
+	// ick-tag with the language of the context
+	out.Reset()
+	report := new(ickcore.RenderReport)
+	root := ickcore.ToHTML(`<ick-t key="test.hello" name="Eve"/> <ick-t key="test.items" count="1"/> <ick-t key="test.missing"/>`)
+	root.RMeta().Context = ickcore.NewRenderContext()
+	root.RMeta().Context.SetLang("fr-CA")
+	root.RMeta().Report = report
+	err = ickcore.RenderChild(out, nil, root)
+	require.NoError(t, err)
+	assert.Equal(t, "Bonjour <b>Eve</b> 1 item test.missing", out.String())
+	require.Equal(t, 1, report.Len())
+	assert.Contains(t, report.Diagnostics()[0].String(), `"test.missing" message missing for "fr-CA"`)
+
+	// parameters are never evaluated as template expressions
+	out.Reset()
+	data := map[string]any{"Secret": "TOPSECRET"}
+	err = ickcore.RenderData(out, nil, data, T("test.hello", i18n.Params{"name": "{{.Secret}}"}))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello <b>&#123;&#123;.Secret}}</b>", out.String())
+
+	out.Reset()
+	err = ickcore.RenderData(out, nil, data, ickcore.ToHTML(`<ick-t key="test.hello" name="&#123;&#123;.Secret}}"/>`))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello <b>&#123;&#123;.Secret}}</b>", out.String())
+
+	// schema
+	schema := ickcore.Schemas()["ick-t"]
+	assert.Nil(t, schema.Property("params"))
+	require.NotNil(t, schema.Property("*"))
+	assert.Equal(t, "string", schema.Property("*").Kind)
+}
+
+func TestLocalizedPages(t *testing.T) {
+	i18n.DefaultBundle.Add("en", "test.title", i18n.Message{Other: "Welcome"})
+	i18n.DefaultBundle.Add("fr", "test.title", i18n.Message{Other: "Bienvenue"})
+
+	web := NewWebSite(t.TempDir())
+	web.Langs = []string{"en", "fr"}
+	pages := web.AddLocalizedPages("index.html", func(pg *Page) {
+		pg.Title = pg.T("test.title", nil)
+		pg.Body().Append(ickcore.ToHTML(`<h1><ick-t key="test.title"/></h1>`))
+	})
+	require.Len(t, pages, 2)
+	assert.Equal(t, "fr/index.html", pages[1].RelURL().String())
+	assert.Equal(t, "fr/index.html", web.LangPath("fr", "index.html"))
+	assert.Equal(t, "index.html", web.LangPath("en", "index.html"))
+
+	n, err := web.WriteFiles()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	html, err := os.ReadFile(filepath.Join(web.OutPath, "fr", "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `<html lang="fr"><head><title>Bienvenue</title>`+
+		`<link href="/index.html" hreflang="en" rel="alternate"><link href="/fr/index.html" hreflang="fr" rel="alternate"></head>`+
+		`<body><h1>Bienvenue</h1>`)
+
+	html, err = os.ReadFile(filepath.Join(web.OutPath, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `<html lang="en"><head><title>Welcome</title>`)
+
+	// alternate links are escaped once
+	web.AddLocalizedPages("a&b.html", nil)
+	_, err = web.WriteFiles()
+	require.NoError(t, err)
+	html, err = os.ReadFile(filepath.Join(web.OutPath, "a&b.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `<link href="/a&amp;b.html" hreflang="en" rel="alternate"><link href="/fr/a&amp;b.html" hreflang="fr" rel="alternate">`)
+}
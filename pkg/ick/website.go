@@ -1,11 +1,16 @@
 package ick
 
 import (
+	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/icecake-framework/icecake/internal/helper"
+	"github.com/icecake-framework/icecake/pkg/i18n"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
 	"github.com/lolorenzo777/verbose"
 )
 
@@ -14,6 +19,14 @@ type WebSite struct {
 
 	OutPath string   // output path where generated websites files will be saved
 	WebURL  *url.URL // website URL
+	Strict  bool     // WriteFiles fails if the rendering of any page raises errors or warnings, infos are only logged
+
+	// Tracer, if set, records the rendering of every page written by WriteFiles
+	Tracer *ickcore.RenderTracer
+
+	// Langs are the languages of the pages added with AddLocalizedPages.
+	// The first one is the default language, its pages are at the root of the website.
+	Langs []string
 }
 
 func NewWebSite(outpath string) *WebSite {
@@ -81,26 +94,106 @@ func (w *WebSite) AddPage(lang string, rawUrl string) *Page {
 	return pg
 }
 
+// AddLocalizedPages adds one page per language of the website, all defined by the same define function called with every page.
+// The page of the default language is at rawUrl, the page of any other language is at rawUrl within a directory named after the language, see LangPath.
+// Every page gets alternate links to the pages of the other languages. The language of the page is the one of the rendering,
+// so ick-t tags and ICKTranslation composers within the page render the messages of this language.
+//
+// If the website has no Langs, a single page is added with the fallback language of the i18n.DefaultBundle.
+// Returns the pages added, in the order of the languages, nil if unable to parse rawUrl.
+func (w *WebSite) AddLocalizedPages(rawUrl string, define func(pg *Page)) []*Page {
+	langs := w.Langs
+	if len(langs) == 0 {
+		langs = []string{i18n.DefaultBundle.Fallback()}
+	}
+	pages := make([]*Page, 0, len(langs))
+	for _, lang := range langs {
+		pg := w.AddPage(lang, w.LangPath(lang, rawUrl))
+		if pg == nil {
+			return nil
+		}
+		pages = append(pages, pg)
+	}
+	for _, pg := range pages {
+		if len(pages) > 1 {
+			for _, alt := range pages {
+				href := alt.AbsURL()
+				if !href.IsAbs() && !strings.HasPrefix(href.Path, "/") {
+					href.Path = "/" + href.Path
+				}
+				item := NewHeadItem("link")
+				item.Tag().SetAttribute("rel", "alternate").SetAttribute("hreflang", alt.Lang).SetURL("href", href)
+				pg.HeadItems = append(pg.HeadItems, *item)
+			}
+		}
+		if define != nil {
+			define(pg)
+		}
+	}
+	return pages
+}
+
+// LangPath returns the path of rawUrl for the language lang: rawUrl within a directory named after the language,
+// unless lang is the default language of the website or the website has no Langs.
+func (w WebSite) LangPath(lang string, rawUrl string) string {
+	if len(w.Langs) == 0 || lang == w.Langs[0] || lang == "" {
+		return rawUrl
+	}
+	return path.Join(i18n.NormalizeLang(lang), rawUrl)
+}
+
 func (w *WebSite) Page(rawUrl string) *Page {
 	return w.pages[rawUrl]
 }
 
+// WriteFiles renders and writes every page of the website.
+// Each page is rendered with its own clone of the ickcore.DefaultRegistry, unless it has its own registry,
+// so generated ids only depend on the content of the page.
+// The rendering diagnostics of each page are collected in its own report, available with page.RMeta().Report,
+// and printed out in verbose mode.
+// If the website has a Tracer, the rendering of every page is recorded in it.
+//
+// In strict mode, WriteFiles writes all the pages and returns an ErrRenderDiagnostics error if any page has diagnostics.
+//
 // returns the number of pages written and errors.
 func (w WebSite) WriteFiles() (n int, err error) {
 	if w.pages == nil {
 		return 0, nil
 	}
 
-	var warn error
 	n = 0
+	ndiag := 0
 	for _, p := range w.pages {
+		p.RMeta().Report = new(ickcore.RenderReport)
+		if w.Tracer != nil {
+			p.RMeta().Trace = w.Tracer
+		}
+		ownregistry := p.RMeta().Registry == nil
+		if ownregistry {
+			p.RMeta().Registry = ickcore.DefaultRegistry.Clone()
+		}
 
 		// write file with its rendered content
 		err = p.WriteFile(w.OutPath)
+		if ownregistry {
+			p.RMeta().Registry = nil
+		}
 		if err != nil {
 			return n, err
 		}
 		n++
+
+		for _, d := range p.RMeta().Report.Diagnostics() {
+			level := verbose.WARNING
+			if d.Level == ickcore.DIAG_INFO {
+				level = verbose.INFO
+			}
+			verbose.Printf(level, "%s: %s\n", p.RelURL().String(), d.String())
+		}
+		ndiag += len(p.RMeta().Report.Errors()) + len(p.RMeta().Report.Warnings())
+	}
+	if w.Strict && ndiag > 0 {
+		return n, fmt.Errorf("%w: %v diagnostics", ErrRenderDiagnostics, ndiag)
 	}
-	return n, warn
+	return n, nil
 }
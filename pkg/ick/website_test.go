@@ -0,0 +1,62 @@
+package ick
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFilesStrict(t *testing.T) {
+
+	web := NewWebSite(t.TempDir())
+	pg := web.AddPage("en", "index.html")
+	require.NotNil(t, pg)
+	pg.Body().Append(ickcore.ToHTML(`<p>ok</p>`))
+
+	n, err := web.WriteFiles()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 0, pg.RMeta().Report.Len())
+
+	pg.Body().Append(ickcore.ToHTML("<p>\n<ick-unknown/></p>"))
+	n, err = web.WriteFiles()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Equal(t, 1, pg.RMeta().Report.Len())
+	d := pg.RMeta().Report.Diagnostics()[0]
+	assert.Equal(t, "ick-unknown", d.IckTagName)
+	assert.Equal(t, 2, d.Line)
+
+	web.Strict = true
+	n, err = web.WriteFiles()
+	assert.True(t, errors.Is(err, ErrRenderDiagnostics))
+	assert.Equal(t, 1, n)
+
+	// attributes passed through to the tag are not an issue
+	pg.Body().Body.ClearContent()
+	pg.Body().Append(ickcore.ToHTML(`<ick-button hx-boost=true/>`))
+	_, err = web.WriteFiles()
+	require.NoError(t, err)
+	assert.Len(t, pg.RMeta().Report.Infos(), 1)
+}
+
+func TestWriteFilesTracer(t *testing.T) {
+
+	web := NewWebSite(t.TempDir())
+	web.Tracer = new(ickcore.RenderTracer)
+	web.AddPage("en", "index.html").Body().Append(ickcore.ToHTML(`<p>ok</p>`))
+
+	_, err := web.WriteFiles()
+	require.NoError(t, err)
+
+	roots := web.Tracer.Roots()
+	require.Len(t, roots, 1)
+	assert.Equal(t, "index.html", roots[0].Label)
+	assert.Equal(t, "*ick.Page", roots[0].Type)
+	assert.Greater(t, roots[0].Bytes, 0)
+	require.NotEmpty(t, roots[0].Children)
+	assert.Equal(t, "*ick.ICKElem", roots[0].Children[0].Type)
+}
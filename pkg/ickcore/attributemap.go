@@ -3,6 +3,7 @@ package ickcore
 import (
 	"errors"
 	"fmt"
+	"html"
 	"net/url"
 	"sort"
 	"strconv"
@@ -34,19 +35,18 @@ func (amap AttributeMap) Clone() AttributeMap {
 
 // AttributeString returns the formated list of attributes, ready to use to generate the tag element.
 // always sorted the same way : 1>id 2>name 3>class 4>others sorted alpha by name
+//
+// If AutoEscape is on, values are escaped according to the attribute context, see AttributeContext.
 func (amap AttributeMap) AttributeString() string {
 	if len(amap) == 0 {
 		return ""
 	}
 
-	attrsortindex := map[string]int{"id": 0, "name": 1, "class": 2}
-
-	strhtml := ""
 	sorted := make([]string, 0, len(amap))
 	for k := range amap {
 		sorted = append(sorted, k)
 	}
-	sort.SliceStable(sorted, func(i, j int) bool {
+	sort.Slice(sorted, func(i, j int) bool {
 		ii, foundi := attrsortindex[sorted[i]]
 		if !foundi {
 			ii = 9
@@ -61,21 +61,30 @@ func (amap AttributeMap) AttributeString() string {
 		return ii < jj
 	})
 
-	for _, k := range sorted {
+	var strhtml strings.Builder
+	for i, k := range sorted {
 		v := amap[k]
-		strhtml += k
+		if AutoEscape {
+			v = escapeAttributeValue(k, v)
+		}
+		if i > 0 {
+			strhtml.WriteByte(' ')
+		}
+		strhtml.WriteString(k)
 		sv, err := StringifyAttributeValue(v)
 		if err != nil {
-			strhtml += "='" + err.Error() + "'"
+			strhtml.WriteString("='" + err.Error() + "'")
 		} else if len(sv) > 0 {
-			strhtml += "=" + sv
+			strhtml.WriteByte('=')
+			strhtml.WriteString(sv)
 		}
-		strhtml += " "
 	}
-	strhtml = strings.TrimRight(strhtml, " ")
-	return strhtml
+	return strhtml.String()
 }
 
+// attrsortindex is the rendering order of the first attributes, others are sorted by name
+var attrsortindex = map[string]int{"id": 0, "name": 1, "class": 2}
+
 // Attribute returns the value of the attribute identified by its name.
 // Returns false if the attribute does not exist.
 //
@@ -598,6 +607,7 @@ func ParseAttributes(alist string) AttributeMap {
 //
 // An attribute can be either a single name, usually a boolean attribute, either a name and a value at the right of an "=" symbol.
 // The value can be delimited by quotes ( " or ' ) and in that case may contains whitespaces.
+// Character references in the value are unescaped, like `&amp;`, the value is escaped when rendered.
 // The string is processed until the end or an error occurs when invalid char is met.
 //
 // Use ParseAttributes to chain calls and ignore errors.
@@ -641,7 +651,7 @@ func TryParseAttributes(alist string) (amap AttributeMap, err error) {
 			istart = 0
 		}
 		value, unparsed, _ = strings.Cut(unparsed[istart:], string(delim))
-		_, err = amap.setAttribute(name, value, true)
+		_, err = amap.setAttribute(name, html.UnescapeString(value), true)
 		if err != nil {
 			verbose.Error("ParseAttribute", err)
 			return make(AttributeMap), err
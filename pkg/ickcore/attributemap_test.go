@@ -214,6 +214,11 @@ func TestTryParseAttributes(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, AttributeMap{"attrB": `  b'  `}, amap)
 
+	// character references are unescaped, and escaped once when rendered
+	amap, err = TryParseAttributes(`href="/x?a=1&amp;b=2" title="Tom &amp; Jerry"`)
+	assert.NoError(t, err)
+	assert.Equal(t, AttributeMap{"href": "/x?a=1&b=2", "title": "Tom & Jerry"}, amap)
+	assert.Equal(t, `href="/x?a=1&amp;b=2" title="Tom &amp; Jerry"`, amap.AttributeString())
 }
 
 func TestString(t *testing.T) {
@@ -224,7 +229,11 @@ func TestString(t *testing.T) {
 	assert.Equal(t, "a=1", amap.SetAttribute("A", "1").AttributeString())
 	assert.Equal(t, `a=1 b="b"`, amap.SetAttribute("B", "b").AttributeString())
 	assert.Equal(t, `a=1 b="b" c="a'b'c"`, amap.SetAttribute("C", `a'b'c`).AttributeString())
-	assert.Equal(t, `a=1 b="b" c="a'b'c" d='a"b"c'`, amap.SetAttribute("D", `a"b"c`).AttributeString())
+	assert.Equal(t, `a=1 b="b" c="a'b'c" d="a&#34;b&#34;c"`, amap.SetAttribute("D", `a"b"c`).AttributeString())
+
+	AutoEscape = false
+	assert.Equal(t, `a=1 b="b" c="a'b'c" d='a"b"c'`, amap.AttributeString())
+	AutoEscape = true
 
 	amap.ResetAttributes()
 	assert.Equal(t, `id="ID1" class="c1 c2"`, amap.SetId("ID1").AddClass("c1 c2").AttributeString())
@@ -61,3 +61,52 @@ type TagProvider interface {
 
 	Tag() *Tag
 }
+
+// AttributeUnfolder can be implemented by a composer willing to take over the mapping of the attributes of its ick-tag.
+// UnfoldAttribute is called for every attribute found in the ick-tag, before the default mapping.
+// Return ErrAttributeNotUnfolded to let the default mapping process the attribute.
+// Any other error stops the unfolding and the ick-tag is rendered as an HTML comment.
+type AttributeUnfolder interface {
+	UnfoldAttribute(name string, value string) error
+}
+
+// ComposerFactory can be implemented by a registered composer to instantiate the composer of its ick-tags without reflection.
+// The factory is ignored if it does not return the type of the registered composer, like a factory promoted from an embedded composer.
+// It's usually generated with `icecake gen`.
+type ComposerFactory interface {
+	NewComposer() Composer
+}
+
+// AttributeApplier can be implemented by a composer to set its properties from the attributes of its ick-tag without reflection.
+// ApplyAttributes is called once with the attributes matching a property, by attribute's name, and once with the default values of the missing ones.
+// Return an error wrapping ErrAttributeNotUnfolded for an attribute that is not a property.
+// ApplyAttributes is only used along with the ComposerFactory of the registered composer, both are generated with `icecake gen`.
+// Otherwise properties are set with reflection, see ickproperty.
+type AttributeApplier interface {
+	ApplyAttributes(attrs AttributeMap) error
+}
+
+// BeforeRenderer can be implemented by a composer willing to prepare its rendering, like fetching data.
+// BeforeRender is called by the rendering process before building the tag and rendering the content.
+// Return an error to stop the rendering of the composer.
+type BeforeRenderer interface {
+	BeforeRender() error
+}
+
+// AfterRenderer can be implemented by a composer willing to be notified once it has been successfully rendered, including its children.
+type AfterRenderer interface {
+	AfterRender()
+}
+
+// NodeBuilder can be implemented by an output stream building the output itself, like DOM nodes, rather than writing an HTML string.
+// The rendering process calls OpenTag and CloseTag instead of writing the opening and the closing tag of the TagBuilders.
+// The content rendered by the composers is still written to the stream.
+type NodeBuilder interface {
+	io.Writer
+
+	// OpenTag is called with the tag built by cmp, before rendering its content. Not called if the tag is empty.
+	OpenTag(cmp Composer, tag Tag) error
+
+	// CloseTag is called once the content of cmp has been rendered. Not called if the tag is empty or self-closing.
+	CloseTag(cmp Composer, tag Tag) error
+}
@@ -1,6 +1,7 @@
 package ickcore
 
 import (
+	"fmt"
 	"io"
 	"reflect"
 
@@ -9,6 +10,9 @@ import (
 
 // ContentStack is a stack of ContentComposer than can easily be embedded into any custom snippet.
 // Call Push to feed the stack and call RenderStack into the RenderContent fonction of the custom snippet.
+//
+// Composers with a Key can be looked up, inserted, removed, moved or replaced by key. Keys must be unique within the stack.
+// In the browser dom.UI applies these operations to the DOM without rendering the whole stack again.
 type ContentStack struct {
 	Stack []ContentComposer
 }
@@ -64,3 +68,130 @@ func (c *ContentStack) RenderStack(out io.Writer, parent RMetaProvider) (err err
 	}
 	return nil
 }
+
+// Keyed sets the key of cmp and returns it, to push or to insert keyed composers in a stack.
+// See RMetaData.Key.
+func Keyed(key string, cmp ContentComposer) ContentComposer {
+	if cmp != nil {
+		cmp.RMeta().Key = key
+	}
+	return cmp
+}
+
+// Len returns the number of composers in the stack.
+func (c *ContentStack) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.Stack)
+}
+
+// At returns the composer at index i, nil if i is out of range.
+func (c *ContentStack) At(i int) ContentComposer {
+	if c == nil || i < 0 || i >= len(c.Stack) {
+		return nil
+	}
+	return c.Stack[i]
+}
+
+// Each calls fn for every composer in the stack, in order, until fn returns false.
+func (c *ContentStack) Each(fn func(i int, cmp ContentComposer) bool) {
+	if c == nil {
+		return
+	}
+	for i, cmp := range c.Stack {
+		if !fn(i, cmp) {
+			return
+		}
+	}
+}
+
+// Keys returns the keys of the composers in the stack, in order. Empty strings for composers without key.
+func (c *ContentStack) Keys() []string {
+	keys := make([]string, 0, c.Len())
+	c.Each(func(_ int, cmp ContentComposer) bool {
+		keys = append(keys, cmp.RMeta().Key)
+		return true
+	})
+	return keys
+}
+
+// IndexOf returns the index of the composer with the key, -1 if not found or if key is empty.
+func (c *ContentStack) IndexOf(key string) int {
+	if key == "" {
+		return -1
+	}
+	idx := -1
+	c.Each(func(i int, cmp ContentComposer) bool {
+		if cmp.RMeta().Key == key {
+			idx = i
+			return false
+		}
+		return true
+	})
+	return idx
+}
+
+// Lookup returns the composer with the key, nil if not found.
+func (c *ContentStack) Lookup(key string) ContentComposer {
+	return c.At(c.IndexOf(key))
+}
+
+// Insert inserts cmp at index at, shifting the next ones. cmp is appended if at is out of range.
+// Returns the index where cmp has been inserted.
+// Returns an error if cmp is nil, or if its key is already used in the stack.
+func (c *ContentStack) Insert(at int, cmp ContentComposer) (int, error) {
+	if cmp == nil || reflect.TypeOf(cmp).Kind() != reflect.Ptr || reflect.ValueOf(cmp).IsNil() {
+		return -1, ErrEmptyComposer
+	}
+	if key := cmp.RMeta().Key; c.IndexOf(key) >= 0 {
+		return -1, fmt.Errorf("%w: %q", ErrDuplicateKey, key)
+	}
+	if at < 0 || at > len(c.Stack) {
+		at = len(c.Stack)
+	}
+	c.Stack = append(c.Stack, nil)
+	copy(c.Stack[at+1:], c.Stack[at:])
+	c.Stack[at] = cmp
+	return at, nil
+}
+
+// Remove removes the composer with the key from the stack and returns it.
+// Returns nil if the key is not found.
+func (c *ContentStack) Remove(key string) ContentComposer {
+	idx := c.IndexOf(key)
+	if idx < 0 {
+		return nil
+	}
+	cmp := c.Stack[idx]
+	c.Stack = append(c.Stack[:idx], c.Stack[idx+1:]...)
+	return cmp
+}
+
+// Move moves the composer with the key to index to, the composer is moved at the end if to is out of range.
+// Returns the new index of the composer or an error if the key is not found.
+func (c *ContentStack) Move(key string, to int) (int, error) {
+	cmp := c.Remove(key)
+	if cmp == nil {
+		return -1, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+	return c.Insert(to, cmp)
+}
+
+// Replace replaces the composer with the key by cmp, at the same index, and returns the replaced composer.
+// Returns an error if the key is not found, if cmp is nil or if the key of cmp is already used by another composer.
+func (c *ContentStack) Replace(key string, cmp ContentComposer) (ContentComposer, error) {
+	idx := c.IndexOf(key)
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+	if cmp == nil || reflect.TypeOf(cmp).Kind() != reflect.Ptr || reflect.ValueOf(cmp).IsNil() {
+		return nil, ErrEmptyComposer
+	}
+	if newkey := cmp.RMeta().Key; newkey != key && c.IndexOf(newkey) >= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, newkey)
+	}
+	old := c.Stack[idx]
+	c.Stack[idx] = cmp
+	return old, nil
+}
@@ -0,0 +1,89 @@
+package ickcore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentStackKeyed(t *testing.T) {
+
+	var c ContentStack
+	c.Push(Keyed("a", ToHTML("a")), ToHTML("-"), Keyed("b", ToHTML("b")))
+	assert.Equal(t, 3, c.Len())
+	assert.Equal(t, []string{"a", "", "b"}, c.Keys())
+	assert.Equal(t, 2, c.IndexOf("b"))
+	assert.Equal(t, -1, c.IndexOf(""))
+	assert.Equal(t, -1, c.IndexOf("z"))
+	assert.Nil(t, c.Lookup("z"))
+	require.NotNil(t, c.Lookup("a"))
+	assert.Equal(t, "a", c.Lookup("a").RMeta().Key)
+
+	// insert
+	at, err := c.Insert(1, Keyed("c", ToHTML("c")))
+	require.NoError(t, err)
+	assert.Equal(t, 1, at)
+	at, err = c.Insert(99, Keyed("d", ToHTML("d")))
+	require.NoError(t, err)
+	assert.Equal(t, 4, at)
+	_, err = c.Insert(0, Keyed("c", ToHTML("c")))
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	_, err = c.Insert(0, nil)
+	assert.ErrorIs(t, err, ErrEmptyComposer)
+	assert.Equal(t, []string{"a", "c", "", "b", "d"}, c.Keys())
+
+	// move
+	at, err = c.Move("d", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, at)
+	_, err = c.Move("z", 0)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, []string{"d", "a", "c", "", "b"}, c.Keys())
+
+	// replace
+	old, err := c.Replace("c", Keyed("e", ToHTML("e")))
+	require.NoError(t, err)
+	assert.Equal(t, "c", old.RMeta().Key)
+	_, err = c.Replace("e", Keyed("a", ToHTML("a")))
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	_, err = c.Replace("z", Keyed("z", ToHTML("z")))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// remove
+	assert.Nil(t, c.Remove("z"))
+	assert.NotNil(t, c.Remove("d"))
+	assert.Equal(t, []string{"a", "e", "", "b"}, c.Keys())
+
+	// iteration
+	n := 0
+	c.Each(func(i int, cmp ContentComposer) bool {
+		n++
+		return i < 1
+	})
+	assert.Equal(t, 2, n)
+
+	out := new(bytes.Buffer)
+	err = c.RenderStack(out, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ae-b", out.String())
+}
+
+func TestKeyDataAttribute(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	s := &snip1{Test: 1}
+	s.RMeta().Key = "k1"
+	err := RenderChild(out, nil, s)
+	require.NoError(t, err)
+	assert.Equal(t, `<span name="snip1" data-ick-key="k1"></span>`, out.String())
+
+	parent := &snip1{Test: 1}
+	out.Reset()
+	err = RenderChild(out, parent, s)
+	require.NoError(t, err)
+	require.Len(t, parent.RMeta().Embedded(), 1)
+	parent.RMeta().Unembed(s)
+	assert.Empty(t, parent.RMeta().Embedded())
+}
@@ -1,8 +1,497 @@
 package ickcore
 
-// type DataState struct {
-// 	//Id   string // the id of the current processing component
-// 	//Me   any    // the current processing component, should embedd an HtmlSnippet
-// 	Page any // the current ick page, can be nil
-// 	App  any // the current ick App, can be nil
-// }
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// RenderData renders composers like RenderChild, resolving the template expressions of their HTMLString against data.
+// data is inherited by the composers rendered as children, unless they have their own data context.
+//
+// HTMLString can embed template expressions resolved against a data context when rendering.
+// The syntax is the one of the go text/template package:
+//
+//	`<p>Hello {{ .Name }}</p>`
+//	`<ick-button Title="{{ .Action }}"/>`
+//	`{{ if .Items }}<ul>{{ range .Items }}<li>{{ . }}</li>{{ end }}</ul>{{ else }}<p>no item</p>{{ end }}`
+//
+// Expressions are only evaluated when a data context is bound to the HTMLString or to one of its rendering parents,
+// with RenderData or by setting the Data field of the rendering metadata. Otherwise the HTMLString is rendered as is.
+//
+// Values are escaped according to the context where they're rendered, whatever the pipeline:
+// element body, attribute value, URL attribute, <style> or <script> element. An ick-tag attribute value is escaped too,
+// and unescaped before being unfolded into the composer's property, unless the property is an HTMLString or an interface:
+// the value is then unfolded as text, like with ToText.
+// HTMLString values are trusted and rendered verbatim in an element body.
+// The context is computed by scanning the template source linearly, so branches of a conditional should end in the same context.
+// Expressions are not allowed within a tag name or in place of an attribute name.
+//
+// If AutoEscape is off, values are rendered unchanged.
+func RenderData(out io.Writer, parent RMetaProvider, data any, child Composer, siblings ...Composer) error {
+	err := render(out, parent, child, data, nil)
+	if err != nil {
+		return err
+	}
+	for _, s := range siblings {
+		err := render(out, parent, s, data, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasexpression returns true if the htmlstring contains a template action
+func hasexpression(htmlstring []byte) bool {
+	return bytes.Contains(htmlstring, []byte("{{"))
+}
+
+// datatemplate is the compiled template of an HTMLString, with the offsets of its rewritten source back to the HTMLString.
+type datatemplate struct {
+	*template.Template
+	src       []byte      // source of the HTMLString
+	rewritten string      // source of the template, rewritten by contextualize
+	offsets   []srcoffset // offsets within rewritten and src where they start to differ, in ascending order
+}
+
+// srcoffset is an offset within the rewritten source of a template and the matching offset within the HTMLString.
+// A rewritten output action matches the start of its expression as a whole.
+type srcoffset struct {
+	rewritten int
+	src       int
+	action    bool
+}
+
+// srcoffset returns the offset within the HTMLString matching the offset within the rewritten source.
+func (tmpl *datatemplate) srcoffset(offset int) int {
+	i := sort.Search(len(tmpl.offsets), func(i int) bool { return tmpl.offsets[i].rewritten > offset }) - 1
+	if i < 0 {
+		return offset
+	}
+	if tmpl.offsets[i].action {
+		return tmpl.offsets[i].src
+	}
+	return mini(tmpl.offsets[i].src+offset-tmpl.offsets[i].rewritten, len(tmpl.src))
+}
+
+// compiletemplate parses the template expressions of htmlstring, with their escaping functions.
+// Returns the datatemplate with the parsing error if any, so the position of the error can be located within htmlstring.
+func compiletemplate(htmlstring []byte) (*datatemplate, error) {
+	src, offsets, err := contextualize(htmlstring)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	tmpl := &datatemplate{src: htmlstring, rewritten: src, offsets: offsets}
+	tmpl.Template, err = template.New("").Funcs(escaperfuncs).Option("missingkey=zero").Parse(src)
+	return tmpl, err
+}
+
+// executetemplate evaluates the template expressions of tmpl against data.
+func executetemplate(tmpl *datatemplate, data any) ([]byte, error) {
+	out := new(bytes.Buffer)
+	if err := tmpl.Execute(out, data); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// escaperfuncs are the escaping functions appended to every output pipeline by contextualize
+var escaperfuncs = template.FuncMap{
+	"_ickbody": escbody,
+	"_ickattr": escattr,
+	"_ickick":  escickattr,
+	"_ickcss":  esccss,
+	"_ickjs":   escjs,
+}
+
+// stringify returns the string representation of an expression's value, and true if the value is a trusted HTMLString.
+// The private delimiters the value may contain are replaced.
+func stringify(v any) (string, bool) {
+	switch tv := v.(type) {
+	case nil:
+		return "", false
+	case HTMLString:
+		return markReplacer.Replace(string(tv.bytes)), true
+	case *HTMLString:
+		if tv == nil {
+			return "", false
+		}
+		return markReplacer.Replace(string(tv.bytes)), true
+	case string:
+		return markReplacer.Replace(tv), false
+	}
+	return markReplacer.Replace(fmt.Sprint(v)), false
+}
+
+// private use runes delimiting the data-bound parts of ick-tag attribute values, see unbind,
+// and the source offsets of the ick-tags inserted in the template, see unmark.
+const (
+	boundopen  string = "\uE000"
+	boundclose string = "\uE001"
+	markopen   string = "\uE002"
+	markclose  string = "\uE003"
+)
+
+var jsReplacer = strings.NewReplacer("{{", `{\u007b`, boundopen, `\ue000`, boundclose, `\ue001`, markopen, `\ue002`, markclose, `\ue003`)
+
+var markReplacer = strings.NewReplacer(boundopen, "\uFFFD", boundclose, "\uFFFD", markopen, "\uFFFD", markclose, "\uFFFD")
+
+// unmark removes the markers inserted by contextualize from the expanded output of a template.
+// Returns the output without markers, and the offsets within the template source of the marked ick-tags by their offset within the output.
+func unmark(b []byte) ([]byte, map[int]int) {
+	marks := make(map[int]int)
+	if !bytes.Contains(b, []byte(markopen)) {
+		return b, marks
+	}
+	out := make([]byte, 0, len(b))
+	for {
+		before, after, found := bytes.Cut(b, []byte(markopen))
+		out = append(out, before...)
+		if !found {
+			return out, marks
+		}
+		offset, rest, _ := bytes.Cut(after, []byte(markclose))
+		if at, err := strconv.Atoi(string(offset)); err == nil {
+			marks[len(out)] = at
+		}
+		b = rest
+	}
+}
+
+// nested expressions are neutralized to avoid a rendered value being evaluated again within a child HTMLString
+var (
+	bodyexprReplacer = strings.NewReplacer("{{", "&#123;&#123;")
+	ickattrReplacer  = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&#34;", `'`, "&#39;", ` `, "&#32;", "\t", "&#9;", "\n", "&#10;", "\r", "&#13;", `/`, "&#47;", `=`, "&#61;", "{", "&#123;")
+)
+
+func escbody(v any) string {
+	s, trusted := stringify(v)
+	if trusted || !AutoEscape {
+		return s
+	}
+	return bodyexprReplacer.Replace(bodyReplacer.Replace(s))
+}
+
+// escattr escapes v rendered within the value of the aname attribute of a standard HTML tag, quoted or not.
+func escattr(aname string, v any) string {
+	s, _ := stringify(v)
+	if !AutoEscape {
+		return s
+	}
+	switch AttributeContext(aname) {
+	case ESCCTX_URL:
+		s = filterURL(s)
+	case ESCCTX_STYLE:
+		s = cssReplacer.Replace(s)
+	}
+	return ickattrReplacer.Replace(s)
+}
+
+// escickattr escapes v rendered within the value of an ick-tag attribute.
+// The escaped value is delimited so unfoldick can tell it from the literal part of the attribute, see unbind.
+func escickattr(v any) string {
+	s, _ := stringify(v)
+	if !AutoEscape {
+		return s
+	}
+	return boundopen + ickattrReplacer.Replace(s) + boundclose
+}
+
+// unbind returns the value of an ick-tag attribute without the delimiters of its data-bound parts.
+// The value is unescaped, so an attribute is escaped once when rendered, unless astext is true:
+// it's then kept escaped to be rendered as text within an HTMLString.
+func unbind(value string, astext bool) string {
+	if !strings.Contains(value, boundopen) {
+		if astext {
+			return value
+		}
+		return html.UnescapeString(value)
+	}
+	var sb strings.Builder
+	for {
+		literal, rest, found := strings.Cut(value, boundopen)
+		if !astext {
+			literal = html.UnescapeString(literal)
+		}
+		sb.WriteString(literal)
+		if !found {
+			return sb.String()
+		}
+		bound, rest, _ := strings.Cut(rest, boundclose)
+		if !astext {
+			bound = html.UnescapeString(bound)
+		}
+		sb.WriteString(bound)
+		value = rest
+	}
+}
+
+func esccss(v any) string {
+	s, _ := stringify(v)
+	if !AutoEscape {
+		return s
+	}
+	return strings.ReplaceAll(cssReplacer.Replace(s), "{{", `{\{`)
+}
+
+// escjs renders v as a JSON value within a <script> element.
+func escjs(v any) string {
+	if !AutoEscape {
+		s, _ := stringify(v)
+		return s
+	}
+	if h, ok := v.(HTMLString); ok {
+		v = string(h.bytes)
+	} else if h, ok := v.(*HTMLString); ok && h != nil {
+		v = string(h.bytes)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	// json.Marshal already escapes <, > and &. "{{" and private delimiters can only appear within a json string.
+	return jsReplacer.Replace(string(b))
+}
+
+// template contexts computed by contextualize
+type tmplcontext int
+
+const (
+	tmplctx_TEXT    tmplcontext = iota // element body
+	tmplctx_COMMENT                    // within an html comment
+	tmplctx_TAG                        // within a tag, between attributes
+	tmplctx_ANAME                      // within an attribute's name
+	tmplctx_AEQ                        // after an attribute's '=', before its value
+	tmplctx_AVALUE                     // within an attribute's value
+	tmplctx_RAW                        // within a <style> or a <script> element
+)
+
+// control actions keywords, not rendering any value
+var controlactions = []string{"if", "else", "end", "range", "with", "define", "template", "block", "break", "continue"}
+
+// contextualize scans the template source and appends an escaping function to every output action according to its context.
+// Control actions, comments and variable declarations are kept unchanged.
+// Every opening ick-tag is preceded by a marker with its offset within src, so its position can be reported, see unmark.
+// Returns the rewritten source with the offsets where it starts to differ from src, see datatemplate.
+func contextualize(src []byte) (string, []srcoffset, error) {
+	var out strings.Builder
+	var offsets []srcoffset
+	ctx := tmplctx_TEXT
+	var tagname, aname string
+	var isick, isclosing bool
+	var quote byte
+
+	isnamebyte := func(b byte) bool {
+		return b == '-' || b == ':' || b == '_' || b == '.' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+	}
+	endtag := func() {
+		ctx = tmplctx_TEXT
+		if !isclosing && (tagname == "style" || tagname == "script") {
+			ctx = tmplctx_RAW
+		}
+	}
+
+	for i := 0; i < len(src); {
+		if bytes.HasPrefix(src[i:], []byte("{{")) {
+			end, err := actionend(src, i)
+			if err != nil {
+				return "", nil, err
+			}
+			action := string(src[i:end])
+			start := i
+			i = end
+			if isoutputaction(action) {
+				var esc string
+				switch ctx {
+				case tmplctx_TEXT, tmplctx_COMMENT:
+					esc = "_ickbody"
+				case tmplctx_RAW:
+					esc = "_ickcss"
+					if tagname == "script" {
+						esc = "_ickjs"
+					}
+				case tmplctx_AEQ, tmplctx_AVALUE:
+					if ctx == tmplctx_AEQ {
+						ctx, quote = tmplctx_AVALUE, 0
+					}
+					esc = fmt.Sprintf("_ickattr %q", aname)
+					if isick {
+						esc = "_ickick"
+					}
+				default:
+					return "", nil, fmt.Errorf("expression %s not allowed within a tag name or an attribute name", action)
+				}
+				offsets = append(offsets, srcoffset{rewritten: out.Len(), src: start + strings.Index(action, actioninner(action)), action: true})
+				out.WriteString(wrapaction(action, esc))
+				offsets = append(offsets, srcoffset{rewritten: out.Len(), src: end})
+				continue
+			}
+			out.WriteString(action)
+			continue
+		}
+
+		b := src[i]
+		switch ctx {
+		case tmplctx_TEXT:
+			switch {
+			case bytes.HasPrefix(src[i:], []byte("<!--")):
+				ctx = tmplctx_COMMENT
+			case b == '<' && i+1 < len(src) && (src[i+1] == '/' || isnamebyte(src[i+1])):
+				j := i + 1
+				isclosing = src[j] == '/'
+				if isclosing {
+					j++
+				}
+				k := j
+				for k < len(src) && isnamebyte(src[k]) {
+					k++
+				}
+				tagname = strings.ToLower(string(src[j:k]))
+				isick = strings.HasPrefix(tagname, "ick-")
+				if isick && !isclosing {
+					out.WriteString(markopen + strconv.Itoa(i) + markclose)
+					offsets = append(offsets, srcoffset{rewritten: out.Len(), src: i})
+				}
+				out.Write(src[i:k])
+				i = k
+				ctx = tmplctx_TAG
+				continue
+			}
+		case tmplctx_COMMENT:
+			if bytes.HasPrefix(src[i:], []byte("-->")) {
+				out.WriteString("-->")
+				i += 3
+				ctx = tmplctx_TEXT
+				continue
+			}
+		case tmplctx_RAW:
+			if closing := "</" + tagname; len(src[i:]) >= len(closing) && strings.EqualFold(string(src[i:i+len(closing)]), closing) {
+				out.Write(src[i : i+len(closing)])
+				i += len(closing)
+				isclosing = true
+				ctx = tmplctx_TAG
+				continue
+			}
+		case tmplctx_TAG, tmplctx_ANAME:
+			switch {
+			case b == '>':
+				endtag()
+			case b == '=' && ctx == tmplctx_ANAME:
+				ctx = tmplctx_AEQ
+			case isnamebyte(b):
+				if ctx == tmplctx_TAG {
+					aname = ""
+				}
+				aname += string(b)
+				ctx = tmplctx_ANAME
+			default:
+				ctx = tmplctx_TAG
+			}
+		case tmplctx_AEQ:
+			switch {
+			case b == ' ' || b == '\t' || b == '\n' || b == '\r':
+			case b == '"' || b == '\'':
+				ctx, quote = tmplctx_AVALUE, b
+			case b == '>':
+				endtag()
+			default:
+				ctx, quote = tmplctx_AVALUE, 0
+			}
+		case tmplctx_AVALUE:
+			switch {
+			case quote != 0 && b == quote:
+				ctx = tmplctx_TAG
+			case quote == 0 && (b == ' ' || b == '\t' || b == '\n' || b == '\r'):
+				ctx = tmplctx_TAG
+			case quote == 0 && b == '>':
+				endtag()
+			case quote == 0 && bytes.HasPrefix(src[i:], []byte("/>")):
+				ctx = tmplctx_TAG
+			}
+		}
+		out.WriteByte(b)
+		i++
+	}
+	return out.String(), offsets, nil
+}
+
+// actionend returns the position following the end of the action starting at src[at].
+// Strings within the action may contain "}}".
+func actionend(src []byte, at int) (int, error) {
+	var quote byte
+	for i := at + 2; i < len(src); i++ {
+		b := src[i]
+		switch {
+		case quote != 0:
+			if b == '\\' && quote != '`' {
+				i++
+			} else if b == quote {
+				quote = 0
+			}
+		case b == '"' || b == '`' || b == '\'':
+			quote = b
+		case b == '}' && i+1 < len(src) && src[i+1] == '}':
+			return i + 2, nil
+		}
+	}
+	return 0, fmt.Errorf("unclosed action %q", string(src[at:mini(at+20, len(src))]))
+}
+
+// isoutputaction returns true if the action renders a value
+func isoutputaction(action string) bool {
+	inner := actioninner(action)
+	if inner == "" || strings.HasPrefix(inner, "/*") {
+		return false
+	}
+	word := inner
+	if n := strings.IndexAny(inner, " \t\n\r("); n >= 0 {
+		word = inner[:n]
+	}
+	for _, kw := range controlactions {
+		if word == kw {
+			return false
+		}
+	}
+	// variable declaration or assignment
+	if strings.HasPrefix(inner, "$") {
+		decl := inner
+		if n := strings.IndexAny(decl, "|\"`'("); n >= 0 {
+			decl = decl[:n]
+		}
+		if strings.Contains(decl, "=") {
+			return false
+		}
+	}
+	return true
+}
+
+// actioninner returns the pipeline of the action, without delimiters and trim markers
+func actioninner(action string) string {
+	inner := strings.TrimSuffix(strings.TrimPrefix(action, "{{"), "}}")
+	if strings.HasPrefix(inner, "- ") {
+		inner = inner[1:]
+	}
+	if strings.HasSuffix(inner, " -") {
+		inner = inner[:len(inner)-1]
+	}
+	return strings.TrimSpace(inner)
+}
+
+// wrapaction appends the esc escaping function to the pipeline of the action, keeping its trim markers
+func wrapaction(action string, esc string) string {
+	left, right := "{{", "}}"
+	if strings.HasPrefix(action, "{{- ") {
+		left = "{{- "
+	}
+	if strings.HasSuffix(action, " -}}") {
+		right = " -}}"
+	}
+	return left + "(" + actioninner(action) + ") | " + esc + right
+}
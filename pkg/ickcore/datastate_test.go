@@ -0,0 +1,143 @@
+package ickcore
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tstData struct {
+	Name  string
+	Link  string
+	Items []string
+	Show  bool
+	Body  HTMLString
+	N     int
+}
+
+type sniph8 struct {
+	BareSnippet
+	Label string
+	Href  string
+}
+
+func (s *sniph8) BuildTag() Tag {
+	s.Tag().SetTagName("a").SetAttribute("href", s.Href)
+	return *s.Tag()
+}
+
+func (s *sniph8) RenderContent(out io.Writer) error {
+	return RenderChild(out, s, ToText(s.Label))
+}
+
+type sniph10 struct {
+	BareSnippet
+	Title HTMLString
+	Body  ContentComposer
+}
+
+func (s *sniph10) RenderContent(out io.Writer) error {
+	return RenderChild(out, s, &s.Title, s.Body)
+}
+
+func TestContextualize(t *testing.T) {
+	tstset := []struct {
+		in   string
+		want string
+	}{
+		{in: `{{ .A }}`, want: `{{(.A) | _ickbody}}`},
+		{in: `{{- .A -}}`, want: `{{- (.A) | _ickbody -}}`},
+		{in: `{{if .A}}{{else}}{{end}}{{range .B}}{{end}}{{/* c */}}{{ $x := .A }}`, want: `{{if .A}}{{else}}{{end}}{{range .B}}{{end}}{{/* c */}}{{ $x := .A }}`},
+		{in: `<a href="{{ .A }}" title={{ .B }}>{{ .C }}</a>`, want: `<a href="{{(.A) | _ickattr "href"}}" title={{(.B) | _ickattr "title"}}>{{(.C) | _ickbody}}</a>`},
+		{in: `<ick-x a='{{ .A }}'/>`, want: markopen + `0` + markclose + `<ick-x a='{{(.A) | _ickick}}'/>`},
+		{in: `<p><ick-x/></ick-x></p>`, want: `<p>` + markopen + `3` + markclose + `<ick-x/></ick-x></p>`},
+		{in: `<style>p{color:{{ .A }}}</style><script>var a={{ .A }};</script>{{ .B }}`, want: `<style>p{color:{{(.A) | _ickcss}}}</style><script>var a={{(.A) | _ickjs}};</script>{{(.B) | _ickbody}}`},
+		{in: `<!-- {{ .A }} -->`, want: `<!-- {{(.A) | _ickbody}} -->`},
+		{in: `{{ printf "}}" }}`, want: `{{(printf "}}") | _ickbody}}`},
+	}
+	for _, tst := range tstset {
+		out, _, err := contextualize([]byte(tst.in))
+		require.NoError(t, err, tst.in)
+		assert.Equal(t, tst.want, out, tst.in)
+	}
+
+	_, _, err := contextualize([]byte(`<a {{ .A }}>`))
+	assert.Error(t, err)
+	_, _, err = contextualize([]byte(`{{ .A `))
+	assert.Error(t, err)
+}
+
+func TestRenderData(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph8", &sniph8{})
+	AddRegistryEntry("ick-tstsniph10", &sniph10{})
+
+	data := &tstData{
+		Name:  `<b>Tom & "Jerry"</b>`,
+		Link:  `javascript:alert(1)`,
+		Items: []string{"a", "<b>", "{{ .N }}"},
+		Show:  true,
+		Body:  *ToHTML(`<i>trusted</i>`),
+		N:     42,
+	}
+
+	tstset := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "text", in: `<p>{{ .Name }}</p>`, want: `<p>&lt;b&gt;Tom &amp; &#34;Jerry&#34;&lt;/b&gt;</p>`},
+		{name: "attribute", in: `<p title="{{ .Name }}" data-n={{ .N }}></p>`, want: `<p title="&lt;b&gt;Tom&#32;&amp;&#32;&#34;Jerry&#34;&lt;&#47;b&gt;" data-n=42></p>`},
+		{name: "url", in: `<a href="{{ .Link }}">x</a>`, want: `<a href="#ick-unsafe-url">x</a>`},
+		{name: "trusted", in: `<div>{{ .Body }}</div>`, want: `<div><i>trusted</i></div>`},
+		{name: "if", in: `{{ if .Show }}yes{{ else }}no{{ end }}`, want: `yes`},
+		{name: "range", in: `<ul>{{ range .Items }}<li>{{ . }}</li>{{ end }}</ul>`, want: `<ul><li>a</li><li>&lt;b&gt;</li><li>&#123;&#123; .N }}</li></ul>`},
+		{name: "missing", in: `<p>{{ .Nope }}</p>`, want: `<!--template: no field-->`},
+		{name: "ick-tag", in: `<ick-tstsniph8 label="{{ .Name }}" href='{{ .Link }}'/>`, want: `<a name="sniph8" href="#ick-unsafe-url">&lt;b&gt;Tom &amp; &#34;Jerry&#34;&lt;/b&gt;</a>`},
+		{name: "ick-tag no injection", in: `{{ range .Items }}<ick-tstsniph8 label={{ . }}/>{{ end }}`, want: `<a name="sniph8" href>a</a><a name="sniph8" href>&lt;b&gt;</a><a name="sniph8" href>{{ .N }}</a>`},
+		{name: "ick-tag html property", in: `<ick-tstsniph10 Title="<b>{{ .Name }}</b>" body="{{ .Name }}"/>`, want: `<b>&lt;b&gt;Tom&#32;&amp;&#32;&#34;Jerry&#34;&lt;&#47;b&gt;</b>&lt;b&gt;Tom&#32;&amp;&#32;&#34;Jerry&#34;&lt;&#47;b&gt;`},
+		{name: "ick-tag literal", in: `<ick-tstsniph8 label="a &amp; {{ .N }}"/>`, want: `<a name="sniph8" href>a &amp; 42</a>`},
+		{name: "ick-tag entity", in: `<ick-tstsniph8 data-t="a &amp; b"/>`, want: `<a name="sniph8" data-t="a &amp; b" href></a>`},
+		{name: "script", in: `<script>var n={{ .Name }};</script>`, want: `<script>var n="\u003cb\u003eTom \u0026 \"Jerry\"\u003c/b\u003e";</script>`},
+	}
+
+	out := new(bytes.Buffer)
+	for _, tst := range tstset {
+		out.Reset()
+		err := RenderData(out, nil, data, ToHTML(tst.in))
+		if tst.name == "missing" {
+			assert.Error(t, err, tst.name)
+			continue
+		}
+		require.NoError(t, err, tst.name)
+		assert.Equal(t, tst.want, out.String(), tst.name)
+	}
+
+	// without data, expressions are not evaluated
+	out.Reset()
+	err := RenderChild(out, nil, ToHTML(`<p>{{ .Name }}</p>`))
+	require.NoError(t, err)
+	assert.Equal(t, `<p>{{ .Name }}</p>`, out.String())
+
+	// data is inherited from the parent
+	out.Reset()
+	parent := new(BareSnippet)
+	parent.RMeta().Data = data
+	err = RenderChild(out, parent, ToHTML(`<p>{{ .N }}</p>`))
+	require.NoError(t, err)
+	assert.Equal(t, `<p>42</p>`, out.String())
+
+	// the data of a rendering is not kept by the composer
+	h := ToHTML(`<p>{{ . }}</p>`)
+	out.Reset()
+	err = RenderData(out, nil, 1, h)
+	require.NoError(t, err)
+	assert.Equal(t, `<p>1</p>`, out.String())
+	out.Reset()
+	err = RenderChild(out, nil, h)
+	require.NoError(t, err)
+	assert.Equal(t, `<p>{{ . }}</p>`, out.String())
+}
@@ -0,0 +1,84 @@
+package ickcore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/lolorenzo777/verbose"
+)
+
+// ErrorBoundary is a composer isolating the rendering of its content.
+// Its content is rendered into a buffer, and errors and panics raised during the rendering are caught.
+// If the content fails to render, the fallback is rendered instead and the error is recorded in RError of its rendering metadata,
+// so one broken composer does not stop the rendering of the whole page.
+//
+// ErrorBoundary does not render any tag itself.
+type ErrorBoundary struct {
+	meta RMetaData
+	ContentStack
+
+	// Fallback is rendered if the content fails to render. Nothing is rendered if Fallback is nil.
+	Fallback ContentComposer
+
+	// FallbackFunc, if set, is called to get the fallback to render according to the error. It takes precedence over Fallback.
+	FallbackFunc func(err error) ContentComposer
+}
+
+// Ensuring ErrorBoundary implements the right interface
+var _ ContentComposer = (*ErrorBoundary)(nil)
+
+// NewErrorBoundary returns an ErrorBoundary isolating the rendering of content and rendering fallback on failure.
+func NewErrorBoundary(fallback ContentComposer, content ...ContentComposer) *ErrorBoundary {
+	eb := new(ErrorBoundary)
+	eb.Fallback = fallback
+	eb.Push(content...)
+	return eb
+}
+
+// RMeta returns a reference to the rendering metadata
+func (eb *ErrorBoundary) RMeta() *RMetaData {
+	return &eb.meta
+}
+
+// NeedRendering returns true if the boundary has content to render
+func (eb *ErrorBoundary) NeedRendering() bool {
+	return eb.ContentStack.NeedRendering()
+}
+
+// RenderContent renders the content into a buffer and writes it to out only if the rendering succeeds.
+// Otherwise the fallback is rendered. Only an error writing the fallback is returned.
+func (eb *ErrorBoundary) RenderContent(out io.Writer) error {
+	eb.meta.RError = nil
+	buf := new(bytes.Buffer)
+	err := eb.renderstack(Redirect(buf, out))
+	if err == nil {
+		_, err = out.Write(buf.Bytes())
+		return err
+	}
+
+	eb.meta.RError = err
+	verbose.Printf(verbose.WARNING, "ErrorBoundary %q: %s\n", eb.meta.VirtualId, err.Error())
+	diagnoseError(out, eb, "", srcpos{}, err)
+
+	// forget the children partially rendered
+	eb.meta.ResetEmbedded()
+	fallback := eb.Fallback
+	if eb.FallbackFunc != nil {
+		fallback = eb.FallbackFunc(err)
+	}
+	if fallback == nil {
+		return nil
+	}
+	return RenderChild(out, eb, fallback)
+}
+
+// renderstack renders the content stack, turning a panic into an error.
+func (eb *ErrorBoundary) renderstack(out io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrRenderingPanic, r)
+		}
+	}()
+	return eb.RenderStack(out, eb)
+}
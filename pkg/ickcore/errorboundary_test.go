@@ -0,0 +1,67 @@
+package ickcore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snipfailing struct {
+	BareSnippet
+	Panic bool
+}
+
+func (s *snipfailing) RenderContent(out io.Writer) error {
+	RenderString(out, "partial")
+	if s.Panic {
+		panic("boom")
+	}
+	return errors.New("broken")
+}
+
+func TestErrorBoundary(t *testing.T) {
+
+	// no error
+	out := new(bytes.Buffer)
+	eb := NewErrorBoundary(ToHTML("fallback"), ToHTML("<p>ok</p>"))
+	err := RenderChild(out, nil, eb)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>ok</p>", out.String())
+	assert.NoError(t, eb.RMeta().RError)
+
+	// error
+	out.Reset()
+	report := new(RenderReport)
+	eb = NewErrorBoundary(ToHTML("fallback"), ToHTML("<p>ok</p>"), &snipfailing{})
+	eb.RMeta().Report = report
+	err = RenderChild(out, nil, eb)
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", out.String())
+	assert.ErrorContains(t, eb.RMeta().RError, "broken")
+	assert.Equal(t, 1, report.Len())
+	assert.Len(t, eb.RMeta().Embedded(), 1)
+
+	// panic
+	out.Reset()
+	report = new(RenderReport)
+	eb = NewErrorBoundary(nil, &snipfailing{Panic: true})
+	eb.FallbackFunc = func(err error) ContentComposer { return ToText(err.Error()) }
+	eb.RMeta().Report = report
+	err = RenderChild(out, nil, eb)
+	require.NoError(t, err)
+	assert.Equal(t, "panic during rendering: boom", out.String())
+	assert.ErrorIs(t, eb.RMeta().RError, ErrRenderingPanic)
+	assert.Equal(t, 1, report.Len())
+
+	// no fallback
+	out.Reset()
+	eb = NewErrorBoundary(nil, &snipfailing{})
+	err = RenderChild(out, nil, eb)
+	require.NoError(t, err)
+	assert.Empty(t, out.String())
+	assert.Error(t, eb.RMeta().RError)
+}
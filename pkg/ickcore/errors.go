@@ -9,6 +9,11 @@ var (
 	ErrBodyTagMissing            = errors.New("<body> tag missing")
 	ErrTooManyRecursiveRendering = errors.New("too many recursive rendering")
 	ErrNameMissing               = errors.New("'opening <ick-' tag found without name")
+	ErrAttributeNotUnfolded      = errors.New("attribute not unfolded")
+	ErrRenderingPanic            = errors.New("panic during rendering")
+	ErrEmptyComposer             = errors.New("empty composer")
+	ErrDuplicateKey              = errors.New("duplicate key")
+	ErrKeyNotFound               = errors.New("key not found")
 )
 
 type IckTagNameError struct {
@@ -0,0 +1,142 @@
+package ickcore
+
+import (
+	"io"
+	"strings"
+)
+
+// AutoEscape turns on the safe rendering mode. It's on by default.
+//
+// When AutoEscape is true, text content rendered with RenderText or ToText and tag attribute values are escaped according to their context:
+// element body, quoted attribute, URL attribute or style. HTMLString values are explicitly trusted and are always rendered verbatim.
+//
+// Set AutoEscape to false to restore the legacy behaviour where every string is written through unchanged.
+var AutoEscape bool = true
+
+// ESCAPE_CONTEXT defines where a string is rendered within the HTML output
+type ESCAPE_CONTEXT int
+
+const (
+	ESCCTX_BODY      ESCAPE_CONTEXT = iota // text inside an element body
+	ESCCTX_ATTRIBUTE                       // value of a quoted attribute
+	ESCCTX_URL                             // value of an attribute expecting an URL, like href or src
+	ESCCTX_STYLE                           // content of a <style> element or a style attribute
+	ESCCTX_SCRIPT                          // content of a <script> element
+	ESCCTX_CODE                            // source code inside an element body, braces are escaped so template expressions within the code are never evaluated
+)
+
+// unsafeURL replaces any URL value with a scheme that can run code in the browser.
+const unsafeURL string = "#ick-unsafe-url"
+
+var (
+	bodyReplacer = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&#34;", `'`, "&#39;")
+	attrReplacer = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&#34;")
+	cssReplacer  = strings.NewReplacer(`</`, `<\/`, `<!--`, `<\!--`)
+)
+
+// EscapeString returns s escaped for the context where it will be rendered.
+// EscapeString escapes s whatever the AutoEscape mode.
+func EscapeString(ctx ESCAPE_CONTEXT, s string) string {
+	switch ctx {
+	case ESCCTX_ATTRIBUTE:
+		return escapeAttribute(s)
+	case ESCCTX_URL:
+		return escapeAttribute(filterURL(s))
+	case ESCCTX_STYLE:
+		return cssReplacer.Replace(s)
+	case ESCCTX_SCRIPT:
+		return escapeScript(s)
+	case ESCCTX_CODE:
+		return strings.ReplaceAll(bodyReplacer.Replace(s), "{", "&#123;")
+	default:
+		return bodyReplacer.Replace(s)
+	}
+}
+
+// AttributeContext returns the escaping context of an attribute according to its name.
+func AttributeContext(aname string) ESCAPE_CONTEXT {
+	switch strings.ToLower(aname) {
+	case "href", "src", "action", "formaction", "cite", "poster", "background", "longdesc", "usemap", "manifest", "xlink:href":
+		return ESCCTX_URL
+	case "style":
+		return ESCCTX_STYLE
+	}
+	return ESCCTX_ATTRIBUTE
+}
+
+// RenderText writes one or many text strings to w, escaped for an element body if AutoEscape is on.
+// Use RenderString to write trusted HTML markup.
+// Returns the number of bytes written and errors from the writer.
+func RenderText(w io.Writer, ss ...string) (n int, err error) {
+	if !AutoEscape {
+		return RenderString(w, ss...)
+	}
+	nn := 0
+	for _, s := range ss {
+		nn, err = io.WriteString(w, bodyReplacer.Replace(s))
+		if err != nil {
+			return
+		}
+		n += nn
+	}
+	return
+}
+
+// RenderTextIf writes one or many text strings to w only if the condition is true.
+// See RenderText.
+func RenderTextIf(condition bool, w io.Writer, ss ...string) (n int, err error) {
+	if !condition {
+		return 0, nil
+	}
+	return RenderText(w, ss...)
+}
+
+// escapeAttribute escapes a value to be rendered within a quoted attribute.
+// Double quotes are always escaped so the value is safe within a double quoted attribute whatever the delimiter.
+func escapeAttribute(s string) string {
+	return attrReplacer.Replace(s)
+}
+
+// escapeAttributeValue escapes the value of the aname attribute according to its context.
+// Style attributes are escaped like any other quoted attribute.
+func escapeAttributeValue(aname string, value string) string {
+	if AttributeContext(aname) == ESCCTX_URL {
+		return EscapeString(ESCCTX_URL, value)
+	}
+	return escapeAttribute(value)
+}
+
+// SafeAttributeValue returns the value of the aname attribute to set directly in the DOM, without HTML escaping.
+// If AutoEscape is on, URLs with a scheme able to run code are replaced.
+func SafeAttributeValue(aname string, value string) string {
+	if AutoEscape && AttributeContext(aname) == ESCCTX_URL {
+		return filterURL(value)
+	}
+	return value
+}
+
+// filterURL returns unsafeURL if the url has a scheme able to run code, like javascript: or vbscript:.
+// data: urls are only allowed for images.
+func filterURL(rawurl string) string {
+	u := strings.ToLower(strings.TrimLeft(rawurl, " \t\n\r\f"))
+	// browsers ignore control chars and blanks within the scheme
+	u = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, u)
+	scheme, _, found := strings.Cut(u, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return rawurl
+	}
+	switch scheme {
+	case "javascript", "vbscript":
+		return unsafeURL
+	case "data":
+		if !strings.HasPrefix(u, "data:image/") {
+			return unsafeURL
+		}
+	}
+	return rawurl
+}
@@ -0,0 +1,62 @@
+package ickcore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeString(t *testing.T) {
+	assert.Equal(t, `&lt;b&gt;Tom &amp; &#34;Jerry&#39;s&#34;&lt;/b&gt;`, EscapeString(ESCCTX_BODY, `<b>Tom & "Jerry's"</b>`))
+
+	assert.Equal(t, `a &lt;b&gt; 'c'`, EscapeString(ESCCTX_ATTRIBUTE, `a <b> 'c'`))
+	assert.Equal(t, `a &#34;b&#34; 'c'`, EscapeString(ESCCTX_ATTRIBUTE, `a "b" 'c'`))
+	assert.Equal(t, `x&#34; onmouseover=&#34;alert(1)`, EscapeString(ESCCTX_ATTRIBUTE, `x" onmouseover="alert(1)`))
+
+	assert.Equal(t, `/docs?a=1&amp;b=2`, EscapeString(ESCCTX_URL, `/docs?a=1&b=2`))
+	assert.Equal(t, `https://icecake.dev`, EscapeString(ESCCTX_URL, `https://icecake.dev`))
+	assert.Equal(t, unsafeURL, EscapeString(ESCCTX_URL, `javascript:alert(1)`))
+	assert.Equal(t, unsafeURL, EscapeString(ESCCTX_URL, ` JavaScript:alert(1)`))
+	assert.Equal(t, unsafeURL, EscapeString(ESCCTX_URL, "java\tscript:alert(1)"))
+	assert.Equal(t, unsafeURL, EscapeString(ESCCTX_URL, `data:text/html,<script>`))
+	assert.Equal(t, `data:image/png;base64,AAAA`, EscapeString(ESCCTX_URL, `data:image/png;base64,AAAA`))
+	assert.Equal(t, `/path:with:colon`, EscapeString(ESCCTX_URL, `/path:with:colon`))
+
+	assert.Equal(t, `p > a { color: red; }<\/style>`, EscapeString(ESCCTX_STYLE, `p > a { color: red; }</style>`))
+
+	assert.Equal(t, ESCCTX_URL, AttributeContext("HRef"))
+	assert.Equal(t, ESCCTX_STYLE, AttributeContext("style"))
+	assert.Equal(t, ESCCTX_ATTRIBUTE, AttributeContext("title"))
+}
+
+func TestSafeAttributeValue(t *testing.T) {
+	assert.Equal(t, `a "b" <c> &amp;`, SafeAttributeValue("title", `a "b" <c> &amp;`))
+	assert.Equal(t, `/docs?a=1&b=2`, SafeAttributeValue("href", `/docs?a=1&b=2`))
+	assert.Equal(t, unsafeURL, SafeAttributeValue("src", `javascript:alert(1)`))
+
+	AutoEscape = false
+	defer func() { AutoEscape = true }()
+	assert.Equal(t, `javascript:alert(1)`, SafeAttributeValue("src", `javascript:alert(1)`))
+}
+
+func TestRenderText(t *testing.T) {
+	out := new(bytes.Buffer)
+	RenderText(out, `<script>`, `&`)
+	assert.Equal(t, `&lt;script&gt;&amp;`, out.String())
+
+	out.Reset()
+	RenderChild(out, nil, ToText(`<ick-tstsniph2/>`))
+	assert.Equal(t, `&lt;ick-tstsniph2/&gt;`, out.String())
+
+	amap := AttributeMap{"title": `a"b'c`, "href": `javascript:void(0)`, "alt": `<x>`}
+	assert.Equal(t, `alt="&lt;x&gt;" href="#ick-unsafe-url" title="a&#34;b'c"`, amap.AttributeString())
+
+	AutoEscape = false
+	defer func() { AutoEscape = true }()
+
+	out.Reset()
+	RenderText(out, `<script>`)
+	assert.Equal(t, `<script>`, out.String())
+	assert.Equal(t, `alt="<x>" href="javascript:void(0)" title='ambiguous quotes in the value'`, amap.AttributeString())
+}
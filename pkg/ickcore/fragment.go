@@ -0,0 +1,121 @@
+package ickcore
+
+import (
+	"bytes"
+	"io"
+)
+
+// Fragment is a composer rendering several composers without enclosing tag.
+// The content is embedded in the fragment, so it's handled as a single unit: the children are mounted and unmounted together.
+//
+// The content is rendered between two html comments, see Markers, so its nodes can be found in the DOM.
+type Fragment struct {
+	meta RMetaData
+	ContentStack
+}
+
+// Ensuring Fragment implements the right interface
+var _ ContentComposer = (*Fragment)(nil)
+
+// NewFragment returns a Fragment rendering the content.
+func NewFragment(content ...ContentComposer) *Fragment {
+	f := new(Fragment)
+	f.Push(content...)
+	return f
+}
+
+// RMeta returns a reference to the rendering metadata
+func (f *Fragment) RMeta() *RMetaData {
+	return &f.meta
+}
+
+// NeedRendering returns true if the fragment has content to render
+func (f *Fragment) NeedRendering() bool {
+	return f.ContentStack.NeedRendering()
+}
+
+// RenderContent renders the content between the markers of the fragment.
+func (f *Fragment) RenderContent(out io.Writer) error {
+	return rendermarked(out, f, &f.ContentStack)
+}
+
+/******************************************************************************/
+
+// Portal is a composer rendering its content into another location of the page, like the end of the body for modals and toasts.
+// The content is embedded in the portal, so it stays logically owned by the portal's parent: it's unmounted with it.
+//
+// Within a RenderContext the content is not written in place but recorded in the context, see RenderContext.Portals.
+// A page writes the content of its portals at the end of its body, in the browser the content is inserted into the Target element.
+// Without context the content is rendered in place.
+//
+// Like a Fragment the content is rendered between two html comments, see Markers.
+type Portal struct {
+	meta RMetaData
+	ContentStack
+
+	// Target is the css selector of the element where to render the content in the browser. The body if empty.
+	Target string
+}
+
+// Ensuring Portal implements the right interface
+var _ ContentComposer = (*Portal)(nil)
+
+// NewPortal returns a Portal rendering the content into the target element.
+func NewPortal(target string, content ...ContentComposer) *Portal {
+	p := new(Portal)
+	p.Target = target
+	p.Push(content...)
+	return p
+}
+
+// RMeta returns a reference to the rendering metadata
+func (p *Portal) RMeta() *RMetaData {
+	return &p.meta
+}
+
+// NeedRendering returns true if the portal has content to render
+func (p *Portal) NeedRendering() bool {
+	return p.ContentStack.NeedRendering()
+}
+
+// RenderContent renders the content between the markers of the portal, and records it in the current render context.
+// The content is written to out if there's no context.
+func (p *Portal) RenderContent(out io.Writer) error {
+	ctx := CurrentContext(out)
+	if ctx == nil {
+		return rendermarked(out, p, &p.ContentStack)
+	}
+	buf := new(bytes.Buffer)
+	if err := rendermarked(Redirect(buf, out), p, &p.ContentStack); err != nil {
+		return err
+	}
+	ctx.addportal(PortalContent{Target: p.Target, HTML: buf.String()})
+	return nil
+}
+
+// PortalContent is the content of a Portal recorded in a RenderContext
+type PortalContent struct {
+	Target string // css selector of the target element, the body if empty
+	HTML   string // the rendered content, markers included
+}
+
+/******************************************************************************/
+
+// Markers returns the data of the html comments surrounding the content of a Fragment or a Portal identified by its virtual id.
+func Markers(vid string) (start string, end string) {
+	vid = EscapeString(ESCCTX_BODY, vid)
+	return "ick:" + vid, "/ick:" + vid
+}
+
+// rendermarked renders the stack of cmp between its markers.
+func rendermarked(out io.Writer, cmp RMetaProvider, stack *ContentStack) error {
+	start, end := Markers(cmp.RMeta().VirtualId)
+	if _, err := io.WriteString(out, "<!--"+start+"-->"); err != nil {
+		return err
+	}
+	if err := stack.RenderStack(out, cmp); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "<!--"+end+"-->")
+	return err
+}
@@ -0,0 +1,55 @@
+package ickcore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFragment(t *testing.T) {
+
+	ResetRegistry()
+	s1 := new(snip3)
+	f := NewFragment(ToHTML("a"), s1)
+	f.RMeta().Key = "f"
+	out := new(bytes.Buffer)
+	err := RenderChild(out, nil, f)
+	require.NoError(t, err)
+	assert.Equal(t, `<!--ick:f-->a<div name="snip3"></div><!--/ick:f-->`, out.String())
+	assert.Len(t, f.RMeta().Embedded(), 2)
+	assert.Equal(t, "f.snip30", s1.RMeta().VirtualId)
+
+	start, end := Markers("a-->b")
+	assert.Equal(t, "ick:a--&gt;b", start)
+	assert.Equal(t, "/ick:a--&gt;b", end)
+
+	assert.False(t, NewFragment().NeedRendering())
+}
+
+func TestPortal(t *testing.T) {
+
+	ResetRegistry()
+
+	// without context, rendered in place
+	s1 := new(snip3)
+	p := NewPortal("#modals", s1)
+	root := &snip2{Childs: []Composer{ToHTML("a"), p}}
+	out := new(bytes.Buffer)
+	err := RenderChild(out, nil, root)
+	require.NoError(t, err)
+	assert.Equal(t, `a<!--ick:snip21.portal0--><div name="snip3"></div><!--/ick:snip21.portal0-->`, out.String())
+
+	// with a context, recorded
+	out.Reset()
+	root.RMeta().Context = NewRenderContext()
+	err = RenderChild(out, nil, root)
+	require.NoError(t, err)
+	assert.Equal(t, `a`, out.String())
+	portals := root.RMeta().Context.Portals()
+	require.Len(t, portals, 1)
+	assert.Equal(t, "#modals", portals[0].Target)
+	assert.Equal(t, `<!--ick:snip21.portal0--><div name="snip3"></div><!--/ick:snip21.portal0-->`, portals[0].HTML)
+	assert.Same(t, s1, p.RMeta().Embedded()["snip21.portal0.snip30"])
+}
@@ -0,0 +1,540 @@
+package ickcore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/icecake-framework/icecake/pkg/namingpattern"
+	"github.com/lolorenzo777/verbose"
+)
+
+// htmlast is the compiled form of an HTMLString: a list of text runs and ick-tags.
+// An htmlast is never changed once parsed, so it can be shared and reused across renders.
+type htmlast struct {
+	nodes []htmlnode
+
+	err     error  // syntax error, nodes are the ones parsed before the error
+	errname string // ick-tag name being parsed when the syntax error occurred
+	errpos  srcpos // position of the syntax error
+}
+
+// htmlnode is either a text run or an ick-tag
+type htmlnode struct {
+	text []byte   // text run, if ick is nil
+	ick  *icknode // ick-tag
+}
+
+// icknode is an ick-tag with its raw attributes and its inner content
+type icknode struct {
+	name  string       // lowercase ick-tag name
+	attrs AttributeMap // raw attribute values, never changed once parsed
+	body  []byte       // inner content of a paired ick-tag, nil for an autoclosing ick-tag
+	pos   srcpos       // position of the opening tag
+	at    int          // offset of the opening tag
+}
+
+// addtext appends a text run, merged with the previous one if any
+func (ast *htmlast) addtext(text []byte) {
+	if len(text) == 0 {
+		return
+	}
+	if n := len(ast.nodes); n > 0 && ast.nodes[n-1].ick == nil {
+		ast.nodes[n-1].text = append(ast.nodes[n-1].text, text...)
+		return
+	}
+	t := make([]byte, len(text))
+	copy(t, text)
+	ast.nodes = append(ast.nodes, htmlnode{text: t})
+}
+
+// addick appends an ick-tag
+func (ast *htmlast) addick(name string, attrs AttributeMap, body []byte, pos srcpos, at int) {
+	ast.nodes = append(ast.nodes, htmlnode{ick: &icknode{name: name, attrs: attrs, body: body, pos: pos, at: at}})
+}
+
+// relocate sets the position of the ick-tags found at the marked offsets to their position within src.
+// marks are the offsets within src by offset within the parsed htmlstring, see unmark.
+func (ast *htmlast) relocate(src []byte, marks map[int]int) {
+	if len(marks) == 0 {
+		return
+	}
+	lines := newlinecounter(src)
+	for _, node := range ast.nodes {
+		if node.ick == nil {
+			continue
+		}
+		if at, found := marks[node.ick.at]; found {
+			node.ick.pos = lines.position(at)
+		}
+	}
+}
+
+// linecounter computes positions within a source incrementally, for increasing offsets
+type linecounter struct {
+	src       []byte
+	offset    int
+	line      int
+	linestart int
+}
+
+func newlinecounter(src []byte) *linecounter {
+	return &linecounter{src: src, line: 1}
+}
+
+// position returns the position of offset. Falls back to a full scan if offset is before the previous one.
+func (lc *linecounter) position(offset int) srcpos {
+	if offset < lc.offset {
+		return position(lc.src, offset)
+	}
+	if offset > len(lc.src) {
+		offset = len(lc.src)
+	}
+	for i := lc.offset; i < offset; i++ {
+		if lc.src[i] == '\n' {
+			lc.line++
+			lc.linestart = i + 1
+		}
+	}
+	lc.offset = offset
+	return srcpos{line: lc.line, column: utf8.RuneCount(lc.src[lc.linestart:offset]) + 1}
+}
+
+/******************************************************************************/
+
+// maxASTCACHE is the maximum number of compiled HTMLStrings kept in the shared cache.
+const maxASTCACHE int = 1024
+
+// astcache is the shared cache of compiled HTMLStrings, by content.
+// It allows reusing the compiled form of HTMLStrings built again at every render, like the ones created by a composer's RenderContent.
+// The cache is emptied when it's full.
+var astcache = struct {
+	sync.RWMutex
+	asts map[string]*htmlast
+}{asts: make(map[string]*htmlast)}
+
+// compilehtml returns the compiled form of htmlstring, from the shared cache if it has already been compiled.
+func compilehtml(htmlstring []byte) *htmlast {
+	astcache.RLock()
+	ast, found := astcache.asts[string(htmlstring)]
+	astcache.RUnlock()
+	if found {
+		return ast
+	}
+
+	ast = parsehtml(htmlstring)
+
+	astcache.Lock()
+	if len(astcache.asts) >= maxASTCACHE {
+		astcache.asts = make(map[string]*htmlast)
+	}
+	astcache.asts[string(htmlstring)] = ast
+	astcache.Unlock()
+	return ast
+}
+
+// parsehtml parses htmlstring and returns its compiled form.
+//
+// htmlstring is a string combining usual HTML text and ick-tags. HTML content is kept as text runs without control and without changes.
+// ick-tags are either autoclosing tags or paired tags with an inner content, and should be in the form:
+//
+//	`<ick-{tagname} ...[boolattribute] ...[attribute=[']value[']]/>`
+//	`<ick-{tagname} ...[boolattribute] ...[attribute=[']value[']]>[content]</ick-{tagname}>`
+//
+// The inner content of a paired ick-tag is kept raw, it's compiled when it's rendered.
+//
+// If the HTML string is not well formatted, the syntax error is recorded in the returned ast with the nodes parsed before the error.
+func parsehtml(htmlstring []byte) (ast *htmlast) {
+	ast = &htmlast{nodes: make([]htmlnode, 0)}
+	var err error
+	const (
+		processing_NONE int = iota
+		processing_TXT
+		processing_ICKTAG
+		processing_ANAME
+		processing_AVALUE
+	)
+
+	type stepway struct {
+		processing int // processing operation
+		fieldat    int // starting position of the current processing field
+		fieldto    int // ending position of the current processing field
+	}
+
+	startfield := func(sw *stepway, i int) {
+		sw.fieldat = i
+		sw.fieldto = sw.fieldat
+	}
+	tagat := 0 // position of the ick-tag being processed
+	openick := func(sw *stepway, i int) {
+		tagat = i
+		sw.processing = processing_ICKTAG
+		sw.fieldat = i + 1
+		sw.fieldto = i + 4
+	}
+	closeick := func(sw *stepway, i int) {
+		sw.processing = processing_NONE
+		startfield(sw, i+2)
+	}
+
+	field := func(s stepway) []byte {
+		return htmlstring[s.fieldat:s.fieldto]
+	}
+
+	walk := stepway{processing: processing_NONE}
+	var ickname, aname, avalue string
+	var bquote byte
+	var body []byte
+	attrs := make(AttributeMap, 0)
+
+	// openbody looks for the closing tag of the paired ick-tag opened at i and extracts its inner content.
+	// returns the position of the last byte of the closing tag.
+	openbody := func(i int) (int, error) {
+		bodyto, closeto, err := matchclosingick(htmlstring, i+1, ickname)
+		if err != nil {
+			return i, err
+		}
+		body = htmlstring[i+1 : bodyto]
+		walk.processing = processing_NONE
+		startfield(&walk, closeto+1)
+		return closeto, nil
+	}
+
+	ilast := len(htmlstring) - 1
+	i := 0
+
+	// record the syntax error with its position
+	lines := newlinecounter(htmlstring)
+	defer func() {
+		if err != nil {
+			ast.err = err
+			ast.errname = ickname
+			ast.errpos = lines.position(mini(i, len(htmlstring)))
+		}
+	}()
+
+nextbyte:
+	for ; i <= ilast && err == nil; i++ {
+		b := htmlstring[i]
+		bautoclose_delim := string(htmlstring[i:mini(i+2, ilast+1)]) == "/>"
+		bopen_delim := string(htmlstring[i:mini(i+5, ilast+1)]) == "<ick-"
+		bclose_delim := string(htmlstring[i:mini(i+6, ilast+1)]) == "</ick-"
+
+		// decide what to do according to walk.processing and b value _</>*
+		funfoldick := false
+		switch walk.processing {
+		case processing_NONE:
+			switch {
+			case bopen_delim: // start processing an ick-tage
+				openick(&walk, i)
+				i += 5 - 1
+			case bclose_delim: // closing ick-tag without opening one
+				err = &IckTagNameError{TagName: closingickname(htmlstring[i:]), Message: "closing tag without opening tag"}
+				return ast
+			default: // start processing a text field
+				walk.processing = processing_TXT
+				startfield(&walk, i)
+				if i == ilast { // flush a single byte text field and exit
+					walk.fieldto = ilast + 1
+					ast.addtext(field(walk))
+				}
+			}
+
+		case processing_TXT:
+			switch {
+			case i == ilast: // flush processed text field and exit
+				walk.fieldto = ilast + 1
+				ast.addtext(field(walk))
+			case bopen_delim: // flush processed text field and start processing an ick-tage
+				walk.fieldto = i
+				ast.addtext(field(walk))
+				openick(&walk, i)
+				i += 5 - 1
+			case bclose_delim: // closing ick-tag without opening one
+				err = &IckTagNameError{TagName: closingickname(htmlstring[i:]), Message: "closing tag without opening tag"}
+				return ast
+			default: // extend the text field
+				walk.fieldto = i
+			}
+
+		case processing_ICKTAG:
+			if b == ' ' || b == '>' || bautoclose_delim { // record component tagname
+				walk.fieldto = i
+				ickname = string(field(walk))
+				ickname = strings.ToLower(ickname)
+				if ickname == "ick-" {
+					err = ErrNameMissing
+					return ast
+				}
+				aname = ""
+				avalue = ""
+				body = nil
+				attrs = make(AttributeMap, 0)
+			}
+			switch {
+			case b == ' ': // look for another aname
+				walk.processing = processing_ANAME
+				startfield(&walk, 0)
+			case bautoclose_delim: // process a single ick-component
+				closeick(&walk, i)
+				i += 2 - 1
+				funfoldick = true
+			case b == '>': // process a paired ick-component
+				if i, err = openbody(i); err != nil {
+					return ast
+				}
+				funfoldick = true
+
+			default: // build component ick-tagname
+				r, size := utf8.DecodeRune(htmlstring[i:mini(ilast+1, i+4)])
+				if size != 0 && namingpattern.IsValidNameRune(r, false) {
+					i += size - 1
+					walk.fieldto = i
+				} else {
+					err = &IckTagNameError{TagName: string(htmlstring[walk.fieldat : i+1]), Message: "invalid character found in tagname"}
+					return ast
+				}
+			}
+
+		case processing_ANAME:
+			switch {
+			case (b == ' ' || b == '\n' || b == '\t') && walk.fieldat == 0: // trim left spaces and \n
+				continue nextbyte
+			case (b == ' ' || b == '=' || b == '\n' || b == '\t' || b == '>' || bautoclose_delim) && walk.fieldat > 0: // get and save aname
+				walk.fieldto = i
+				aname = string(field(walk))
+				attrs[aname] = ""
+			}
+
+			switch {
+			case b == ' ': // look for another aname
+				aname = ""
+				walk.processing = processing_ANAME
+				startfield(&walk, 0)
+			case b == '=': // look for a value
+				if aname == "" {
+					err = &IckTagNameError{TagName: ickname, Message: "missing attribute's name before '='"}
+					return ast
+				}
+				walk.processing = processing_AVALUE
+				startfield(&walk, 0)
+				bquote = 0
+			case bautoclose_delim: // process an ick-component
+				closeick(&walk, i)
+				i += 2 - 1
+				funfoldick = true
+			case b == '>': // process a paired ick-component
+				if i, err = openbody(i); err != nil {
+					return ast
+				}
+				funfoldick = true
+
+			default: // build attribute name
+				r, size := utf8.DecodeRune(htmlstring[i:mini(ilast+1, i+4)])
+				if size > 0 && namingpattern.IsValidNameRune(r, walk.fieldat == 0) {
+					if walk.fieldat == 0 {
+						startfield(&walk, i)
+					}
+					i += size - 1
+					walk.fieldto = i
+				} else {
+					err = &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("invalid attribute's name character %q", string(htmlstring[walk.fieldat:i+1]))}
+					return ast
+				}
+			}
+
+		case processing_AVALUE:
+			if bquote == 0 { // don't know yet if a quoted or unquoted value
+				switch {
+				case b == ' ': // trim left spaces
+				case b == '"' || b == '\'': // start a quoted value
+					bquote = b
+					startfield(&walk, i+1)
+				case bautoclose_delim || b == '>': // empty value
+					err = &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("missing attribute's value %q", string(htmlstring[walk.fieldat:i+1]))}
+					return ast
+				default: // start unquoted value
+					bquote = 1
+					startfield(&walk, i)
+				}
+				break
+			}
+
+			switch {
+			case bquote == 1 && (b == ' ' || b == '>' || bautoclose_delim): // process unquoted value
+				walk.fieldto = i
+				avalue = string(field(walk))
+				attrs[aname] = trimfirstvalue(avalue)
+				switch {
+				case bautoclose_delim: // process an ick-tagname
+					closeick(&walk, i)
+					i += 2 - 1
+					funfoldick = true
+				case b == '>': // process a paired ick-tagname
+					if i, err = openbody(i); err != nil {
+						return ast
+					}
+					funfoldick = true
+				default: // look for another aname
+					aname = ""
+					walk.processing = processing_ANAME
+					startfield(&walk, 0)
+				}
+			case bquote != 1 && b == bquote: // process a quoted value
+				walk.fieldto = i
+				avalue = string(field(walk))
+				attrs[aname] = avalue
+				aname = ""
+				walk.processing = processing_ANAME
+				startfield(&walk, 0)
+			default: // extend field value
+				walk.fieldto = i
+			}
+		}
+
+		if funfoldick {
+			ast.addick(ickname, attrs, body, lines.position(tagat), tagat)
+		}
+	}
+
+	// unterminated ick-tag
+	if err == nil && walk.processing != processing_NONE && walk.processing != processing_TXT {
+		if walk.processing == processing_ICKTAG {
+			ickname = string(htmlstring[tagat+1:])
+		}
+		i = tagat
+		err = &IckTagNameError{TagName: ickname, Message: "tag not closed"}
+	}
+	return ast
+}
+
+// renderHTML lookups for ick-tags in the htmlstring and unfold each of them into out.
+// The compiled form of the htmlstring is reused if any, see HTMLString.Compile. See parsehtml for the syntax.
+//
+// The inner content of a paired ick-tag is an HTMLString that can embed other ick-tags. It's handed to the composer, see setslots.
+//
+// If no ick-tags are found, the output is a copy of the htmlstring.
+// An error is returned if the HTML string is not well formatted and the rendering process fails.
+// The rendering process renders an HTML comment in the following cases:
+//   - If the HTML string contains ick-tag but the ick-tagname does not correspond to a Registered composer,
+//   - If the HTML string contains ick-tag with attributes but one value of these attribute is of a bad type,
+func renderHTML(out io.Writer, parent ContentComposer, htmlstr HTMLString) (err error) {
+	ast := htmlstr.ast
+	if ast == nil {
+		ast = compilehtml(htmlstr.bytes)
+	}
+	return renderast(out, parent, ast)
+}
+
+// renderast renders the compiled htmlstring ast into out.
+func renderast(out io.Writer, parent ContentComposer, ast *htmlast) error {
+	nick := 0
+	for _, node := range ast.nodes {
+		if node.ick == nil {
+			if _, err := out.Write(node.text); err != nil {
+				return err
+			}
+			continue
+		}
+		ick := node.ick
+		errunf := unfoldick(parent, out, ick.name, ick.attrs, ick.body, nick, ick.pos)
+		if errunf != nil {
+			errunf = diagnoseError(out, parent, ick.name, ick.pos, errunf)
+			if errors.Is(errunf, ErrTooManyRecursiveRendering) {
+				verbose.Printf(verbose.ALERT, errunf.Error())
+				return errunf
+			}
+			verbose.Printf(verbose.WARNING, errunf.Error())
+		}
+		nick++
+	}
+	return diagnoseError(out, parent, ast.errname, ast.errpos, ast.err)
+}
+
+/******************************************************************************/
+
+// IckTagNode is an ick-tag found in an HTMLString.
+type IckTagNode struct {
+	Name       string       // lowercase ick-tag name
+	Attributes AttributeMap // raw attribute values, as written in the HTMLString
+	Body       *HTMLString  // inner content of a paired ick-tag, nil for an autoclosing ick-tag
+	Line       int          // line of the opening tag within the HTMLString, starting at 1
+	Column     int          // column of the opening tag within the line, in runes starting at 1
+}
+
+// Compile parses the HTMLString once and keeps its compiled form, a list of text runs and ick-tags,
+// to be reused by every following render. Writing to the HTMLString drops the compiled form.
+// Template expressions are compiled too, but they're evaluated and their output is parsed at every render.
+//
+// Compile returns the syntax error of the HTMLString if any.
+// Compiling is optional, an HTMLString that has not been compiled is compiled at every render, using a shared cache.
+func (h *HTMLString) Compile() (err error) {
+	if !h.literal && hasexpression(h.bytes) {
+		tmpl, err := compiletemplate(h.bytes)
+		if err != nil {
+			_, err = locatetemplate(tmpl, err)
+			return err
+		}
+		h.tmpl = tmpl
+	}
+	h.ast = parsehtml(h.bytes)
+	return h.ast.err
+}
+
+// IckTags returns the ick-tags directly used by the HTMLString, in the order they appear.
+// ick-tags used within the inner content of paired ick-tags are available with the Body of the returned nodes.
+// Returns the ick-tags found before the syntax error if any, and the error.
+func (h *HTMLString) IckTags() ([]IckTagNode, error) {
+	ast := h.ast
+	if ast == nil {
+		ast = compilehtml(h.bytes)
+	}
+	ickts := make([]IckTagNode, 0)
+	for _, node := range ast.nodes {
+		if node.ick == nil {
+			continue
+		}
+		n := IckTagNode{Name: node.ick.name, Attributes: node.ick.attrs.Clone(), Line: node.ick.pos.line, Column: node.ick.pos.column}
+		if node.ick.body != nil {
+			n.Body = ToHTML(string(node.ick.body))
+		}
+		ickts = append(ickts, n)
+	}
+	if ast.err != nil {
+		return ickts, fmt.Errorf("%d:%d: %w", ast.errpos.line, ast.errpos.column, ast.err)
+	}
+	return ickts, nil
+}
+
+// UsedIckTagNames returns the sorted list of unique ick-tag names used by the HTMLString, including the ones
+// within the inner content of paired ick-tags, and excluding ick-slot.
+func (h *HTMLString) UsedIckTagNames() ([]string, error) {
+	names := make(map[string]bool)
+	var walk func(h *HTMLString) error
+	walk = func(h *HTMLString) error {
+		ickts, err := h.IckTags()
+		for _, n := range ickts {
+			if n.Name != SLOT_TAGNAME {
+				names[n.Name] = true
+			}
+			if n.Body != nil && bytes.Contains(n.Body.bytes, []byte("<ick-")) {
+				if errb := walk(n.Body); errb != nil && err == nil {
+					err = errb
+				}
+			}
+		}
+		return err
+	}
+	err := walk(h)
+	lst := make([]string, 0, len(names))
+	for n := range names {
+		lst = append(lst, n)
+	}
+	sort.Strings(lst)
+	return lst, err
+}
@@ -0,0 +1,143 @@
+package ickcore
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHTML(t *testing.T) {
+	ast := parsehtml([]byte("<p>a</p>\n<ick-x a=1 b='c d'/>text<ick-y>in <ick-z/></ick-y>"))
+	require.NoError(t, ast.err)
+	require.Len(t, ast.nodes, 4)
+	assert.Equal(t, "<p>a</p>\n", string(ast.nodes[0].text))
+	require.NotNil(t, ast.nodes[1].ick)
+	assert.Equal(t, "ick-x", ast.nodes[1].ick.name)
+	assert.Equal(t, AttributeMap{"a": "1", "b": "c d"}, ast.nodes[1].ick.attrs)
+	assert.Nil(t, ast.nodes[1].ick.body)
+	assert.Equal(t, srcpos{2, 1}, ast.nodes[1].ick.pos)
+	assert.Equal(t, "text", string(ast.nodes[2].text))
+	assert.Equal(t, "ick-y", ast.nodes[3].ick.name)
+	assert.Equal(t, "in <ick-z/>", string(ast.nodes[3].ick.body))
+
+	ast = parsehtml([]byte("<p>a</p>\n <ick-x =1/>"))
+	require.Error(t, ast.err)
+	assert.Equal(t, "ick-x", ast.errname)
+	assert.Equal(t, 2, ast.errpos.line)
+	require.Len(t, ast.nodes, 1)
+	assert.Equal(t, "<p>a</p>\n ", string(ast.nodes[0].text))
+}
+
+func TestIckTags(t *testing.T) {
+	h := ToHTML("<ick-a/>\n<ick-b x=1><ick-slot name=s><ick-c/></ick-slot><ick-a/></ick-b>")
+	ickts, err := h.IckTags()
+	require.NoError(t, err)
+	require.Len(t, ickts, 2)
+	assert.Equal(t, "ick-a", ickts[0].Name)
+	assert.Nil(t, ickts[0].Body)
+	assert.Equal(t, "ick-b", ickts[1].Name)
+	assert.Equal(t, AttributeMap{"x": "1"}, ickts[1].Attributes)
+	assert.Equal(t, 2, ickts[1].Line)
+	assert.Equal(t, 1, ickts[1].Column)
+	require.NotNil(t, ickts[1].Body)
+
+	names, err := h.UsedIckTagNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ick-a", "ick-b", "ick-c"}, names)
+
+	_, err = ToHTML("<ick-a/><ick-b").UsedIckTagNames()
+	assert.Error(t, err)
+}
+
+func TestCompile(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph7", &sniph7{})
+
+	h := ToHTML(`<ick-tstsniph7 key=k1/>`)
+	require.NoError(t, h.Compile())
+	require.NotNil(t, h.ast)
+
+	out := new(bytes.Buffer)
+	for i := 0; i < 2; i++ {
+		out.Reset()
+		require.NoError(t, renderHTML(out, h, *h))
+		assert.Equal(t, `<p name="sniph7" data-key="k1" data-level=3>hello, world</p>`, out.String())
+	}
+
+	// writing drops the compiled form
+	h.Write([]byte("!"))
+	assert.Nil(t, h.ast)
+	out.Reset()
+	require.NoError(t, renderHTML(out, h, *h))
+	assert.Equal(t, `<p name="sniph7" data-key="k1" data-level=3>hello, world</p>!`, out.String())
+
+	assert.Error(t, ToHTML(`<ick-a`).Compile())
+	assert.Error(t, ToHTML(`{{ .A `).Compile())
+}
+
+/******************************************************************************/
+
+var benchhtml = strings.Repeat(`<div class="card"><p>Lorem ipsum dolor sit amet, consectetur adipiscing elit.</p>
+<ick-tstsniph7 key=k1 level=5 lbl='a label' is-hidden/>
+<span>sed do eiusmod tempor incididunt ut labore</span><ick-tstsniph7 key="k2"/></div>
+`, 10)
+
+func benchsetup() {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph7", &sniph7{})
+}
+
+// BenchmarkRenderHTML_Parse parses the HTMLString at every render
+func BenchmarkRenderHTML_Parse(b *testing.B) {
+	benchsetup()
+	h := ToHTML(benchhtml)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		renderast(io.Discard, nil, parsehtml(h.bytes))
+	}
+}
+
+// BenchmarkRenderHTML_SharedCache renders a new HTMLString at every render, compiled once thanks to the shared cache
+func BenchmarkRenderHTML_SharedCache(b *testing.B) {
+	benchsetup()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h := ToHTML(benchhtml)
+		renderHTML(io.Discard, nil, *h)
+	}
+}
+
+// BenchmarkRenderHTML_Compiled renders a compiled HTMLString
+func BenchmarkRenderHTML_Compiled(b *testing.B) {
+	benchsetup()
+	h := ToHTML(benchhtml)
+	h.Compile()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		renderHTML(io.Discard, nil, *h)
+	}
+}
+
+// BenchmarkParseHTML measures the parsing only
+func BenchmarkParseHTML(b *testing.B) {
+	src := []byte(benchhtml)
+	for i := 0; i < b.N; i++ {
+		parsehtml(src)
+	}
+}
+
+// BenchmarkRenderData_Compiled renders a compiled HTMLString with template expressions
+func BenchmarkRenderData_Compiled(b *testing.B) {
+	benchsetup()
+	h := ToHTML(`<ul>{{ range . }}<li>{{ . }}</li><ick-tstsniph7 key="{{ . }}"/>{{ end }}</ul>`)
+	h.Compile()
+	data := []string{"a", "b", "<c>", "d"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RenderData(io.Discard, nil, data, h)
+	}
+}
@@ -1,17 +1,15 @@
 package ickcore
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"reflect"
-	"strconv"
 	"strings"
 	"time"
-	"unicode/utf8"
 
-	"github.com/icecake-framework/icecake/pkg/namingpattern"
 	"github.com/lolorenzo777/verbose"
 )
 
@@ -19,8 +17,11 @@ import (
 // HTMLString implements Elementcomposer interface and the string is rendered to an output stream with the icecake Rendering functions.
 // It is part of the core icecake snippets.
 type HTMLString struct {
-	meta  RMetaData // Rendering MetaData
-	bytes []byte
+	meta    RMetaData // Rendering MetaData
+	bytes   []byte
+	literal bool          // template expressions are not evaluated
+	ast     *htmlast      // compiled form, if compiled
+	tmpl    *datatemplate // compiled template expressions, if compiled
 }
 
 // Ensuring HTMLString implements the right interface
@@ -35,6 +36,18 @@ func ToHTML(s string) *HTMLString {
 	return h
 }
 
+// ToText is the HTMLString factory allowing to convert an untrusted text string into a new HTMLString ready for rendering.
+// If AutoEscape is on, the text is escaped for an element body so ick-tags and HTML markups it may contain are rendered as text.
+// Template expressions the text may contain are never evaluated.
+func ToText(s string) *HTMLString {
+	if AutoEscape {
+		s = EscapeString(ESCCTX_BODY, s)
+	}
+	h := ToHTML(s)
+	h.literal = true
+	return h
+}
+
 // Meta provides a reference to the RenderingMeta object associated with this composer.
 // This is required by the icecake rendering process.
 func (h *HTMLString) RMeta() *RMetaData {
@@ -44,7 +57,30 @@ func (h *HTMLString) RMeta() *RMetaData {
 // RenderContent writes the HTML string corresponding to the content of the HTML element.
 // For an HTMLString snippet, RenderContent renders (unfold and generate HTML output) the internal string without enclosed tag.
 // Use an HTMLSnippet snippet to renders the string inside an enclosed tag.
+//
+// Template expressions are evaluated first if a data context is bound, see RenderData.
 func (h *HTMLString) RenderContent(out io.Writer) error {
+	if data := currentstate(out, h).data; data != nil && !h.literal && hasexpression(h.bytes) {
+		tmpl := h.tmpl
+		if tmpl == nil {
+			var err error
+			if tmpl, err = compiletemplate(h.bytes); err != nil {
+				pos, err := locatetemplate(tmpl, err)
+				return diagnoseError(out, h, "", pos, err)
+			}
+		}
+		b, err := executetemplate(tmpl, data)
+		if err != nil {
+			pos, err := locatetemplate(tmpl, err)
+			return diagnoseError(out, h, "", pos, err)
+		}
+		// the expanded output changes with data, it's not cached.
+		// ick-tags are located within the template source rather than within the expanded output.
+		b, marks := unmark(b)
+		ast := parsehtml(b)
+		ast.relocate(h.bytes, marks)
+		return renderast(out, h, ast)
+	}
 	return renderHTML(out, h, *h)
 }
 
@@ -62,262 +98,92 @@ func (s HTMLString) NeedRendering() bool {
 // Writer interface
 func (s *HTMLString) Write(p []byte) (n int, err error) {
 	s.bytes = append(s.bytes, p...)
+	s.ast = nil
+	s.tmpl = nil
 	return len(p), nil
 }
 
-// RenderHTML lookups for ick-tags in the htmlstring and unfold each of them into out.
-//
-// htmlstring is a string combining usual HTML text and ick-tags. HTML content is transfered to the output without control and without changes.
-// ick-tags are autoclosing tags and should be in the form:
-//
-//	`<ick-{tagname} ...[boolattribute] ...[attribute=[']value[']]/>`
-//
-// If no ick-tags are found, the output is a copy of the htmlstring.
-// An error is returned if the HTML string is not well formatted and the rendering process fails.
-// The rendering process renders an HTML comment in the following cases:
-//   - If the HTML string contains ick-tag but the ick-tagname does not correspond to a Registered composer,
-//   - If the HTML string contains ick-tag with attributes but one value of these attribute is of a bad type,
-func renderHTML(out io.Writer, parent ContentComposer, htmlstr HTMLString) (err error) {
-	const (
-		processing_NONE int = iota
-		processing_TXT
-		processing_ICKTAG
-		processing_ANAME
-		processing_AVALUE
-	)
-
-	type stepway struct {
-		processing int // processing operation
-		fieldat    int // starting position of the current processing field
-		fieldto    int // ending position of the current processing field
-	}
-
-	startfield := func(sw *stepway, i int) {
-		sw.fieldat = i
-		sw.fieldto = sw.fieldat
-	}
-	openick := func(sw *stepway, i int) {
-		sw.processing = processing_ICKTAG
-		sw.fieldat = i + 1
-		sw.fieldto = i + 4
-	}
-	closeick := func(sw *stepway, i int) {
-		sw.processing = processing_NONE
-		startfield(sw, i+2)
-	}
-
-	htmlstring := htmlstr.bytes
-
-	field := func(s stepway) []byte {
-		return htmlstring[s.fieldat:s.fieldto]
-	}
-
-	walk := stepway{processing: processing_NONE}
-	var ickname, aname, avalue string
-	var bquote byte
-	attrs := make(AttributeMap, 0)
-
-	nick := 0
-	ilast := len(htmlstring) - 1
-nextbyte:
-	for i := 0; i <= ilast && err == nil; i++ {
-		b := htmlstring[i]
-		bautoclose_delim := string(htmlstring[i:mini(i+2, ilast+1)]) == "/>"
-		bopen_delim := string(htmlstring[i:mini(i+5, ilast+1)]) == "<ick-"
-
-		// decide what to do according to walk.processing and b value _</>*
-		funfoldick := false
-		switch walk.processing {
-		case processing_NONE:
-			switch {
-			case bopen_delim: // start processing an ick-tage
-				openick(&walk, i)
-				i += 5 - 1
-			default: // start processing a text field
-				walk.processing = processing_TXT
-				startfield(&walk, i)
-			}
-
-		case processing_TXT:
-			switch {
-			case i == ilast: // flush processed text field and exit
-				walk.fieldto = ilast + 1
-				out.Write(field(walk))
-			case bopen_delim: // flush processed text field and start processing an ick-tage
-				walk.fieldto = i
-				out.Write(field(walk))
-				openick(&walk, i)
-				i += 5 - 1
-			default: // extend the text field
-				walk.fieldto = i
-			}
-
-		case processing_ICKTAG:
-			if b == ' ' || bautoclose_delim { // record component tagname
-				walk.fieldto = i
-				ickname = string(field(walk))
-				ickname = strings.ToLower(ickname)
-				if ickname == "ick-" {
-					return ErrNameMissing
-				}
-				aname = ""
-				avalue = ""
-				attrs = make(AttributeMap, 0)
-			}
-			switch {
-			case b == ' ': // look for another aname
-				walk.processing = processing_ANAME
-				startfield(&walk, 0)
-			case bautoclose_delim: // process a single ick-component
-				closeick(&walk, i)
-				i += 2 - 1
-				funfoldick = true
-
-			default: // build component ick-tagname
-				r, size := utf8.DecodeRune(htmlstring[i:mini(ilast+1, i+4)])
-				if size != 0 && namingpattern.IsValidNameRune(r, false) {
-					i += size - 1
-					walk.fieldto = i
-				} else {
-					return &IckTagNameError{TagName: string(htmlstring[walk.fieldat : i+1]), Message: "invalid character found in tagname"}
-				}
-			}
-
-		case processing_ANAME:
-			switch {
-			case (b == ' ' || b == '\n' || b == '\t') && walk.fieldat == 0: // trim left spaces and \n
-				continue nextbyte
-			case (b == ' ' || b == '=' || b == '\n' || b == '\t' || bautoclose_delim) && walk.fieldat > 0: // get and save aname
-				walk.fieldto = i
-				aname = string(field(walk))
-				attrs[aname] = ""
-			}
-
-			switch {
-			case b == ' ': // look for another aname
-				aname = ""
-				walk.processing = processing_ANAME
-				startfield(&walk, 0)
-			case b == '=': // look for a value
-				if aname == "" {
-					return &IckTagNameError{TagName: ickname, Message: "missing attribute's name before '='"}
-				}
-				walk.processing = processing_AVALUE
-				startfield(&walk, 0)
-				bquote = 0
-			case bautoclose_delim: // process an ick-component
-				closeick(&walk, i)
-				i += 2 - 1
-				funfoldick = true
-
-			default: // build attribute name
-				r, size := utf8.DecodeRune(htmlstring[i:mini(ilast+1, i+4)])
-				if size > 0 && namingpattern.IsValidNameRune(r, walk.fieldat == 0) {
-					if walk.fieldat == 0 {
-						startfield(&walk, i)
-					}
-					i += size - 1
-					walk.fieldto = i
-				} else {
-					return &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("invalid attribute's name character %q", string(htmlstring[walk.fieldat:i+1]))}
-				}
-			}
-
-		case processing_AVALUE:
-			if bquote == 0 { // don't know yet if a quoted or unquoted value
-				switch {
-				case b == ' ': // trim left spaces
-				case b == '"' || b == '\'': // start a quoted value
-					bquote = b
-					startfield(&walk, i+1)
-				case bautoclose_delim: // empty value
-					return &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("missing attribute's value %q", string(htmlstring[walk.fieldat:i+1]))}
-				default: // start unquoted value
-					bquote = 1
-					startfield(&walk, i)
-				}
-				break
-			}
-
-			switch {
-			case bquote == 1 && (b == ' ' || bautoclose_delim): // process unquoted value
-				walk.fieldto = i
-				avalue = string(field(walk))
-				attrs[aname] = trimfirstvalue(avalue)
-				switch {
-				case bautoclose_delim: // process an ick-tagname
-					closeick(&walk, i)
-					i += 2 - 1
-					funfoldick = true
-				default: // look for another aname
-					walk.processing = processing_ANAME
-					startfield(&walk, 0)
-				}
-			case bquote != 1 && b == bquote: // process a quoted value
-				walk.fieldto = i
-				avalue = string(field(walk))
-				attrs[aname] = avalue
-				walk.processing = processing_ANAME
-				startfield(&walk, 0)
-			default: // extend field value
-				walk.fieldto = i
-			}
-		}
-
-		if funfoldick {
-			errunf := unfoldick(parent, out, ickname, attrs, nick)
-			if errunf != nil {
-				if errors.Is(errunf, ErrTooManyRecursiveRendering) {
-					verbose.Printf(verbose.ALERT, errunf.Error())
-					return errunf
-				}
-				verbose.Printf(verbose.WARNING, errunf.Error())
-			}
-			nick++
-		}
-	}
-	return err
-}
-
 // unfoldick instanciates and unfolds the ick-component corresponding to ickname.
+// body is the inner content of a paired ick-tag, nil for an autoclosing ick-tag.
 // The rendering process renders an HTML comment and return an error in the following cases:
 //   - If the HTML string contains ick-tag but the ick-tagname does not correspond to a Registered composer,
 //   - If the HTML string contains ick-tag with attributes but one value of these attribute is of a bad type,
-func unfoldick(parent RMetaProvider, out io.Writer, ickname string, ickattrs AttributeMap, seq int) (err error) {
+//   - If the ick-tag has an inner content the composer can't receive.
+//
+// pos is the position of the ick-tag within the HTMLString, used to record warnings in the render report.
+func unfoldick(parent RMetaProvider, out io.Writer, ickname string, ickattrs AttributeMap, body []byte, seq int, pos srcpos) (err error) {
 
 	verbose.Debug("unfolding composer %q", ickname)
 
 	// does this tag refer to a registered component ?
-	regentry := GetRegistryEntry(ickname)
+	reg := currentstate(out, parent).currentregistry()
+	regentry := reg.Entry(ickname)
 	if regentry.Component() != nil {
 
-		// clone the registered snippet (a composer)
-		newref := reflect.New(reflect.TypeOf(regentry.Component()).Elem())
-
-		// init all values with the one in the template
-		// warning: pointers are copied by ref and not by value
-		// newref.Elem().Set(reflect.ValueOf(regentry.Component()).Elem())
-
-		// get the interface of the new snippet
-		newcmp := newref.Interface().(Composer)
+		// clone the registered snippet (a composer), with its generated factory if any
+		var newcmp Composer
+		var applier AttributeApplier
+		hasapplier := false
+		if regentry.factory != nil {
+			newcmp = regentry.factory.NewComposer()
+			// properties are set with the generated ApplyAttributes if any, otherwise with reflection
+			applier, hasapplier = newcmp.(AttributeApplier)
+		} else {
+			newcmp = reflect.New(reflect.TypeOf(regentry.Component()).Elem()).Interface().(Composer)
+		}
+		applied := make(AttributeMap)
 
-		// process unfolded attributes, set value of ickcomponent field when name of attribute matches field name,
+		// process unfolded attributes, set value of ickcomponent field when name of attribute matches a property,
 		// otherwise set unfolded attribute to the attribute of the component.
+		// See ickproperty for the mapping rules.
+		unfolder, hasunfolder := newcmp.(AttributeUnfolder)
+		unfolded := make(map[*ickproperty]bool, len(ickattrs))
 		for ickattname, ickattvalue := range ickattrs {
 			verbose.Debug("unfolding attribute %q", ickattname)
-			_, isCmpProperty := newref.Elem().Type().FieldByName(ickattname)
-			if isCmpProperty {
+			if strings.EqualFold(ickattname, KEY_ATTRIBUTE) {
+				newcmp.RMeta().Key = unbind(ickattvalue, false)
+				continue
+			}
+			if hasunfolder {
+				erru := unfolder.UnfoldAttribute(ickattname, unbind(ickattvalue, false))
+				if erru == nil {
+					// the matching property, if any, is set by the hook: it's neither missing nor defaulted
+					if p := regentry.props.lookup(ickattname); p != nil {
+						unfolded[p] = true
+					}
+					continue
+				}
+				if !errors.Is(erru, ErrAttributeNotUnfolded) {
+					err = &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("%q attribute: %s", ickattname, erru)}
+					break
+				}
+			}
+			if p := regentry.props.lookup(ickattname); p != nil {
+				unfolded[p] = true
+				ickattvalue = unbind(ickattvalue, istextproperty(p.Type))
+				if hasapplier {
+					applied[p.Name] = ickattvalue
+					continue
+				}
 				// feed cmp struct property with the ickattvalue
-				prop := newref.Elem().FieldByName(ickattname)
+				prop := reflect.ValueOf(newcmp).Elem().FieldByIndex(p.index)
 				if erru := updateproperty(prop, ickattvalue); erru != nil {
 					err = &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("%q attribute: %s", ickattname, erru)}
 					break
 				}
 			} else {
+				ickattvalue = unbind(ickattvalue, false)
 				// this attribute is not a field of the componenent
 				// keep it as is unless it is the class attribute, in this case, add the attribute
-				if tagbuilder, isbuilder := newref.Interface().(TagBuilder); isbuilder && tagbuilder != nil {
+				if UnfoldStrict && !isglobalattribute(ickattname) {
+					err = &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("%q attribute: unknown attribute", ickattname)}
+					break
+				}
+				if tagbuilder, isbuilder := newcmp.(TagBuilder); isbuilder && tagbuilder != nil {
 					tagbuilder.SetAttribute(ickattname, ickattvalue)
+					if !isglobalattribute(ickattname) {
+						diagnose(out, parent, Diagnostic{Level: DIAG_INFO, IckTagName: ickname, Line: pos.line, Column: pos.column, Err: fmt.Errorf("%q attribute: not a property, set to the tag", ickattname)})
+					}
 				} else {
 					err = &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("%q attribute: not a component property and not assignable to the composer.", ickattname)}
 					break
@@ -325,6 +191,48 @@ func unfoldick(parent RMetaProvider, out io.Writer, ickname string, ickattrs Att
 			}
 		}
 
+		if err == nil && len(applied) > 0 {
+			if erra := applier.ApplyAttributes(applied); erra != nil {
+				err = &IckTagNameError{TagName: ickname, Message: erra.Error()}
+			}
+		}
+
+		// check required properties and set default values
+		if err == nil && regentry.props != nil {
+			defaults := make(AttributeMap)
+			for _, p := range regentry.props.list {
+				if unfolded[p] {
+					continue
+				}
+				if p.Required {
+					err = &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("%q attribute: required attribute missing", p.Name)}
+					break
+				}
+				if p.HasDefault {
+					if hasapplier {
+						defaults[p.Name] = p.Default
+						continue
+					}
+					if erru := updateproperty(reflect.ValueOf(newcmp).Elem().FieldByIndex(p.index), p.Default); erru != nil {
+						err = &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("%q attribute: default value: %s", p.Name, erru)}
+						break
+					}
+				}
+			}
+			if err == nil && len(defaults) > 0 {
+				if erra := applier.ApplyAttributes(defaults); erra != nil {
+					err = &IckTagNameError{TagName: ickname, Message: "default value: " + erra.Error()}
+				}
+			}
+		}
+
+		// hand the inner content to the composer
+		if err == nil && body != nil {
+			if errs := setslots(reflect.ValueOf(newcmp), regentry.props, body); errs != nil {
+				err = &IckTagNameError{TagName: ickname, Message: errs.Error()}
+			}
+		}
+
 		// render the composer found
 		if err == nil {
 			err = RenderChild(out, parent, newcmp)
@@ -341,11 +249,34 @@ func unfoldick(parent RMetaProvider, out io.Writer, ickname string, ickattrs Att
 	return err
 }
 
+// istextproperty returns true if a property of type typ renders its value verbatim, so data-bound values must be unfolded as text.
+func istextproperty(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ == reflect.TypeOf(HTMLString{}) || typ.Kind() == reflect.Interface
+}
+
 // updateproperty updates prop with the value trying to convert the value to the type of prop.
+// The following types are managed:
+//   - any type implementing encoding.TextUnmarshaler
+//   - HTMLString, time.Duration, url.URL and *url.URL
+//   - string, bool, any int, uint or float kind, including named types like enums
+//   - pointers to a managed type, allocated if nil
+//   - interfaces implemented by *HTMLString, like ContentComposer
+//   - slices, arrays, maps and structs, the value must be a JSON string
+//
 // Returns an error if prop's type is unmannaged and its value can't be extracted.
 func updateproperty(prop reflect.Value, value string) (err error) {
 	typ := prop.Type().String()
 	verbose.Debug("property type:%s value:%v", typ, value)
+
+	if prop.CanAddr() {
+		if tu, ok := prop.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return tu.UnmarshalText([]byte(value))
+		}
+	}
+
 	switch typ {
 	case "ickcore.HTMLString":
 		s := ToHTML(value)
@@ -356,36 +287,62 @@ func updateproperty(prop reflect.Value, value string) (err error) {
 		if err == nil {
 			prop.SetInt(int64(d))
 		}
-	case "*url.URL":
+	case "*url.URL", "url.URL":
 		pu, erru := url.Parse(value)
 		if erru != nil {
 			err = erru
 			break
 		}
-		prop.Set(reflect.ValueOf(pu))
+		if prop.Kind() == reflect.Pointer {
+			prop.Set(reflect.ValueOf(pu))
+		} else {
+			prop.Set(reflect.ValueOf(*pu))
+		}
 
 	default:
 		switch prop.Kind() {
 		case reflect.String:
 			prop.SetString(value)
-		case reflect.Int:
-			var i int
-			i, err = strconv.Atoi(value)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var i int64
+			i, err = UnfoldInt(value, prop.Type().Bits())
 			if err == nil {
-				prop.SetInt(int64(i))
+				prop.SetInt(i)
 			}
-		case reflect.Float64:
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var u uint64
+			u, err = UnfoldUint(value, prop.Type().Bits())
+			if err == nil {
+				prop.SetUint(u)
+			}
+		case reflect.Float32, reflect.Float64:
 			var f float64
-			f, err = strconv.ParseFloat(value, 64)
+			f, err = UnfoldFloat(value, prop.Type().Bits())
 			if err == nil {
 				prop.SetFloat(f)
 			}
 		case reflect.Bool:
-			f := true
-			if s := strings.ToLower(strings.Trim(value, " ")); s == "false" || s == "0" {
-				f = false
+			prop.SetBool(UnfoldBool(value))
+		case reflect.Pointer:
+			pv := reflect.New(prop.Type().Elem())
+			if !prop.IsNil() {
+				pv.Elem().Set(prop.Elem())
+			}
+			if err = updateproperty(pv.Elem(), value); err == nil {
+				prop.Set(pv)
+			}
+		case reflect.Interface:
+			h := ToHTML(value)
+			if !reflect.TypeOf(h).Implements(prop.Type()) {
+				err = fmt.Errorf("unmanaged type %s", prop.Type().String())
+				break
+			}
+			prop.Set(reflect.ValueOf(h))
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+			pv := reflect.New(prop.Type())
+			if err = UnfoldJSON(value, pv.Interface(), prop.Type().String()); err == nil {
+				prop.Set(pv.Elem())
 			}
-			prop.SetBool(f)
 		default:
 			err = fmt.Errorf("unmanaged type %s", prop.Type().String())
 		}
@@ -2,16 +2,28 @@ package ickcore
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
-type Unmanaged struct{}
+type Unmanaged func()
+
+type tstENUM string
+
+type tstLEVEL int
+
+type tstStruct struct {
+	A string
+	B int
+}
 
 type sniph1 struct {
 	BareSnippet
@@ -23,6 +35,17 @@ type sniph1 struct {
 	D    time.Duration
 	U    *url.URL
 	Unmanaged
+	E  tstENUM
+	L  tstLEVEL
+	N8 int8
+	UI uint
+	F3 float32
+	P  *int
+	IP net.IP
+	S  []string
+	M  map[string]int
+	St tstStruct
+	CC ContentComposer
 }
 
 func (s *sniph1) BuildTag() Tag {
@@ -38,6 +61,21 @@ func (s *sniph1) RenderContent(out io.Writer) error {
 	RenderStringIf(s.F != 0, out, fmt.Sprintf("%v", s.F))
 	RenderStringIf(s.D != 0, out, fmt.Sprintf("%v", s.D+(time.Hour*1)))
 	RenderStringIf(s.U != nil, out, fmt.Sprintf("<a href='%v'></a>", s.U))
+	RenderStringIf(s.E != "", out, string(s.E))
+	RenderStringIf(s.L != 0, out, fmt.Sprintf("%v", s.L))
+	RenderStringIf(s.N8 != 0, out, fmt.Sprintf("%v", s.N8))
+	RenderStringIf(s.UI != 0, out, fmt.Sprintf("%v", s.UI))
+	RenderStringIf(s.F3 != 0, out, fmt.Sprintf("%v", s.F3))
+	if s.P != nil {
+		RenderString(out, fmt.Sprintf("%v", *s.P))
+	}
+	RenderStringIf(s.IP != nil, out, s.IP.String())
+	RenderStringIf(s.S != nil, out, fmt.Sprintf("%v", s.S))
+	RenderStringIf(s.M != nil, out, fmt.Sprintf("%v", s.M))
+	RenderStringIf(s.St.A != "", out, fmt.Sprintf("%+v", s.St))
+	if s.CC != nil {
+		RenderChild(out, s, s.CC)
+	}
 	return nil
 }
 
@@ -102,6 +140,121 @@ func TestRenderHTML_1(t *testing.T) {
 	err = renderHTML(out, nil, *ToHTML(`<ick-tstsniph1 U="/icecake.dev"/>`))
 	require.NoError(t, err)
 	require.Equal(t, `<div name="sniph1"><a href='/icecake.dev'></a></div>`, out.String())
+
+	tstset := []struct {
+		in   string
+		want string
+	}{
+		{in: `<ick-tstsniph1 E=enum/>`, want: `<div name="sniph1">enum</div>`},
+		{in: `<ick-tstsniph1 L=3/>`, want: `<div name="sniph1">3</div>`},
+		{in: `<ick-tstsniph1 N8=-8/>`, want: `<div name="sniph1">-8</div>`},
+		{in: `<ick-tstsniph1 N8=300/>`, want: `<!--ick-tstsniph1: "N8" attribute: strconv.ParseInt: parsing "300": value out of range-->`},
+		{in: `<ick-tstsniph1 UI=42/>`, want: `<div name="sniph1">42</div>`},
+		{in: `<ick-tstsniph1 UI=-1/>`, want: `<!--ick-tstsniph1: "UI" attribute: strconv.ParseUint: parsing "-1": invalid syntax-->`},
+		{in: `<ick-tstsniph1 F3=1.5/>`, want: `<div name="sniph1">1.5</div>`},
+		{in: `<ick-tstsniph1 P=7/>`, want: `<div name="sniph1">7</div>`},
+		{in: `<ick-tstsniph1 IP="192.168.0.1"/>`, want: `<div name="sniph1">192.168.0.1</div>`},
+		{in: `<ick-tstsniph1 S='["a","b"]'/>`, want: `<div name="sniph1">[a b]</div>`},
+		{in: `<ick-tstsniph1 M='{"x":1}'/>`, want: `<div name="sniph1">map[x:1]</div>`},
+		{in: `<ick-tstsniph1 St='{"A":"a","B":2}'/>`, want: `<div name="sniph1">{A:a B:2}</div>`},
+		{in: `<ick-tstsniph1 St='a'/>`, want: `<!--ick-tstsniph1: "St" attribute: ickcore.tstStruct: json value expected: invalid character 'a' looking for beginning of value-->`},
+		{in: `<ick-tstsniph1 CC='<b>cc</b>'/>`, want: `<div name="sniph1"><b>cc</b></div>`},
+	}
+	for _, tst := range tstset {
+		out.Reset()
+		err = renderHTML(out, nil, *ToHTML(tst.in))
+		require.NoError(t, err)
+		require.Equal(t, tst.want, out.String(), tst.in)
+	}
+}
+
+type sniph6 struct {
+	BareSnippet
+	Text string
+}
+
+func (s *sniph6) UnfoldAttribute(name string, value string) error {
+	switch name {
+	case "text-content":
+		s.Text = strings.ToUpper(value)
+		return nil
+	case "bad":
+		return errors.New("bad value")
+	}
+	return ErrAttributeNotUnfolded
+}
+
+func (s *sniph6) BuildTag() Tag {
+	s.Tag().SetTagName("p")
+	return *s.Tag()
+}
+
+func (s *sniph6) RenderContent(out io.Writer) error {
+	_, err := RenderString(out, s.Text)
+	return err
+}
+
+func TestUnfoldAttribute(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph6", &sniph6{})
+
+	out := new(bytes.Buffer)
+	err := renderHTML(out, nil, *ToHTML(`<ick-tstsniph6 text-content="hello" a=1/>`))
+	require.NoError(t, err)
+	require.Equal(t, `<p name="sniph6" a=1>HELLO</p>`, out.String())
+
+	out.Reset()
+	err = renderHTML(out, nil, *ToHTML(`<ick-tstsniph6 Text="hello"/>`))
+	require.NoError(t, err)
+	require.Equal(t, `<p name="sniph6">hello</p>`, out.String())
+
+	out.Reset()
+	err = renderHTML(out, nil, *ToHTML(`<ick-tstsniph6 bad/>`))
+	require.NoError(t, err)
+	require.Equal(t, `<!--ick-tstsniph6: "bad" attribute: bad value-->`, out.String())
+}
+
+type sniph6b struct {
+	BareSnippet
+	Level int    `ick:"level,required"`
+	Size  string `ick:",default=normal"`
+}
+
+// UnfoldAttribute parses "h{n}" levels and upper cases sizes
+func (s *sniph6b) UnfoldAttribute(name string, value string) error {
+	switch name {
+	case "level":
+		_, err := fmt.Sscanf(value, "h%d", &s.Level)
+		return err
+	case "size":
+		s.Size = strings.ToUpper(value)
+		return nil
+	}
+	return ErrAttributeNotUnfolded
+}
+
+func (s *sniph6b) RenderContent(out io.Writer) error {
+	_, err := RenderString(out, fmt.Sprintf("%d-%s", s.Level, s.Size))
+	return err
+}
+
+func TestUnfoldAttributeProperty(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph6b", &sniph6b{})
+
+	out := new(bytes.Buffer)
+	err := renderHTML(out, nil, *ToHTML(`<ick-tstsniph6b level="h2" size="big"/>`))
+	require.NoError(t, err)
+	require.Equal(t, `2-BIG`, out.String())
+
+	out.Reset()
+	err = renderHTML(out, nil, *ToHTML(`<ick-tstsniph6b level="h3"/>`))
+	require.NoError(t, err)
+	require.Equal(t, `3-normal`, out.String())
+
+	out.Reset()
+	renderHTML(out, nil, *ToHTML(`<ick-tstsniph6b size="big"/>`))
+	require.Equal(t, `<!--ick-tstsniph6b: "level" attribute: required attribute missing-->`, out.String())
 }
 
 type sniph2 struct {
@@ -131,6 +284,10 @@ func TestRenderHTML_2(t *testing.T) {
 			in:   ``,
 			want: ``,
 			err:  false},
+		{name: "single char",
+			in:   `H`,
+			want: `H`,
+			err:  false},
 		{name: "text",
 			in:   `Hello`,
 			want: `Hello`,
@@ -239,7 +396,11 @@ func TestRenderHTML_2(t *testing.T) {
 			err:  false},
 		{name: "html value",
 			in:   `<ick-tstsniph2 Test=1 a="<ok></>"/>`,
-			want: `<span name="sniph2" a="<ok></>"></span>`,
+			want: `<span name="sniph2" a="&lt;ok&gt;&lt;/&gt;"></span>`,
+			err:  false},
+		{name: "url value",
+			in:   `<ick-tstsniph2 Test=1 href="javascript:alert(1)"/>`,
+			want: `<span name="sniph2" href="#ick-unsafe-url"></span>`,
 			err:  false},
 
 		{name: "text + embedding + text",
@@ -417,3 +578,89 @@ func TestRenderHTML_4(t *testing.T) {
 	err := renderHTML(out, nil, *ToHTML("<ick-tstrecursive/>"))
 	require.Error(t, err)
 }
+
+type sniph5 struct {
+	BareSnippet
+	Title  HTMLString
+	Footer []HTMLString
+	Body   ContentStack
+}
+
+func (s *sniph5) BuildTag() Tag {
+	s.Tag().SetTagName("div")
+	return *s.Tag()
+}
+
+func (s *sniph5) RenderContent(out io.Writer) error {
+	RenderChild(out, s, &s.Title)
+	s.Body.RenderStack(out, s)
+	for _, f := range s.Footer {
+		RenderString(out, "<footer>")
+		RenderChild(out, s, &f)
+		RenderString(out, "</footer>")
+	}
+	return nil
+}
+
+func TestRenderHTML_5(t *testing.T) {
+
+	tstset := []struct {
+		name string
+		in   string
+		want string
+		err  bool
+	}{
+		{name: "empty paired tag",
+			in:   `<ick-tstsniph5></ick-tstsniph5>`,
+			want: `<div name="sniph5"></div>`},
+		{name: "paired tag with attributes",
+			in:   `<ick-tstsniph5 a b=1></ick-tstsniph5>`,
+			want: `<div name="sniph5" a b=1></div>`},
+		{name: "default content",
+			in:   `Hello <ick-tstsniph5 a='x'><p>body</p></ick-tstsniph5> folks`,
+			want: `Hello <div name="sniph5" a="x"><p>body</p></div> folks`},
+		{name: "named slots",
+			in:   `<ick-tstsniph5><ick-slot name="title">T</ick-slot><p>body</p><ick-slot name=footer>F1</ick-slot><ick-slot name="footer">F2</ick-slot></ick-tstsniph5>`,
+			want: `<div name="sniph5">T<p>body</p><footer>F1</footer><footer>F2</footer></div>`},
+		{name: "blank default content",
+			in:   "<ick-tstsniph5>\n  <ick-slot name=\"title\">T</ick-slot>\n</ick-tstsniph5>",
+			want: `<div name="sniph5">T</div>`},
+		{name: "nested ick-tags",
+			in:   `<ick-tstsniph5><ick-tstsniph5 b><ick-slot name="title"><ick-tstsniph5 c/></ick-slot></ick-tstsniph5>!</ick-tstsniph5>`,
+			want: `<div name="sniph5"><div name="sniph5" b><div name="sniph5" c></div></div>!</div>`},
+		{name: "quoted >",
+			in:   `<ick-tstsniph5 a="x>y">z</ick-tstsniph5>`,
+			want: `<div name="sniph5" a="x&gt;y">z</div>`},
+		{name: "unknown slot",
+			in:   `<ick-tstsniph5><ick-slot name="unknown">T</ick-slot></ick-tstsniph5>`,
+			want: `<!--ick-tstsniph5: slot "unknown": no matching property-->`},
+		{name: "content not accepted",
+			in:   `<ick-tstsniph2>content</ick-tstsniph2>`,
+			want: `<!--ick-tstsniph2: composer does not accept content-->`},
+		{name: "closing tag missing",
+			in:  `<ick-tstsniph5><p>body</p>`,
+			err: true},
+		{name: "closing tag mismatch",
+			in:  `<ick-tstsniph5><p>body</p></ick-tstsniph2>`,
+			err: true},
+		{name: "closing tag alone",
+			in:  `body</ick-tstsniph5>`,
+			err: true},
+	}
+
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph2", &sniph2{})
+	AddRegistryEntry("ick-tstsniph5", &sniph5{})
+
+	out := new(bytes.Buffer)
+	for _, tst := range tstset {
+		out.Reset()
+		err := renderHTML(out, nil, *ToHTML(tst.in))
+		if tst.err {
+			require.Error(t, err, tst.name)
+		} else {
+			require.NoError(t, err, tst.name)
+			require.Equal(t, tst.want, out.String(), tst.name)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package ickcore
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// UnfoldStrict turns on the strict unfolding mode, off by default.
+//
+// In strict mode an ick-tag attribute that does not match any composer's property is an error,
+// unless it's a global HTML attribute like id, class, style, title or any data-* and aria-* attributes.
+// Otherwise unknown attributes are set to the tag of the composer.
+var UnfoldStrict bool = false
+
+// KEY_ATTRIBUTE is the special ick-tag attribute setting the Key of the unfolded composer, see RMetaData.GenerateVirtualId.
+//
+//	<ick-button ick-key="save"/>
+const KEY_ATTRIBUTE string = "ick-key"
+
+// KEY_DATA_ATTRIBUTE is the attribute rendered in the tag of a composer with a Key, so its element can be found in the DOM.
+const KEY_DATA_ATTRIBUTE string = "data-ick-key"
+
+// ickproperty describes how an ick-tag attribute maps to a composer's field.
+//
+// The mapping is defined with the `ick` struct tag:
+//
+//	Field TYPE `ick:"[name][,required][,default=value]"`
+//
+// If the name is empty, the attribute's name is the kebab-case of the field's name, `IsHidden` gives `is-hidden`.
+// If the kebab-case is a global HTML attribute, like title or lang, the attribute is set to the tag and the attribute's name is the exact field's name, like `Title="..."`.
+// The ick struct tag can name the attribute explicitly after a global HTML attribute.
+// A field with the `ick:"-"` tag is ignored.
+// The default value is unfolded like any attribute's value when the attribute is missing, so it must be the last option.
+type ickproperty struct {
+	Name       string       // attribute's name, lowercase unless it's the exact field's name
+	Field      string       // go field's name
+	Required   bool         // the attribute must be provided
+	Default    string       // default attribute's value, if HasDefault
+	HasDefault bool         // a default value is provided
+	Type       reflect.Type // type of the field
+
+	index []int // index sequence of the field for FieldByIndex
+}
+
+// ickproperties is the list of properties of a composer type.
+type ickproperties struct {
+	list    []*ickproperty          // properties in the declaration order
+	byname  map[string]*ickproperty // properties by attribute's name
+	byfield map[string]*ickproperty // properties by field's name
+}
+
+// parseproperties computes the properties of typ, a composer type.
+// Exported fields of embedded structs are processed like the fields of the outer struct.
+// Returns an empty list if typ is not a struct or a pointer to a struct.
+func parseproperties(typ reflect.Type) *ickproperties {
+	props := &ickproperties{
+		list:    make([]*ickproperty, 0),
+		byname:  make(map[string]*ickproperty),
+		byfield: make(map[string]*ickproperty),
+	}
+	if typ == nil {
+		return props
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Struct {
+		props.parsestruct(typ, nil)
+	}
+	return props
+}
+
+func (props *ickproperties) parsestruct(typ reflect.Type, index []int) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, hastag := sf.Tag.Lookup("ick")
+		if tag == "-" {
+			continue
+		}
+
+		fidx := make([]int, len(index), len(index)+1)
+		copy(fidx, index)
+		fidx = append(fidx, i)
+
+		if sf.Anonymous && !hastag {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				// embedded pointers may be nil, they're not unfolded
+				continue
+			}
+			if ft.Kind() == reflect.Struct {
+				props.parsestruct(ft, fidx)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		// the shallower field hides the deeper one
+		exist, found := props.byfield[sf.Name]
+		if found && len(exist.index) <= len(fidx) {
+			continue
+		}
+
+		p := &ickproperty{Field: sf.Name, Type: sf.Type, index: fidx}
+		p.Name, p.Required, p.Default, p.HasDefault = ParsePropertyTag(sf.Name, tag)
+		if tagname, _, _ := strings.Cut(tag, ","); strings.TrimSpace(tagname) == "" && isglobalattribute(p.Name) {
+			// the global attribute is left to the tag
+			p.Name = sf.Name
+		}
+
+		if found {
+			props.remove(exist)
+		}
+		props.list = append(props.list, p)
+		props.byfield[p.Field] = p
+		if _, found := props.byname[p.Name]; !found && p.Name != p.Field {
+			props.byname[p.Name] = p
+		}
+	}
+}
+
+// ParsePropertyTag parses the `ick` struct tag of the field, see ickproperty.
+// Returns the attribute's name, the kebab-case of the field's name if the tag does not name it, and the options.
+func ParsePropertyTag(field string, tag string) (name string, required bool, dft string, hasdefault bool) {
+	name, opts, _ := strings.Cut(tag, ",")
+	for opts != "" {
+		var opt string
+		if strings.HasPrefix(opts, "default=") {
+			dft = strings.TrimPrefix(opts, "default=")
+			hasdefault = true
+			break
+		}
+		opt, opts, _ = strings.Cut(opts, ",")
+		if strings.TrimSpace(opt) == "required" {
+			required = true
+		}
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = KebabCase(field)
+	}
+	return name, required, dft, hasdefault
+}
+
+// remove removes p from the list of properties
+func (props *ickproperties) remove(p *ickproperty) {
+	for i, lp := range props.list {
+		if lp == p {
+			props.list = append(props.list[:i], props.list[i+1:]...)
+			break
+		}
+	}
+	delete(props.byfield, p.Field)
+	if props.byname[p.Name] == p {
+		delete(props.byname, p.Name)
+	}
+}
+
+// lookup returns the property corresponding to the attribute's name.
+// The exact go field's name is looked up first, then the attribute's name, case insensitive.
+// Returns nil if not found.
+func (props *ickproperties) lookup(aname string) *ickproperty {
+	if props == nil {
+		return nil
+	}
+	if p, found := props.byfield[aname]; found {
+		return p
+	}
+	return props.byname[strings.ToLower(aname)]
+}
+
+// KebabCase returns the kebab-case of a go name. Words are split on case changes and underscores.
+//
+//	`IsHidden` gives `is-hidden`, `CWidth` gives `c-width`, `HTMLString` gives `html-string`, `TAGLABEL_SIZE` gives `taglabel-size`.
+func KebabCase(name string) string {
+	rs := []rune(name)
+	var b strings.Builder
+	for i, r := range rs {
+		if r == '_' || r == '-' {
+			if b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && rs[i-1] != '_' && rs[i-1] != '-' {
+			prevlower := unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1])
+			nextlower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if prevlower || (unicode.IsUpper(rs[i-1]) && nextlower) {
+				b.WriteRune('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// isglobalattribute returns true if aname is a global HTML attribute, accepted on any tag in strict unfolding mode.
+func isglobalattribute(aname string) bool {
+	aname = strings.ToLower(aname)
+	if strings.HasPrefix(aname, "data-") || strings.HasPrefix(aname, "aria-") {
+		return true
+	}
+	switch aname {
+	case "id", "class", "style", "title", "tabindex", "hidden", "role", "lang", "dir", "accesskey", "draggable", "contenteditable", "spellcheck", "translate":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package ickcore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKebabCase(t *testing.T) {
+	assert.Equal(t, "is-hidden", KebabCase("IsHidden"))
+	assert.Equal(t, "c-width", KebabCase("CWidth"))
+	assert.Equal(t, "h-ref", KebabCase("HRef"))
+	assert.Equal(t, "html-string", KebabCase("HTMLString"))
+	assert.Equal(t, "taglabel-size", KebabCase("TAGLABEL_SIZE"))
+	assert.Equal(t, "color", KebabCase("COLOR"))
+	assert.Equal(t, "target-id", KebabCase("TargetId"))
+	assert.Equal(t, "n8", KebabCase("N8"))
+	assert.Equal(t, "level2-name", KebabCase("Level2Name"))
+}
+
+type sniph7embed struct {
+	Shadowed string
+	Deep     string
+}
+
+type sniph7 struct {
+	BareSnippet
+	sniph7embed
+	Key      string `ick:"key,required"`
+	Level    int    `ick:",default=3"`
+	Label    string `ick:"lbl,default=hello, world"`
+	Ignored  string `ick:"-"`
+	IsHidden bool
+	Shadowed int
+	private  string
+}
+
+func (s *sniph7) BuildTag() Tag {
+	s.Tag().SetTagName("p").SetAttribute("data-key", s.Key).SetAttribute("data-level", strconv.Itoa(s.Level))
+	s.Tag().SetAttributeIf(s.IsHidden, "data-hidden", "").SetAttributeIf(s.Deep != "", "data-deep", s.Deep)
+	return *s.Tag()
+}
+
+func (s *sniph7) RenderContent(out io.Writer) error {
+	_, err := RenderString(out, s.Label)
+	return err
+}
+
+func TestParseProperties(t *testing.T) {
+	props := parseproperties(reflect.TypeOf(&sniph7{}))
+
+	names := make([]string, 0)
+	for _, p := range props.list {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"deep", "key", "level", "lbl", "is-hidden", "shadowed"}, names)
+
+	p := props.lookup("Key")
+	require.NotNil(t, p)
+	assert.True(t, p.Required)
+
+	p = props.lookup("LEVEL")
+	require.NotNil(t, p)
+	assert.Equal(t, "3", p.Default)
+
+	p = props.lookup("lbl")
+	require.NotNil(t, p)
+	assert.Equal(t, "hello, world", p.Default)
+	assert.Equal(t, props.lookup("Label"), p)
+
+	p = props.lookup("shadowed")
+	require.NotNil(t, p)
+	assert.Equal(t, reflect.TypeOf(0), p.Type)
+
+	assert.Nil(t, props.lookup("ignored"))
+	assert.Nil(t, props.lookup("private"))
+	assert.Nil(t, props.lookup("tag"))
+	assert.NotNil(t, props.lookup("is-hidden"))
+	assert.NotNil(t, props.lookup("IsHidden"))
+}
+
+func TestUnfoldProperties(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph7", &sniph7{})
+
+	tstset := []struct {
+		in   string
+		want string
+	}{
+		{in: `<ick-tstsniph7 key=k1/>`, want: `<p name="sniph7" data-key="k1" data-level=3>hello, world</p>`},
+		{in: `<ick-tstsniph7 key=k1 level=5 lbl='x' is-hidden deep=d/>`, want: `<p name="sniph7" data-deep="d" data-hidden data-key="k1" data-level=5>x</p>`},
+		{in: `<ick-tstsniph7 Key=k1 Label='y'/>`, want: `<p name="sniph7" data-key="k1" data-level=3>y</p>`},
+		{in: `<ick-tstsniph7/>`, want: `<!--ick-tstsniph7: "key" attribute: required attribute missing-->`},
+		{in: `<ick-tstsniph7 key=k1 level=x/>`, want: `<!--ick-tstsniph7: "level" attribute: strconv.ParseInt: parsing "x": invalid syntax-->`},
+		{in: `<ick-tstsniph7 key=k1 unknown=1/>`, want: `<p name="sniph7" data-key="k1" data-level=3 unknown=1>hello, world</p>`},
+	}
+
+	AutoEscape = false
+	defer func() { AutoEscape = true }()
+	out := new(bytes.Buffer)
+	for _, tst := range tstset {
+		out.Reset()
+		err := renderHTML(out, nil, *ToHTML(tst.in))
+		require.NoError(t, err)
+		require.Equal(t, tst.want, out.String(), tst.in)
+	}
+
+	UnfoldStrict = true
+	defer func() { UnfoldStrict = false }()
+
+	out.Reset()
+	err := renderHTML(out, nil, *ToHTML(`<ick-tstsniph7 key=k1 unknown=1/>`))
+	require.NoError(t, err)
+	require.Equal(t, `<!--ick-tstsniph7: "unknown" attribute: unknown attribute-->`, out.String())
+
+	out.Reset()
+	err = renderHTML(out, nil, *ToHTML(`<ick-tstsniph7 key=k1 id=x class=c data-x=1/>`))
+	require.NoError(t, err)
+	require.Equal(t, `<p id="x" name="sniph7" class="c" data-key="k1" data-level=3 data-x=1>hello, world</p>`, out.String())
+}
+
+type sniph12 struct {
+	BareSnippet
+	Title string
+	Lang  string `ick:"lang"`
+}
+
+func (s *sniph12) BuildTag() Tag {
+	s.Tag().SetTagName("p")
+	return *s.Tag()
+}
+
+func (s *sniph12) RenderContent(out io.Writer) error {
+	_, err := RenderString(out, s.Title, " ", s.Lang)
+	return err
+}
+
+func TestUnfoldGlobalAttributes(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph12", &sniph12{})
+
+	// a global attribute is left to the tag, unless the ick struct tag names it explicitly
+	props := parseproperties(reflect.TypeOf(&sniph12{}))
+	assert.Nil(t, props.lookup("title"))
+	require.NotNil(t, props.lookup("Title"))
+	assert.Equal(t, "Title", props.lookup("Title").Name)
+	assert.NotNil(t, props.lookup("lang"))
+
+	out := new(bytes.Buffer)
+	err := renderHTML(out, nil, *ToHTML(`<ick-tstsniph12 title="tip" Title="text" lang="fr"/>`))
+	require.NoError(t, err)
+	assert.Equal(t, `<p name="sniph12" title="tip">text fr</p>`, out.String())
+}
+
+// sniph7gen is sniph7 with the factory and the ApplyAttributes method generated by icecake gen.
+type sniph7gen struct{ sniph7 }
+
+var sniph7gennew int
+
+func (*sniph7gen) NewComposer() Composer {
+	sniph7gennew++
+	return new(sniph7gen)
+}
+
+func (cmp *sniph7gen) ApplyAttributes(attrs AttributeMap) (err error) {
+	for name, value := range attrs {
+		switch strings.ToLower(name) {
+		case "deep":
+			cmp.sniph7.sniph7embed.Deep = value
+		case "key":
+			cmp.sniph7.Key = value
+		case "level":
+			var i int64
+			i, err = UnfoldInt(value, 0)
+			cmp.sniph7.Level = int(i)
+		case "lbl":
+			cmp.sniph7.Label = value
+		case "is-hidden":
+			cmp.sniph7.IsHidden = UnfoldBool(value)
+		case "shadowed":
+			var i int64
+			i, err = UnfoldInt(value, 0)
+			cmp.sniph7.Shadowed = int(i)
+		default:
+			err = ErrAttributeNotUnfolded
+		}
+		if err != nil {
+			return fmt.Errorf("%q attribute: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// sniph7genembed embeds sniph7gen, the promoted factory is ignored.
+type sniph7genembed struct{ sniph7gen }
+
+func TestUnfoldGenerated(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph7gen", &sniph7gen{})
+
+	tstset := []struct {
+		in   string
+		want string
+	}{
+		{in: `<ick-tstsniph7gen key=k1/>`, want: `<p name="sniph7gen" data-key="k1" data-level=3>hello, world</p>`},
+		{in: `<ick-tstsniph7gen key=k1 level=5 lbl='x' is-hidden deep=d/>`, want: `<p name="sniph7gen" data-deep="d" data-hidden data-key="k1" data-level=5>x</p>`},
+		{in: `<ick-tstsniph7gen Key=k1 Label='y'/>`, want: `<p name="sniph7gen" data-key="k1" data-level=3>y</p>`},
+		{in: `<ick-tstsniph7gen/>`, want: `<!--ick-tstsniph7gen: "key" attribute: required attribute missing-->`},
+		{in: `<ick-tstsniph7gen key=k1 level=x/>`, want: `<!--ick-tstsniph7gen: "level" attribute: strconv.ParseInt: parsing "x": invalid syntax-->`},
+		{in: `<ick-tstsniph7gen key=k1 unknown=1/>`, want: `<p name="sniph7gen" data-key="k1" data-level=3 unknown=1>hello, world</p>`},
+	}
+
+	AutoEscape = false
+	defer func() { AutoEscape = true }()
+	sniph7gennew = 0
+	out := new(bytes.Buffer)
+	for _, tst := range tstset {
+		out.Reset()
+		err := renderHTML(out, nil, *ToHTML(tst.in))
+		require.NoError(t, err)
+		require.Equal(t, tst.want, out.String(), tst.in)
+	}
+	assert.Equal(t, len(tstset), sniph7gennew)
+
+	// promoted factory
+	AddRegistryEntry("ick-tstsniph7genembed", &sniph7genembed{})
+	sniph7gennew = 0
+	out.Reset()
+	err := renderHTML(out, nil, *ToHTML(`<ick-tstsniph7genembed key=k1 level=5/>`))
+	require.NoError(t, err)
+	assert.Equal(t, `<p name="sniph7genembed" data-key="k1" data-level=5>hello, world</p>`, out.String())
+	assert.Equal(t, 0, sniph7gennew)
+}
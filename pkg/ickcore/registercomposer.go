@@ -21,6 +21,11 @@ import (
 //   - If the ickname does not meet the pattern "ick-*"
 //   - If the composer does not implement the ElementComposer interface
 func RegisterComposer(icktagname string, composer any) (entry *RegistryEntry, err error) {
+	return DefaultRegistry.Register(icktagname, composer)
+}
+
+// Register registers a composer with the unique icktagname in the registry. See RegisterComposer.
+func (reg *Registry) Register(icktagname string, composer any) (entry *RegistryEntry, err error) {
 	// TODO: register - RegisterComposer should generate ickname automatically (see RenderSnippet), unless a same component can be registered with 2 names ?!
 
 	// register by reference
@@ -55,13 +60,13 @@ func RegisterComposer(icktagname string, composer any) (entry *RegistryEntry, er
 	}
 
 	// already registeredwith anoher
-	if IsRegistered(icktagname) && reflect.TypeOf(GetRegistryEntry(icktagname).Component()).String() != typ.String() {
+	if reg.IsRegistered(icktagname) && reflect.TypeOf(reg.Entry(icktagname).Component()).String() != typ.String() {
 		err = fmt.Errorf("RegisterComposer: %s(%v) warning: already registered with another composer", icktagname, typ.String())
 		log.Println(err.Error())
 		return nil, err
 	}
 
-	entry = AddRegistryEntry(icktagname, composer)
+	entry = reg.AddEntry(icktagname, composer)
 
 	verbose.Debug("RegisterComposer: %s(%v) with success", icktagname, typ.String())
 
@@ -1,6 +1,7 @@
 package ickcore
 
 import (
+	"io"
 	"reflect"
 	"strconv"
 	"sync"
@@ -8,20 +9,24 @@ import (
 	"github.com/icecake-framework/icecake/internal/helper"
 )
 
-// The registry is the global Registry instantiated once and used by the HtmlSnippet and other components.
-var theRegistry registry
+// DefaultRegistry is the global Registry instantiated once and used by the package level registry functions,
+// and by the rendering process when no other registry is provided.
+var DefaultRegistry = NewRegistry()
 
 // RegistryEntry defines a component
 type RegistryEntry struct {
 	mu         sync.Mutex
-	icktagname string // unique name of the component
-	cmp        any    // The component type that must be instantiated. This must be a reference.
-	count      int    // number of time this cmp has already been instantiated
+	icktagname string          // unique name of the component
+	cmp        any             // The component type that must be instantiated. This must be a reference.
+	count      int             // number of time this cmp has already been instantiated
+	props      *ickproperties  // mapping of the ick-tag attributes to the cmp fields, computed once when registering
+	factory    ComposerFactory // generated factory of cmp, nil if missing or promoted from an embedded composer
 
 	// csslinkref     []string // slice of required stylesheet link ref for this component. will be added once into the head of the page
 	// csslinkmounted bool
 }
 
+// Count increments and returns the instantiation counter of the entry.
 func (_r *RegistryEntry) Count() int {
 	_r.mu.Lock()
 	_r.count++
@@ -32,79 +37,129 @@ func (_r *RegistryEntry) Count() int {
 
 // Name returns the unique name of the component
 // starting with the `ick-` prefix.
-func (_r RegistryEntry) IckTagName() string {
+func (_r *RegistryEntry) IckTagName() string {
 	return _r.icktagname
 }
 
 // Component returns the component type that must be instantiated
-func (_r RegistryEntry) Component() any {
+func (_r *RegistryEntry) Component() any {
 	return _r.cmp
 }
 
-// IsRegistered returns if the _name has already been regystered
-func IsRegistered(_name string) bool {
-	theRegistry.init()
-	_, found := theRegistry.entries[_name]
-	return found
+/******************************************************************************/
+
+// Registry stores definition of components in a map, by unique name, and the counters used to generate unique ids.
+//
+// A Registry is safe for concurrent use. Use a dedicated registry to render pages concurrently,
+// or to get deterministic ids per page, see Registry.Clone and Registry.Render.
+type Registry struct {
+	mu       sync.RWMutex
+	entries  map[string]*RegistryEntry
+	counters map[string]int // unique id counters, by prefix
 }
 
-func (_reg *registry) init() {
-	if _reg.entries == nil {
-		_reg.entries = make(map[string]*RegistryEntry, 0)
+// NewRegistry returns a new empty registry.
+func NewRegistry() *Registry {
+	reg := new(Registry)
+	reg.entries = make(map[string]*RegistryEntry, 0)
+	reg.counters = make(map[string]int, 0)
+	return reg
+}
+
+// Clone returns a new registry with the same entries, and with its own unique id counters starting from zero.
+func (reg *Registry) Clone() *Registry {
+	clone := NewRegistry()
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	for k, v := range reg.entries {
+		clone.entries[k] = v
 	}
+	return clone
 }
 
-// Registry stores definition of components in a map, by unique name
-type registry struct {
-	entries map[string]*RegistryEntry
+// Render renders composers like RenderChild, unfolding ick-tags and generating unique ids with this registry.
+// The registry is inherited by the composers rendered as children.
+func (reg *Registry) Render(out io.Writer, parent RMetaProvider, child Composer, siblings ...Composer) error {
+	err := render(out, parent, child, nil, reg)
+	if err != nil {
+		return err
+	}
+	for _, s := range siblings {
+		err := render(out, parent, s, nil, reg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func Map() map[string]*RegistryEntry {
-	theRegistry.init()
-	return theRegistry.entries
+// IsRegistered returns if the name has already been registered
+func (reg *Registry) IsRegistered(name string) bool {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	_, found := reg.entries[name]
+	return found
 }
 
-// AddRegistryEntry create a new RegistryEntry and add it to the global and private registry.
+// Map returns a copy of the map of the registry entries, by ick-tag name.
+func (reg *Registry) Map() map[string]*RegistryEntry {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	m := make(map[string]*RegistryEntry, len(reg.entries))
+	for k, v := range reg.entries {
+		m[k] = v
+	}
+	return m
+}
+
+// AddEntry create a new RegistryEntry and add it to the registry.
 // No check is done on name. If name is already registered a new registryentry overwrites the existing one.
-// css is optional and can be nil.
-func AddRegistryEntry(name string, cmp any) *RegistryEntry {
-	theRegistry.init()
+func (reg *Registry) AddEntry(name string, cmp any) *RegistryEntry {
 	name = helper.Normalize(name)
 
-	entry := RegistryEntry{
+	entry := &RegistryEntry{
 		icktagname: name,
 		cmp:        cmp,
 		count:      0,
+		props:      parseproperties(reflect.TypeOf(cmp)),
+	}
+	if factory, ok := cmp.(ComposerFactory); ok && reflect.TypeOf(factory.NewComposer()) == reflect.TypeOf(cmp) {
+		entry.factory = factory
 	}
-	theRegistry.entries[name] = &entry
-	return &entry
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.entries[name] = entry
+	return entry
 }
 
-// GetRegistryEntry returns the RegistryEntry corresponding to the _name.
-// If _name is empty GetRegistryEntry returns the RegistryEntry for "ick".
-// If _name does not correspond to an existing entry in the resitry, then
-// GetRegistryEntry create a default entry in the registry with that name.
-// Also GetRegistryEntry always returns a RegistryEntry.
-func GetRegistryEntry(name string) *RegistryEntry {
-	theRegistry.init()
+// Entry returns the RegistryEntry corresponding to the name.
+// If name is empty Entry returns the RegistryEntry for "ick".
+// If name does not correspond to an existing entry in the registry, then Entry returns a default entry with that name.
+// The default entry is not added to the registry.
+// Also Entry always returns a RegistryEntry.
+func (reg *Registry) Entry(name string) *RegistryEntry {
 	name = helper.Normalize(name)
 	if name == "" {
 		name = "ick"
 	}
-	regentry, found := theRegistry.entries[name]
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	regentry, found := reg.entries[name]
 	if !found {
 		regentry = &RegistryEntry{icktagname: name}
 	}
 	return regentry
 }
 
-// LookupRegistryEntry lookup for _cmp in the registry and returns the first RegistryEntry with matching type.
-// _cmp must be a pointer, like it was registered with AddRegistryEntry.
+// Lookup lookups for cmp in the registry and returns the first RegistryEntry with matching type.
+// cmp must be a pointer, like it was registered with AddEntry.
 // Return nil if nothing is found.
-func LookupRegistryEntry(cmp any) *RegistryEntry {
-	theRegistry.init()
+func (reg *Registry) Lookup(cmp any) *RegistryEntry {
 	typ := reflect.TypeOf(cmp)
-	for _, v := range theRegistry.entries {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	for _, v := range reg.entries {
 		tv := reflect.TypeOf(v.cmp)
 		if tv == typ {
 			return v
@@ -113,18 +168,71 @@ func LookupRegistryEntry(cmp any) *RegistryEntry {
 	return nil
 }
 
-// GetUniqueId returns a unique id starting with prefix.
+// UniqueId returns a unique id within the registry, starting with prefix.
+// if prefix is empty "ick-" is used to prefix the returned id.
+// The returned id is always lowercase.
+func (reg *Registry) UniqueId(prefix string) (idx int, uid string) {
+	prefix = helper.Normalize(prefix)
+	if prefix == "" {
+		prefix = "ick"
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if reg.counters == nil {
+		reg.counters = make(map[string]int, 1)
+	}
+	reg.counters[prefix]++
+	idx = reg.counters[prefix]
+	return idx, prefix + "-" + strconv.Itoa(idx)
+}
+
+// Reset removes all entries and unique id counters.
+func (reg *Registry) Reset() {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.entries = make(map[string]*RegistryEntry, 1)
+	reg.counters = make(map[string]int, 1)
+}
+
+/******************************************************************************/
+
+// IsRegistered returns if the _name has already been registered in the DefaultRegistry
+func IsRegistered(_name string) bool {
+	return DefaultRegistry.IsRegistered(_name)
+}
+
+// Map returns a copy of the map of the DefaultRegistry entries.
+func Map() map[string]*RegistryEntry {
+	return DefaultRegistry.Map()
+}
+
+// AddRegistryEntry create a new RegistryEntry and add it to the DefaultRegistry.
+// No check is done on name. If name is already registered a new registryentry overwrites the existing one.
+func AddRegistryEntry(name string, cmp any) *RegistryEntry {
+	return DefaultRegistry.AddEntry(name, cmp)
+}
+
+// GetRegistryEntry returns the RegistryEntry of the DefaultRegistry corresponding to the _name.
+// See Registry.Entry.
+func GetRegistryEntry(name string) *RegistryEntry {
+	return DefaultRegistry.Entry(name)
+}
+
+// LookupRegistryEntry lookup for _cmp in the DefaultRegistry and returns the first RegistryEntry with matching type.
+// See Registry.Lookup.
+func LookupRegistryEntry(cmp any) *RegistryEntry {
+	return DefaultRegistry.Lookup(cmp)
+}
+
+// GetUniqueId returns a unique id starting with prefix, generated with the DefaultRegistry.
 // if prefix is empty "ick-" is used to prefix the returned id.
 // The returned id is always lowercase.
 // GetUniqueId is thread safe.
 func GetUniqueId(prefix string) (idx int, uid string) {
-	regentry := GetRegistryEntry(prefix)
-	idx = regentry.Count()
-	theRegistry.entries[regentry.icktagname] = regentry
-	return idx, regentry.icktagname + "-" + strconv.Itoa(idx)
+	return DefaultRegistry.UniqueId(prefix)
 }
 
-// ResetRegistry is only used for testing
+// ResetRegistry resets the DefaultRegistry. It's only used for testing.
 func ResetRegistry() {
-	theRegistry.entries = make(map[string]*RegistryEntry, 1)
+	DefaultRegistry.Reset()
 }
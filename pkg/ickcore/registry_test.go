@@ -1,9 +1,13 @@
 package ickcore
 
 import (
+	"bytes"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegistry(t *testing.T) {
@@ -12,8 +16,67 @@ func TestRegistry(t *testing.T) {
 
 	e := GetRegistryEntry("ickTEST")
 	assert.NotNil(t, e)
+	assert.False(t, IsRegistered("icktest"))
 
 	_, id := GetUniqueId("ickTEST")
 	assert.Equal(t, "icktest-1", id)
-	assert.Equal(t, 1, theRegistry.entries["icktest"].count)
+	assert.Equal(t, 1, DefaultRegistry.counters["icktest"])
+	assert.False(t, IsRegistered("icktest"))
+}
+
+func TestRegistryInstance(t *testing.T) {
+
+	reg := NewRegistry()
+	_, err := reg.Register("ick-tstsniph7", &sniph7{})
+	require.NoError(t, err)
+	assert.True(t, reg.IsRegistered("ick-tstsniph7"))
+	assert.NotNil(t, reg.Lookup(&sniph7{}))
+	assert.Len(t, reg.Map(), 1)
+
+	clone := reg.Clone()
+	assert.True(t, clone.IsRegistered("ick-tstsniph7"))
+	_, id := reg.UniqueId("x")
+	assert.Equal(t, "x-1", id)
+	_, id = clone.UniqueId("x")
+	assert.Equal(t, "x-1", id)
+
+	// the ick-tag is unfolded with the registry passed to the render call
+	ResetRegistry()
+	out := new(bytes.Buffer)
+	err = reg.Render(out, nil, ToHTML(`<ick-tstsniph7 key=k1/>`))
+	require.NoError(t, err)
+	assert.Equal(t, `<p name="sniph7" data-key="k1" data-level=3>hello, world</p>`, out.String())
+
+	out.Reset()
+	err = RenderChild(out, nil, ToHTML(`<ick-tstsniph7 key=k1/>`))
+	require.NoError(t, err)
+	assert.Equal(t, `<!--ick-tstsniph7: unregistered ick-tagname-->`, out.String())
+
+	// deterministic ids per registry
+	h1, h2 := ToHTML("a"), ToHTML("b")
+	err = reg.Clone().Render(out, nil, h1)
+	require.NoError(t, err)
+	err = reg.Clone().Render(out, nil, h2)
+	require.NoError(t, err)
+	assert.Equal(t, h1.RMeta().VirtualId, h2.RMeta().VirtualId)
+}
+
+func TestRegistryConcurrency(t *testing.T) {
+
+	reg := NewRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reg.Register("ick-tstsniph"+strconv.Itoa(i), &sniph7{})
+			reg.UniqueId("c")
+			out := new(bytes.Buffer)
+			reg.Clone().Render(out, nil, ToHTML(`<ick-tstsniph0 key=k/><ick-tstsniph1 key=k/>`))
+		}(i)
+	}
+	wg.Wait()
+	_, id := reg.UniqueId("c")
+	assert.Equal(t, "c-21", id)
+	assert.Len(t, reg.Map(), 20)
 }
@@ -1,6 +1,7 @@
 package ickcore
 
 import (
+	"fmt"
 	"io"
 	"reflect"
 
@@ -20,24 +21,21 @@ const maxDEEP int = 25
 // otherwise only the content is written.
 //
 // A snippet id can be setup up upfront (a) accessing any saved tag attribute within the snippet struct, or (b) within an html ick-tag attribute (for embedded snippet).
-// Thes id will be lost if ther'e a parent, the snippet attributes will be overwritten with the unique id generated by the rendering process.
-// Unique ids are generated by using the composer name (without "ick-" prefix) with a sequence number. sub-composer ids combine the id of the parent with the id of the sub-composer.
-// if the component is not registered and so does't have a name, a global unique id is generated.
-// This behaviour ensures that ids are uniques even for multiple instanciations of the same composer.
+// The id attribute is rendered as is.
 //
-// snippet may have none id on request. noid snippet attribute must be set to true to render the composer without id.
-// The special attribute noid can be defined within an ick-tag html or with attribute's methods.
+// Every rendered composer gets a virtual id, stable and predictable, depending only on its position in the rendering tree or on its Key.
+// See RMetaData.GenerateVirtualId for the rules.
 //
 // If the parent is not nil, the snippet is added to its embedded stack of sub-components.
 //
 // Returns rendering errors, typically with the writer, or if there's too many recursive rendering.
 func RenderChild(out io.Writer, parent RMetaProvider, child Composer, siblings ...Composer) error {
-	err := render(out, parent, child)
+	err := render(out, parent, child, nil, nil)
 	if err != nil {
 		return err
 	}
 	for _, s := range siblings {
-		err := render(out, parent, s)
+		err := render(out, parent, s, nil, nil)
 		if err != nil {
 			return err
 		}
@@ -45,7 +43,9 @@ func RenderChild(out io.Writer, parent RMetaProvider, child Composer, siblings .
 	return nil
 }
 
-func render(out io.Writer, parent RMetaProvider, cmp Composer) error {
+// render renders cmp.
+// data and reg are the data context and the registry of the rendering, if nil they're the ones of the composer or inherited from its parent.
+func render(out io.Writer, parent RMetaProvider, cmp Composer, data any, reg *Registry) error {
 	cmptyp := ""
 	if cmp != nil {
 		cmptyp = reflect.TypeOf(cmp).String()
@@ -72,22 +72,63 @@ func render(out io.Writer, parent RMetaProvider, cmp Composer) error {
 	}
 	verbose.Debug("rendering L.%v composer %s", deep, cmptyp)
 
+	// setup the rendering state, inherited from the output stream or from the parent, and bind it to the output stream.
+	// data and reg take precedence over the fields of the composer.
+	pout := out
+	st := stateof(out)
+	if st == nil && parent != nil {
+		st = st.inherit(parent.RMeta())
+	}
+	st = st.inherit(cmp.RMeta())
+	if data != nil {
+		st.data = data
+	}
+	if reg != nil {
+		st.registry = reg
+	}
+	out = st.bind(out)
+	if st.tracer != nil {
+		var span *TraceSpan
+		span, out = st.tracer.Begin("", cmp, out)
+		defer st.tracer.End(span, cmp)
+	}
+
+	// lifecycle hook
+	if br, is := cmp.(BeforeRenderer); is {
+		if err := br.BeforeRender(); err != nil {
+			cmp.RMeta().RError = err
+			return diagnoseError(out, cmp, "", srcpos{}, err)
+		}
+	}
+	st.context.record(cmp)
+
 	// build the tag
 	var tag Tag
 	cmptag, istagger := cmp.(TagBuilder)
 	if istagger && cmptag != nil {
 		tag = BuildTag(cmptag)
+		if key := cmp.RMeta().Key; key != "" && !tag.IsEmpty() {
+			tag.SetAttribute(KEY_DATA_ATTRIBUTE, key)
+		}
 	}
 
 	// generate the virtual id
-	cmp.RMeta().GenerateVirtualId(cmp)
+	cmp.RMeta().generatevid(parent, cmp, st.currentregistry())
+	cmp.RMeta().ResetEmbedded()
 
 	// render openingtag
-	if cmptag != nil {
+	builder, isbuilder := out.(NodeBuilder)
+	if cmptag != nil && isbuilder && !tag.IsEmpty() {
+		err := builder.OpenTag(cmp, tag)
+		if tag.IsSelfClosing() || err != nil {
+			cmp.RMeta().RError = err
+			return diagnoseError(out, cmp, "", srcpos{}, err)
+		}
+	} else if cmptag != nil {
 		selfclosed, err := tag.RenderOpening(out)
 		if selfclosed || err != nil {
 			cmp.RMeta().RError = err
-			return err
+			return diagnoseError(out, cmp, "", srcpos{}, err)
 		}
 	}
 
@@ -96,16 +137,22 @@ func render(out io.Writer, parent RMetaProvider, cmp Composer) error {
 		err := cc.RenderContent(out)
 		if err != nil {
 			cmp.RMeta().RError = err
-			return err
+			return diagnoseError(out, cmp, "", srcpos{}, err)
 		}
 	}
 
 	// Render closingtag
-	if cmptag != nil {
+	if cmptag != nil && isbuilder && !tag.IsEmpty() {
+		err := builder.CloseTag(cmp, tag)
+		if err != nil {
+			cmp.RMeta().RError = err
+			return diagnoseError(out, cmp, "", srcpos{}, err)
+		}
+	} else if cmptag != nil {
 		err := tag.RenderClosing(out)
 		if err != nil {
 			cmp.RMeta().RError = err
-			return err
+			return diagnoseError(out, cmp, "", srcpos{}, err)
 		}
 	}
 
@@ -113,7 +160,14 @@ func render(out io.Writer, parent RMetaProvider, cmp Composer) error {
 	cmp.RMeta().IsRender = true
 	cmp.RMeta().Deep = 0
 	if parent != nil {
-		parent.RMeta().Embed(cmp)
+		if dup := parent.RMeta().embed(cmp); dup != "" {
+			diagnose(pout, parent, Diagnostic{Level: DIAG_WARNING, Err: fmt.Errorf("duplicate child id %q", dup)})
+		}
+	}
+
+	// lifecycle hook
+	if ar, is := cmp.(AfterRenderer); is {
+		ar.AfterRender()
 	}
 
 	// DEBUG:	verbose.Debug("render: %+v", cmp)
@@ -2,6 +2,7 @@ package ickcore
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"testing"
 
@@ -82,3 +83,162 @@ func TestRenderChild(t *testing.T) {
 	RenderChild(out, nil, s2)
 	assert.Equal(t, `<div id="tst" name="snip1" class="ts2a ts2b" a2 a3 style="display=test;" tabindex=2></div>`, out.String())
 }
+
+type snip2 struct {
+	BareSnippet
+	Childs []Composer
+}
+
+func (s *snip2) RenderContent(out io.Writer) error {
+	for _, c := range s.Childs {
+		if err := RenderChild(out, s, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestVirtualId(t *testing.T) {
+
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph7", &sniph7{})
+
+	h1 := ToHTML(`<ick-tstsniph7 key=a/><ick-tstsniph7 key=b ick-key=second/>`)
+	h2 := ToHTML(`text`)
+	s1 := &snip1{Test: 1}
+	s1.Tag().SetId("myid")
+	inner := &snip2{Childs: []Composer{h2}}
+	root := &snip2{Childs: []Composer{h1, inner, s1, ToHTML("x")}}
+
+	out := new(bytes.Buffer)
+	for i := 0; i < 2; i++ {
+		out.Reset()
+		err := RenderChild(out, nil, root)
+		require.NoError(t, err)
+
+		assert.Equal(t, "snip21", root.RMeta().VirtualId)
+		assert.Equal(t, "snip21.htmlstring0", h1.RMeta().VirtualId)
+		assert.Equal(t, "snip21.snip20", inner.RMeta().VirtualId)
+		assert.Equal(t, "snip21.snip20.htmlstring0", h2.RMeta().VirtualId)
+		assert.Equal(t, "myid", s1.RMeta().VirtualId)
+		require.Len(t, root.RMeta().Embedded(), 4)
+		assert.NotNil(t, root.RMeta().Embedded()["snip21.htmlstring1"])
+
+		emb := h1.RMeta().Embedded()
+		require.Len(t, emb, 2)
+		assert.NotNil(t, emb["snip21.htmlstring0.sniph70"])
+		assert.NotNil(t, emb["snip21.htmlstring0.second"])
+	}
+
+	// user-provided key
+	h2.RMeta().Key = "k"
+	out.Reset()
+	err := RenderChild(out, nil, root)
+	require.NoError(t, err)
+	assert.Equal(t, "snip21.snip20.k", h2.RMeta().VirtualId)
+
+	// roots get a unique virtual id
+	other := &snip2{}
+	err = RenderChild(out, nil, other)
+	require.NoError(t, err)
+	assert.Equal(t, "snip22", other.RMeta().VirtualId)
+	assert.Equal(t, "snip21", root.RMeta().VirtualId)
+}
+
+type sniplifecycle struct {
+	BareSnippet
+	Fail  bool
+	Calls []string
+}
+
+func (s *sniplifecycle) BeforeRender() error {
+	s.Calls = append(s.Calls, "before")
+	if s.Fail {
+		return errors.New("not ready")
+	}
+	return nil
+}
+
+func (s *sniplifecycle) BuildTag() Tag {
+	s.Calls = append(s.Calls, "tag")
+	s.Tag().SetTagName("div")
+	return *s.Tag()
+}
+
+func (s *sniplifecycle) RenderContent(out io.Writer) error {
+	s.Calls = append(s.Calls, "content")
+	return nil
+}
+
+func (s *sniplifecycle) AfterRender() {
+	s.Calls = append(s.Calls, "after")
+}
+
+func TestRenderLifecycle(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	s := new(sniplifecycle)
+	err := RenderChild(out, nil, s)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"before", "tag", "content", "after"}, s.Calls)
+
+	out.Reset()
+	s = &sniplifecycle{Fail: true}
+	err = RenderChild(out, nil, s)
+	assert.Error(t, err)
+	assert.Empty(t, out.String())
+	assert.Equal(t, []string{"before"}, s.Calls)
+	assert.ErrorContains(t, s.RMeta().RError, "not ready")
+}
+
+// testbuilder records the calls of the NodeBuilder interface
+type testbuilder struct {
+	bytes.Buffer
+	Calls []string
+}
+
+func (b *testbuilder) OpenTag(cmp Composer, tag Tag) error {
+	tagname, _ := tag.TagName()
+	b.Calls = append(b.Calls, "open "+tagname+" "+b.String())
+	b.Reset()
+	return nil
+}
+
+func (b *testbuilder) CloseTag(cmp Composer, tag Tag) error {
+	tagname, _ := tag.TagName()
+	b.Calls = append(b.Calls, "close "+tagname+" "+b.String())
+	b.Reset()
+	return nil
+}
+
+type snip3 struct {
+	snip2
+}
+
+func (s *snip3) BuildTag() Tag {
+	s.Tag().SetTagName("div")
+	return *s.Tag()
+}
+
+func TestRenderNodeBuilder(t *testing.T) {
+
+	ResetRegistry()
+	s1 := &snip1{Test: 1}
+	br := &snip1{}
+	br.Tag().SetTagName("br")
+	root := &snip3{snip2{Childs: []Composer{ToHTML("<b>a</b>"), s1, br, ToHTML("c")}}}
+
+	out := new(testbuilder)
+	err := RenderChild(out, nil, root)
+	require.NoError(t, err)
+	out.Calls = append(out.Calls, "end "+out.String())
+
+	assert.Equal(t, []string{
+		"open div ",
+		"open span <b>a</b>",
+		"close span ",
+		"open br ",
+		"close div c",
+		"end ",
+	}, out.Calls)
+}
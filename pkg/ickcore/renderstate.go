@@ -0,0 +1,163 @@
+package ickcore
+
+import (
+	"io"
+)
+
+// renderstate is the state of a rendering, inherited by the composers of the rendering tree.
+// The rendering process binds it to the output stream handed to RenderContent, so it's passed down the call chain
+// rather than saved in the rendering metadata of the composers, which can be rendered by several renderings at once.
+type renderstate struct {
+	data     any            // data context for template expressions
+	report   *RenderReport  // report collecting the diagnostics
+	registry *Registry      // registry used to unfold ick-tags
+	context  *RenderContext // context collecting the requirements of the rendered composers
+	tracer   *RenderTracer  // tracer recording the rendering of the composers
+	span     *TraceSpan     // span of the composer being rendered, if traced
+}
+
+// inherit returns a copy of the state overridden by the fields of rmeta. st can be nil.
+func (st *renderstate) inherit(rmeta *RMetaData) *renderstate {
+	nst := new(renderstate)
+	if st != nil {
+		*nst = *st
+	}
+	if rmeta == nil {
+		return nst
+	}
+	if rmeta.Data != nil {
+		nst.data = rmeta.Data
+	}
+	if rmeta.Report != nil {
+		nst.report = rmeta.Report
+	}
+	if rmeta.Registry != nil {
+		nst.registry = rmeta.Registry
+	}
+	if rmeta.Context != nil {
+		nst.context = rmeta.Context
+	}
+	if rmeta.Trace != nil && rmeta.Trace != nst.tracer {
+		nst.tracer = rmeta.Trace
+		nst.span = nil
+	}
+	return nst
+}
+
+// currentregistry returns the registry of the state, the DefaultRegistry if none.
+func (st *renderstate) currentregistry() *Registry {
+	if st == nil || st.registry == nil {
+		return DefaultRegistry
+	}
+	return st.registry
+}
+
+// bind returns a writer to w bound to the state. w is returned as a NodeBuilder if it is one.
+func (st *renderstate) bind(w io.Writer) io.Writer {
+	switch bw := w.(type) {
+	case *statewriter:
+		w = bw.Writer
+	case *statebuilder:
+		w = bw.NodeBuilder
+	}
+	if builder, isbuilder := w.(NodeBuilder); isbuilder {
+		return &statebuilder{NodeBuilder: builder, state: st}
+	}
+	return &statewriter{Writer: w, state: st}
+}
+
+// statewriter is an output stream bound to a rendering state
+type statewriter struct {
+	io.Writer
+	state *renderstate
+}
+
+// statebuilder is a NodeBuilder bound to a rendering state
+type statebuilder struct {
+	NodeBuilder
+	state *renderstate
+}
+
+// stateof returns the rendering state bound to out, nil if none.
+func stateof(out io.Writer) *renderstate {
+	switch bw := out.(type) {
+	case *statewriter:
+		return bw.state
+	case *statebuilder:
+		return bw.state
+	}
+	return nil
+}
+
+// currentstate returns the rendering state bound to out, otherwise the one defined by the fields of the rendering metadata of cmp.
+func currentstate(out io.Writer, cmp RMetaProvider) *renderstate {
+	if st := stateof(out); st != nil {
+		return st
+	}
+	if cmp == nil {
+		return nil
+	}
+	return (*renderstate)(nil).inherit(cmp.RMeta())
+}
+
+/******************************************************************************/
+
+// Bind returns out bound to the rendering state of cmp: the state bound to out if any, overridden by the Data, Report, Registry,
+// Context and Trace fields of the rendering metadata of cmp. The children rendered into the returned writer inherit this state.
+//
+// The rendering process binds the output stream of every composer before calling its RenderContent.
+// A composer rendering its content outside of the rendering process, like a Page, must bind it itself.
+func Bind(out io.Writer, cmp RMetaProvider) io.Writer {
+	var rmeta *RMetaData
+	if cmp != nil {
+		rmeta = cmp.RMeta()
+	}
+	return stateof(out).inherit(rmeta).bind(out)
+}
+
+// Redirect returns a writer to w bound to the rendering state of out.
+// A composer rendering its children into an intermediate buffer must redirect the buffer, so its children inherit the rendering state.
+// w is returned as is if there's no state bound to out.
+func Redirect(w io.Writer, out io.Writer) io.Writer {
+	st := stateof(out)
+	if st == nil {
+		return w
+	}
+	return st.bind(w)
+}
+
+// WithContext returns a writer to w bound to the render context ctx, and to the rest of the rendering state of w if any.
+// Use it to collect the requirements of a composer rendered directly with RenderContent.
+func WithContext(w io.Writer, ctx *RenderContext) io.Writer {
+	return stateof(w).inherit(&RMetaData{Context: ctx}).bind(w)
+}
+
+// CurrentContext returns the render context bound to out, nil if none.
+// Call it within RenderContent to get the context of the current rendering.
+func CurrentContext(out io.Writer) *RenderContext {
+	if st := stateof(out); st != nil {
+		return st.context
+	}
+	return nil
+}
+
+// CurrentRegistry returns the registry bound to out, the DefaultRegistry if none.
+func CurrentRegistry(out io.Writer) *Registry {
+	return stateof(out).currentregistry()
+}
+
+// CurrentReport returns the report bound to out, nil if none.
+func CurrentReport(out io.Writer) *RenderReport {
+	if st := stateof(out); st != nil {
+		return st.report
+	}
+	return nil
+}
+
+// CurrentTracer returns the tracer bound to out, nil if none.
+func CurrentTracer(out io.Writer) *RenderTracer {
+	if st := stateof(out); st != nil {
+		return st.tracer
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package ickcore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DIAGNOSTIC_LEVEL is the severity of a rendering diagnostic
+type DIAGNOSTIC_LEVEL int
+
+const (
+	DIAG_INFO    DIAGNOSTIC_LEVEL = iota // the output has been rendered as expected, the diagnostic is a hint
+	DIAG_WARNING                         // the output has been rendered but may not be the expected one
+	DIAG_ERROR                           // a part of the output has not been rendered
+)
+
+func (l DIAGNOSTIC_LEVEL) String() string {
+	switch l {
+	case DIAG_ERROR:
+		return "error"
+	case DIAG_WARNING:
+		return "warning"
+	}
+	return "info"
+}
+
+// Diagnostic is an error or a warning raised during a rendering.
+type Diagnostic struct {
+	Level      DIAGNOSTIC_LEVEL
+	Path       string // virtual id of the composer being rendered when the diagnostic has been raised
+	IckTagName string // the ick-tag name involved, if any
+	Line       int    // line within the originating HTMLString, starting at 1. 0 if unknown
+	Column     int    // column within the line, in runes starting at 1. 0 if unknown
+	Err        error
+}
+
+// String returns the diagnostic in the form:
+//
+//	`{level}: {path} {ick-tagname} {line}:{column}: {message}`
+func (d Diagnostic) String() string {
+	s := d.Level.String() + ":"
+	if d.Path != "" {
+		s += " " + d.Path
+	}
+	if d.IckTagName != "" {
+		s += " " + d.IckTagName
+	}
+	if d.Line > 0 {
+		s += " " + strconv.Itoa(d.Line) + ":" + strconv.Itoa(d.Column)
+	}
+	msg := "unknown"
+	if d.Err != nil {
+		msg = d.Err.Error()
+	}
+	return s + ": " + msg
+}
+
+// RenderReport collects diagnostics raised during a rendering.
+// A report is bound to a composer with the Report field of its rendering metadata, and is inherited by its children.
+// The zero value is an empty report ready to use. RenderReport is safe for concurrent use.
+type RenderReport struct {
+	mu          sync.Mutex
+	diagnostics []Diagnostic
+}
+
+// Add appends a diagnostic to the report. Does nothing if the report is nil.
+func (r *RenderReport) Add(d Diagnostic) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.diagnostics = append(r.diagnostics, d)
+}
+
+// Diagnostics returns a copy of all the diagnostics in the order they have been raised.
+func (r *RenderReport) Diagnostics() []Diagnostic {
+	return r.filter(func(Diagnostic) bool { return true })
+}
+
+// Errors returns the diagnostics with the DIAG_ERROR level.
+func (r *RenderReport) Errors() []Diagnostic {
+	return r.filter(func(d Diagnostic) bool { return d.Level == DIAG_ERROR })
+}
+
+// Warnings returns the diagnostics with the DIAG_WARNING level.
+func (r *RenderReport) Warnings() []Diagnostic {
+	return r.filter(func(d Diagnostic) bool { return d.Level == DIAG_WARNING })
+}
+
+// Infos returns the diagnostics with the DIAG_INFO level.
+func (r *RenderReport) Infos() []Diagnostic {
+	return r.filter(func(d Diagnostic) bool { return d.Level == DIAG_INFO })
+}
+
+// Len returns the number of diagnostics
+func (r *RenderReport) Len() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.diagnostics)
+}
+
+// HasErrors returns true if the report contains at least one error
+func (r *RenderReport) HasErrors() bool {
+	return len(r.Errors()) > 0
+}
+
+// String returns all the diagnostics, one per line.
+func (r *RenderReport) String() string {
+	lines := make([]string, 0)
+	for _, d := range r.Diagnostics() {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *RenderReport) filter(keep func(Diagnostic) bool) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	if r == nil {
+		return diags
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.diagnostics {
+		if keep(d) {
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+/******************************************************************************/
+
+// diagnosedError wraps an error already recorded in the render report, so it's not recorded again by the parents.
+type diagnosedError struct {
+	error
+}
+
+func (e diagnosedError) Unwrap() error {
+	return e.error
+}
+
+// isdiagnosed returns true if err has already been recorded in the render report
+func isdiagnosed(err error) bool {
+	var de diagnosedError
+	return errors.As(err, &de)
+}
+
+// diagnose records a diagnostic in the report of the rendering into out, otherwise in the Report of the rendering composer cmp, if any.
+// The path of the diagnostic is the virtual id of cmp.
+func diagnose(out io.Writer, cmp RMetaProvider, d Diagnostic) {
+	st := currentstate(out, cmp)
+	if st == nil || st.report == nil {
+		return
+	}
+	if cmp != nil {
+		d.Path = cmp.RMeta().VirtualId
+	}
+	st.report.Add(d)
+}
+
+// diagnoseError records err as a DIAG_ERROR in the report of the rendering and returns it wrapped in a diagnosedError.
+// Returns err unchanged if it is nil or if it has already been recorded.
+func diagnoseError(out io.Writer, cmp RMetaProvider, ickname string, pos srcpos, err error) error {
+	if err == nil || isdiagnosed(err) {
+		return err
+	}
+	diagnose(out, cmp, Diagnostic{Level: DIAG_ERROR, IckTagName: ickname, Line: pos.line, Column: pos.column, Err: err})
+	return diagnosedError{err}
+}
+
+// srcpos is a position within an HTMLString
+type srcpos struct {
+	line   int
+	column int
+}
+
+// position returns the line and column of the byte at offset within src
+func position(src []byte, offset int) srcpos {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	line := 1 + strings.Count(string(src[:offset]), "\n")
+	linestart := strings.LastIndexByte(string(src[:offset]), '\n') + 1
+	return srcpos{line: line, column: utf8.RuneCount(src[linestart:offset]) + 1}
+}
+
+var tmplposRegexp = regexp.MustCompile(`^template: [^:]*:(\d+):(\d+)?`)
+
+// templateerror is an error of a template expression, located within the source of the HTMLString.
+type templateerror struct {
+	err error
+	pos srcpos
+}
+
+// Error returns the error of the template with the position within the source of the HTMLString.
+func (e templateerror) Error() string {
+	return tmplposRegexp.ReplaceAllLiteralString(e.err.Error(), fmt.Sprintf("template: %d:%d", e.pos.line, e.pos.column))
+}
+
+func (e templateerror) Unwrap() error { return e.err }
+
+// locatetemplate locates err, an error of a text/template compiled from an HTMLString, within the source of the HTMLString.
+// text/template reports positions within the source rewritten by contextualize, they're translated back with the offsets of tmpl.
+// An error within an output expression is located at the start of the expression.
+// Returns the position and err reporting it. Only the line is located if tmpl is nil.
+func locatetemplate(tmpl *datatemplate, err error) (srcpos, error) {
+	var pos srcpos
+	m := tmplposRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return pos, err
+	}
+	pos.line, _ = strconv.Atoi(m[1])
+	if tmpl == nil || m[2] == "" {
+		return pos, err
+	}
+	// text/template columns are byte offsets within the line, starting at 0.
+	// contextualize never adds nor removes a line.
+	column, _ := strconv.Atoi(m[2])
+	linestart := 0
+	for l := 1; l < pos.line; l++ {
+		linestart += strings.IndexByte(tmpl.rewritten[linestart:], '\n') + 1
+	}
+	pos = position(tmpl.src, tmpl.srcoffset(linestart+column))
+	return pos, templateerror{err: err, pos: pos}
+}
+
+// Ensuring Diagnostic implements the right interface
+var _ fmt.Stringer = Diagnostic{}
@@ -0,0 +1,95 @@
+package ickcore
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPosition(t *testing.T) {
+	src := []byte("ab\ncdé\nf")
+	assert.Equal(t, srcpos{1, 1}, position(src, 0))
+	assert.Equal(t, srcpos{1, 3}, position(src, 2))
+	assert.Equal(t, srcpos{2, 1}, position(src, 3))
+	assert.Equal(t, srcpos{3, 1}, position(src, 8))
+	assert.Equal(t, srcpos{3, 2}, position(src, 100))
+}
+
+func TestRenderReport(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph7", &sniph7{})
+
+	report := new(RenderReport)
+	parent := new(BareSnippet)
+	parent.RMeta().Report = report
+
+	out := new(bytes.Buffer)
+	err := RenderChild(out, parent, ToHTML("<p>\n  <ick-unknown/>\n</p><ick-tstsniph7 level=x/>\n<ick-tstsniph7 key=k1 foo=bar/>"))
+	require.NoError(t, err)
+
+	diags := report.Diagnostics()
+	require.Len(t, diags, 3)
+	assert.Len(t, report.Errors(), 2)
+	assert.Len(t, report.Infos(), 1)
+	assert.True(t, report.HasErrors())
+
+	assert.Equal(t, DIAG_ERROR, diags[0].Level)
+	assert.Equal(t, "ick-unknown", diags[0].IckTagName)
+	assert.Contains(t, diags[0].Path, "htmlstring")
+	assert.Equal(t, 2, diags[0].Line)
+	assert.Equal(t, 3, diags[0].Column)
+
+	assert.Equal(t, "ick-tstsniph7", diags[1].IckTagName)
+	assert.Equal(t, 3, diags[1].Line)
+	assert.Equal(t, 5, diags[1].Column)
+
+	assert.Equal(t, DIAG_INFO, diags[2].Level)
+	assert.Equal(t, 4, diags[2].Line)
+	assert.Equal(t, `info: `+diags[2].Path+` ick-tstsniph7 4:1: "foo" attribute: not a property, set to the tag`, diags[2].String())
+
+	// syntax error, recorded once
+	report = new(RenderReport)
+	parent.RMeta().Report = report
+	err = RenderChild(out, parent, ToHTML("<p>\n<ick-tstsniph7 key=k1 =1/>"))
+	require.Error(t, err)
+	var ickerr *IckTagNameError
+	assert.True(t, errors.As(err, &ickerr))
+	require.Equal(t, 1, report.Len())
+	assert.Equal(t, 2, report.Diagnostics()[0].Line)
+
+	// template error
+	report = new(RenderReport)
+	parent.RMeta().Report = report
+	err = RenderData(out, parent, struct{}{}, ToHTML("<p>\n{{ .Nope }}</p>"))
+	require.Error(t, err)
+	require.Equal(t, 1, report.Len())
+	assert.Equal(t, 2, report.Diagnostics()[0].Line)
+
+	// template error located at the expression within the source of the HTMLString, not within the compiled template
+	report = new(RenderReport)
+	parent.RMeta().Report = report
+	data0 := map[string]any{"X": 1}
+	err = RenderData(out, parent, data0, ToHTML(`<p class="a">{{.X}}</p><b title="t">{{ .Missing.Deep }}</b>`))
+	require.Error(t, err)
+	require.Equal(t, 1, report.Len())
+	assert.Equal(t, 1, report.Diagnostics()[0].Line)
+	assert.Equal(t, 40, report.Diagnostics()[0].Column)
+	assert.Contains(t, err.Error(), "template: 1:40: ")
+
+	// ick-tags expanded from a template are located within the template source
+	report = new(RenderReport)
+	parent.RMeta().Report = report
+	data := struct{ Items []string }{Items: []string{"a", "long item"}}
+	err = RenderData(out, parent, data, ToHTML("<ul>\n{{ range .Items }}<li>{{ . }}</li>\n{{ end }}\n  <ick-unknown/></ul>"))
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Len())
+	assert.Equal(t, 4, report.Diagnostics()[0].Line)
+	assert.Equal(t, 3, report.Diagnostics()[0].Column)
+
+	// no report
+	err = RenderChild(out, nil, ToHTML("<ick-unknown/>"))
+	require.NoError(t, err)
+}
@@ -2,54 +2,314 @@ package ickcore
 
 import (
 	"net/url"
+	"reflect"
 	"strings"
+	"sync"
 )
 
+// cssstyle is a required CSS style string
+type cssstyle struct {
+	name     string // the name of the style, the ick-tag name or the lowercase type name of the composer requiring it if composer is true
+	style    string
+	composer bool // the style is added only to the pages rendering a composer matching name
+}
+
+// cssfile is a CSS file required by a specific composer
+type cssfile struct {
+	name string // the ick-tag name or the lowercase type name of the composer requiring it
+	url  string
+}
+
 var (
-	_cssFiles []url.URL // slice of required stylesheet link ref. will be added once into the head of the page
-	_cssStyle string    // css string that will be added to the style tag in the head of the HTML file
+	_requiremu        sync.RWMutex // protects the declared requirements
+	_cssFiles         []url.URL    // slice of required stylesheet link ref. will be added once into the head of every page
+	_composerCSSFiles []cssfile    // slice of stylesheet link ref required by composers. will be added once into the head of the pages rendering their composer
+	_cssStyles        []cssstyle   // slice of required css styles. will be added once into the style tag in the head of every page, or of the pages rendering their composer
 )
 
-// RequireCSSFile allows to declare a required CSS file.
+// RequireCSSFile allows to declare a CSS file required by every page.
 // This can be call in the init function of a package defining custom snippets.
+// Use RenderContext.RequireCSSFile to require a CSS file only for the page being rendered.
 func RequireCSSFile(cssURL string) {
+	_requiremu.Lock()
+	defer _requiremu.Unlock()
+	_cssFiles = appendcssfile(_cssFiles, cssURL)
+}
+
+// RequireComposerCSSFile allows to declare a CSS file required by a specific composer.
+// This can be call in the init function of a package defining custom snippets.
+//
+// name is either the registered ick-tag name of the composer, or the lowercase name of its type, like for RequireComposerCSSStyle.
+// The file is added only to the pages rendering at least one composer matching the name.
+func RequireComposerCSSFile(name string, cssURL string) {
 	if cssURL == "" {
 		return
 	}
+	_requiremu.Lock()
+	defer _requiremu.Unlock()
+	_composerCSSFiles = append(_composerCSSFiles, cssfile{name: strings.ToLower(strings.TrimSpace(name)), url: cssURL})
+}
+
+// RequireCSSStyle allows to declare a required CSS style string, added once into the head of every page.
+// This can be call in the init function of a package defining custom snippets.
+// The style is added once per name.
+func RequireCSSStyle(name string, cssStyle string) {
+	_requiremu.Lock()
+	defer _requiremu.Unlock()
+	_cssStyles = appendcssstyle(_cssStyles, name, cssStyle, false)
+}
+
+// RequireComposerCSSStyle allows to declare a required CSS style string for a specific composer.
+// This can be call in the init function of a package defining custom snippets.
+//
+// name is either the registered ick-tag name of the composer, or the lowercase name of its type.
+// The style is added only to the pages rendering at least one composer matching the name. It's added once whatever the number of rendered composers.
+// Use RequireCSSStyle for composers inserted into the DOM by the wasm code, which are not rendered with the page.
+func RequireComposerCSSStyle(name string, cssStyle string) {
+	_requiremu.Lock()
+	defer _requiremu.Unlock()
+	_cssStyles = appendcssstyle(_cssStyles, name, cssStyle, true)
+}
+
+// RequiredCSSFile returns the CSS files declared with RequireCSSFile.
+func RequiredCSSFile() []url.URL {
+	_requiremu.RLock()
+	defer _requiremu.RUnlock()
+	return append([]url.URL{}, _cssFiles...)
+}
+
+// RequiredCSSStyle returns all the CSS styles declared with RequireCSSStyle and RequireComposerCSSStyle, whatever composers have been rendered.
+func RequiredCSSStyle() string {
+	_requiremu.RLock()
+	defer _requiremu.RUnlock()
+	return joincssstyles(_cssStyles, nil)
+}
+
+// appendcssfile parses cssURL and appends it to files if not already there.
+func appendcssfile(files []url.URL, cssURL string) []url.URL {
+	if cssURL == "" {
+		return files
+	}
 	url, err := url.Parse(cssURL)
 	if err != nil {
-		return
+		return files
 	}
-
-	// do not add it twice
-	duplicate := false
 	strurl := url.String()
-	for _, cssf := range _cssFiles {
+	for _, cssf := range files {
 		if cssf.String() == strurl {
-			duplicate = true
-			break
+			return files
 		}
 	}
-	if !duplicate {
-		_cssFiles = append(_cssFiles, *url)
+	return append(files, *url)
+}
+
+// appendcssstyle appends style to styles if name has not already been declared.
+func appendcssstyle(styles []cssstyle, name string, style string, composer bool) []cssstyle {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, s := range styles {
+		if s.name == name {
+			return styles
+		}
 	}
+	return append(styles, cssstyle{name: name, style: style, composer: composer})
 }
 
-// RequireCSSFile allows to declare a required CSS style string for a specific snippet.
-// This can be call in the init function of a package defining custom snippets.
-func RequireCSSStyle(ickTagName string, cssStyle string) {
-	cmt := `/* ` + ickTagName + " */\n"
-	found := strings.Contains(_cssStyle, cmt)
-	if !found {
-		_cssStyle += cmt
-		_cssStyle += cssStyle
+// joincssstyles concatenates the styles for which keep returns true, each one preceded with its name in a css comment.
+// All styles are kept if keep is nil.
+func joincssstyles(styles []cssstyle, keep func(s cssstyle) bool) string {
+	var sb strings.Builder
+	for _, s := range styles {
+		if keep == nil || keep(s) {
+			sb.WriteString(`/* ` + s.name + " */\n")
+			sb.WriteString(s.style)
+		}
 	}
+	return sb.String()
 }
 
-func RequiredCSSFile() []url.URL {
-	return _cssFiles
+/******************************************************************************/
+
+// RenderContext collects the requirements of the composers rendered within a single rendering, typically a page.
+// A context is bound to a composer with the Context field of its rendering metadata, and is inherited by its children.
+// Every composer rendered with a context is recorded in it.
+//
+// The zero value is not ready to use, use NewRenderContext. A nil context ignores requirements.
+// RenderContext is safe for concurrent use.
+type RenderContext struct {
+	mu        sync.Mutex
+	cssfiles  []url.URL
+	cssstyles []cssstyle
+	scripts   []RequiredScript
+	portals   []PortalContent
+	lang      string                // language of the rendering
+	rendered  map[reflect.Type]bool // types of the rendered composers
 }
 
-func RequiredCSSStyle() string {
-	return _cssStyle
+// NewRenderContext returns a new empty render context.
+func NewRenderContext() *RenderContext {
+	ctx := new(RenderContext)
+	ctx.rendered = make(map[reflect.Type]bool)
+	return ctx
+}
+
+// SetLang sets the language of the rendering, typically the one of the page.
+// Composers can use it to translate or format their content.
+func (ctx *RenderContext) SetLang(lang string) {
+	if ctx == nil {
+		return
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.lang = lang
+}
+
+// Lang returns the language of the rendering, empty if it has not been set.
+func (ctx *RenderContext) Lang() string {
+	if ctx == nil {
+		return ""
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	return ctx.lang
+}
+
+// RequireCSSFile declares a CSS file required by the rendering.
+func (ctx *RenderContext) RequireCSSFile(cssURL string) {
+	if ctx == nil {
+		return
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.cssfiles = appendcssfile(ctx.cssfiles, cssURL)
+}
+
+// RequireCSSStyle declares a CSS style string required by the rendering.
+// The style is added once per name.
+func (ctx *RenderContext) RequireCSSStyle(name string, cssStyle string) {
+	if ctx == nil {
+		return
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.cssstyles = appendcssstyle(ctx.cssstyles, name, cssStyle, false)
+}
+
+// record records the type of a rendered composer
+func (ctx *RenderContext) record(cmp any) {
+	if ctx == nil {
+		return
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.rendered[reflect.TypeOf(cmp)] = true
+}
+
+// addportal records the content of a portal
+func (ctx *RenderContext) addportal(p PortalContent) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.portals = append(ctx.portals, p)
+}
+
+// Portals returns the content of the portals rendered with this context, in rendering order.
+func (ctx *RenderContext) Portals() []PortalContent {
+	if ctx == nil {
+		return nil
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	return append([]PortalContent{}, ctx.portals...)
+}
+
+// IsRendered returns true if at least one composer of the same type than cmp has been rendered with this context.
+func (ctx *RenderContext) IsRendered(cmp any) bool {
+	if ctx == nil {
+		return false
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	return ctx.rendered[reflect.TypeOf(cmp)]
+}
+
+// CSSFiles returns the CSS files declared with the package level RequireCSSFile, and with RequireComposerCSSFile for the composers rendered with this context,
+// followed by the ones required by the rendering, without duplicates.
+// reg is used to lookup the composers declared with their ick-tag name. DefaultRegistry is used if reg is nil.
+func (ctx *RenderContext) CSSFiles(reg *Registry) []url.URL {
+	if reg == nil {
+		reg = DefaultRegistry
+	}
+
+	_requiremu.RLock()
+	files := append([]url.URL{}, _cssFiles...)
+	declared := append([]cssfile{}, _composerCSSFiles...)
+	_requiremu.RUnlock()
+
+	if ctx == nil {
+		return files
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	for _, f := range declared {
+		if ctx.isrenderedlocked(reg, f.name) {
+			files = appendcssfile(files, f.url)
+		}
+	}
+	for _, f := range ctx.cssfiles {
+		files = appendcssfile(files, f.String())
+	}
+	return files
+}
+
+// CSSStyle returns the CSS styles declared with the package level RequireCSSStyle, and with RequireComposerCSSStyle for the composers rendered with this context,
+// followed by the styles required by the rendering.
+// reg is used to lookup the composers declared with their ick-tag name. DefaultRegistry is used if reg is nil.
+func (ctx *RenderContext) CSSStyle(reg *Registry) string {
+	if reg == nil {
+		reg = DefaultRegistry
+	}
+
+	_requiremu.RLock()
+	declared := append([]cssstyle{}, _cssStyles...)
+	_requiremu.RUnlock()
+
+	if ctx == nil {
+		return joincssstyles(declared, func(s cssstyle) bool {
+			return !s.composer
+		})
+	}
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	emitted := make(map[string]bool)
+	css := joincssstyles(declared, func(s cssstyle) bool {
+		emitted[s.name] = !s.composer || ctx.isrenderedlocked(reg, s.name)
+		return emitted[s.name]
+	})
+	return css + joincssstyles(ctx.cssstyles, func(s cssstyle) bool {
+		return !emitted[s.name]
+	})
+}
+
+// isrendered returns true if a composer matching name has been rendered with this context.
+// name is either a registered ick-tag name or the lowercase name of the composer's type.
+func (ctx *RenderContext) isrendered(reg *Registry, name string) bool {
+	if ctx == nil {
+		return false
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	return ctx.isrenderedlocked(reg, name)
+}
+
+// isrenderedlocked is isrendered for a caller holding the lock.
+func (ctx *RenderContext) isrenderedlocked(reg *Registry, name string) bool {
+	if reg.IsRegistered(name) {
+		return ctx.rendered[reflect.TypeOf(reg.Entry(name).Component())]
+	}
+	for typ := range ctx.rendered {
+		if typ.Kind() == reflect.Ptr && strings.ToLower(typ.Elem().Name()) == name {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,80 @@
+package ickcore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderContext(t *testing.T) {
+
+	// save and restore the declared requirements
+	_requiremu.Lock()
+	savfiles, savcmpfiles, savstyles := _cssFiles, _composerCSSFiles, _cssStyles
+	_cssFiles, _composerCSSFiles, _cssStyles = nil, nil, nil
+	_requiremu.Unlock()
+	defer func() {
+		_requiremu.Lock()
+		_cssFiles, _composerCSSFiles, _cssStyles = savfiles, savcmpfiles, savstyles
+		_requiremu.Unlock()
+	}()
+
+	ResetRegistry()
+	RegisterComposer("ick-testrc1", &snip1{})
+
+	RequireCSSFile("/assets/site.css")
+	RequireCSSFile("/assets/site.css")
+	RequireComposerCSSFile("ick-testrc1", "/assets/s1.css")
+	RequireComposerCSSFile("snip0", "/assets/s0.css")
+	RequireComposerCSSFile("snip0", "/assets/site.css")
+	RequireCSSStyle("global", ".g{}")
+	RequireComposerCSSStyle("ick-testrc1", ".s1{}")
+	RequireComposerCSSStyle("snip0", ".s0{}")
+	RequireComposerCSSStyle("snip0", ".duplicate{}")
+	RequireCSSStyle("snip0", ".duplicate{}")
+	RequireComposerCSSStyle("unknown", ".unknown{}")
+	assert.Equal(t, "/* global */\n.g{}/* ick-testrc1 */\n.s1{}/* snip0 */\n.s0{}/* unknown */\n.unknown{}", RequiredCSSStyle())
+
+	// nothing rendered
+	ctx := NewRenderContext()
+	assert.Equal(t, "/* global */\n.g{}", ctx.CSSStyle(nil))
+	require.Len(t, ctx.CSSFiles(nil), 1)
+	assert.Equal(t, "/assets/site.css", ctx.CSSFiles(nil)[0].String())
+
+	// the context is inherited by the children, composers are recorded
+	parent := &snip1{Test: 1}
+	parent.RMeta().Context = ctx
+	out := new(bytes.Buffer)
+	err := RenderChild(out, nil, parent)
+	require.NoError(t, err)
+	assert.True(t, ctx.IsRendered(&snip1{}))
+	assert.False(t, ctx.IsRendered(&snip0{}))
+	assert.Equal(t, "/* global */\n.g{}/* ick-testrc1 */\n.s1{}", ctx.CSSStyle(nil))
+	require.Len(t, ctx.CSSFiles(nil), 2)
+	assert.Equal(t, "/assets/s1.css", ctx.CSSFiles(nil)[1].String())
+
+	err = RenderChild(out, parent, &snip0{})
+	require.NoError(t, err)
+	assert.True(t, ctx.IsRendered(&snip0{}))
+	require.Len(t, ctx.CSSFiles(nil), 3)
+	assert.Equal(t, "/assets/s0.css", ctx.CSSFiles(nil)[2].String())
+	assert.Equal(t, "/* global */\n.g{}/* ick-testrc1 */\n.s1{}/* snip0 */\n.s0{}", ctx.CSSStyle(nil))
+
+	// requirements of the rendering
+	ctx.RequireCSSFile("/assets/page.css")
+	ctx.RequireCSSFile("/assets/site.css")
+	ctx.RequireCSSStyle("page", ".page{}")
+	ctx.RequireCSSStyle("snip0", ".ignored{}")
+	require.Len(t, ctx.CSSFiles(nil), 4)
+	assert.Equal(t, "/assets/page.css", ctx.CSSFiles(nil)[3].String())
+	assert.Equal(t, "/* global */\n.g{}/* ick-testrc1 */\n.s1{}/* snip0 */\n.s0{}/* page */\n.page{}", ctx.CSSStyle(nil))
+
+	// without context
+	var nilctx *RenderContext
+	nilctx.RequireCSSStyle("snip0", ".s0{}")
+	assert.Equal(t, "/* global */\n.g{}", nilctx.CSSStyle(nil))
+	assert.Len(t, nilctx.CSSFiles(nil), 1)
+	assert.False(t, nilctx.IsRendered(&snip0{}))
+}
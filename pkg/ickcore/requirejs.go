@@ -0,0 +1,197 @@
+package ickcore
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// JS_PLACEMENT defines where a required script is rendered within the page
+type JS_PLACEMENT int
+
+const (
+	JSPLACE_HEAD      JS_PLACEMENT = iota // in the head, after the CSS. The default.
+	JSPLACE_BODY                          // at the end of the body, before the wasm bootstrap script
+	JSPLACE_AFTERWASM                     // at the end of the body, after the wasm bootstrap script
+)
+
+// JSOptions defines how a required script is loaded
+type JSOptions struct {
+	Async     bool         // the script is fetched in parallel and evaluated as soon as it's available
+	Defer     bool         // the script is fetched in parallel and evaluated after the document has been parsed
+	Placement JS_PLACEMENT // where the script is rendered within the page
+}
+
+// RequiredScript is a script required by the rendering, either a file or an inline script.
+type RequiredScript struct {
+	JSOptions
+	Src    *url.URL // the url of the script file, nil for an inline script
+	Module bool     // the script is an ES module
+	Name   string   // the name of an inline script, used to render it only once
+	Inline string   // the content of an inline script
+}
+
+// key returns the key used to de-duplicate the scripts
+func (s RequiredScript) key() string {
+	if s.Src != nil {
+		return "src:" + s.Src.String()
+	}
+	return "inline:" + s.Name
+}
+
+// Render renders the <script> element to out. Inline scripts are escaped if AutoEscape is on.
+// The element is written at once, returns the error writing it if any.
+func (s RequiredScript) Render(out io.Writer) error {
+	sb := new(strings.Builder)
+	RenderString(sb, `<script`)
+	if s.Src != nil {
+		src := s.Src.String()
+		if AutoEscape {
+			src = EscapeString(ESCCTX_URL, src)
+		}
+		RenderString(sb, ` src="`, src, `"`)
+	}
+	RenderStringIf(s.Module, sb, ` type="module"`)
+	RenderStringIf(s.Async, sb, ` async`)
+	RenderStringIf(s.Defer, sb, ` defer`)
+	RenderString(sb, `>`)
+	if s.Src == nil {
+		script := s.Inline
+		if AutoEscape {
+			script = EscapeString(ESCCTX_SCRIPT, script)
+		}
+		RenderString(sb, script)
+	}
+	RenderString(sb, `</script>`)
+	_, err := io.WriteString(out, sb.String())
+	return err
+}
+
+var (
+	// _scripts are the scripts declared by the packages. Files are added to every page, inline scripts to the pages rendering their composers.
+	_scripts []RequiredScript
+
+	closingscriptRegexp = regexp.MustCompile(`(?i)</(script)`)
+)
+
+// escapeScript prevents the content of an inline script from closing the script element.
+func escapeScript(s string) string {
+	s = closingscriptRegexp.ReplaceAllString(s, `<\/$1`)
+	return strings.ReplaceAll(s, `<!--`, `<\!--`)
+}
+
+// RequireJSFile allows to declare a JavaScript file required by every page.
+// This can be call in the init function of a package defining custom snippets.
+// Use RenderContext.RequireJSFile to require a script only for the page being rendered.
+func RequireJSFile(jsURL string, opt JSOptions) {
+	requirejsfile(jsURL, false, opt)
+}
+
+// RequireJSModule allows to declare a JavaScript module required by every page. See RequireJSFile.
+func RequireJSModule(jsURL string, opt JSOptions) {
+	requirejsfile(jsURL, true, opt)
+}
+
+func requirejsfile(jsURL string, module bool, opt JSOptions) {
+	src, err := url.Parse(jsURL)
+	if jsURL == "" || err != nil {
+		return
+	}
+	_requiremu.Lock()
+	defer _requiremu.Unlock()
+	_scripts = appendscript(_scripts, RequiredScript{JSOptions: opt, Src: src, Module: module})
+}
+
+// RequireJSScript allows to declare an inline script required by a specific composer.
+// This can be call in the init function of a package defining custom snippets.
+//
+// name is either the registered ick-tag name of the composer, or the lowercase name of its type, like for RequireComposerCSSStyle.
+// The script is added only once to the pages rendering at least one composer matching the name.
+func RequireJSScript(name string, script string, opt JSOptions) {
+	_requiremu.Lock()
+	defer _requiremu.Unlock()
+	_scripts = appendscript(_scripts, RequiredScript{JSOptions: opt, Name: strings.ToLower(strings.TrimSpace(name)), Inline: script})
+}
+
+// RequiredScripts returns all the scripts declared with RequireJSFile, RequireJSModule and RequireJSScript.
+func RequiredScripts() []RequiredScript {
+	_requiremu.RLock()
+	defer _requiremu.RUnlock()
+	return append([]RequiredScript{}, _scripts...)
+}
+
+// appendscript appends s to scripts if not already there.
+func appendscript(scripts []RequiredScript, s RequiredScript) []RequiredScript {
+	key := s.key()
+	for _, rs := range scripts {
+		if rs.key() == key {
+			return scripts
+		}
+	}
+	return append(scripts, s)
+}
+
+/******************************************************************************/
+
+// RequireJSFile declares a JavaScript file required by the rendering.
+func (ctx *RenderContext) RequireJSFile(jsURL string, opt JSOptions) {
+	ctx.requirejsfile(jsURL, false, opt)
+}
+
+// RequireJSModule declares a JavaScript module required by the rendering.
+func (ctx *RenderContext) RequireJSModule(jsURL string, opt JSOptions) {
+	ctx.requirejsfile(jsURL, true, opt)
+}
+
+func (ctx *RenderContext) requirejsfile(jsURL string, module bool, opt JSOptions) {
+	src, err := url.Parse(jsURL)
+	if ctx == nil || jsURL == "" || err != nil {
+		return
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.scripts = appendscript(ctx.scripts, RequiredScript{JSOptions: opt, Src: src, Module: module})
+}
+
+// RequireJSScript declares an inline script required by the rendering.
+// The script is added once per name.
+func (ctx *RenderContext) RequireJSScript(name string, script string, opt JSOptions) {
+	if ctx == nil {
+		return
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.scripts = appendscript(ctx.scripts, RequiredScript{JSOptions: opt, Name: strings.ToLower(strings.TrimSpace(name)), Inline: script})
+}
+
+// Scripts returns the scripts to render at the placement, without duplicates and in the order they have been declared:
+// the files declared at the package level, the inline scripts declared at the package level for the composers rendered with this context,
+// then the scripts required by the rendering. A script required twice is rendered only at the placement of its first declaration.
+// reg is used to lookup the composers declared with their ick-tag name. DefaultRegistry is used if reg is nil.
+func (ctx *RenderContext) Scripts(reg *Registry, placement JS_PLACEMENT) []RequiredScript {
+	if reg == nil {
+		reg = DefaultRegistry
+	}
+	all := make([]RequiredScript, 0)
+	for _, s := range RequiredScripts() {
+		if s.Src != nil || ctx.isrendered(reg, s.Name) {
+			all = appendscript(all, s)
+		}
+	}
+	if ctx != nil {
+		ctx.mu.Lock()
+		for _, s := range ctx.scripts {
+			all = appendscript(all, s)
+		}
+		ctx.mu.Unlock()
+	}
+
+	scripts := make([]RequiredScript, 0)
+	for _, s := range all {
+		if s.Placement == placement {
+			scripts = append(scripts, s)
+		}
+	}
+	return scripts
+}
@@ -0,0 +1,74 @@
+package ickcore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredScripts(t *testing.T) {
+
+	// save and restore the declared requirements
+	_requiremu.Lock()
+	savscripts := _scripts
+	_scripts = nil
+	_requiremu.Unlock()
+	defer func() {
+		_requiremu.Lock()
+		_scripts = savscripts
+		_requiremu.Unlock()
+	}()
+
+	ResetRegistry()
+	RegisterComposer("ick-testrs1", &snip1{})
+
+	RequireJSFile("/assets/site.js", JSOptions{})
+	RequireJSFile("/assets/site.js", JSOptions{Placement: JSPLACE_BODY})
+	RequireJSModule("/assets/mod.js", JSOptions{Async: true, Placement: JSPLACE_BODY})
+	RequireJSScript("ick-testrs1", `console.log("s1")`, JSOptions{Placement: JSPLACE_AFTERWASM})
+	RequireJSScript("snip0", `console.log("s0")`, JSOptions{})
+	require.Len(t, RequiredScripts(), 4)
+
+	// nothing rendered, only files
+	ctx := NewRenderContext()
+	head := ctx.Scripts(nil, JSPLACE_HEAD)
+	require.Len(t, head, 1)
+	assert.Equal(t, "/assets/site.js", head[0].Src.String())
+	require.Len(t, ctx.Scripts(nil, JSPLACE_BODY), 1)
+	assert.Empty(t, ctx.Scripts(nil, JSPLACE_AFTERWASM))
+
+	// inline scripts of the rendered composers
+	s1 := &snip1{Test: 1}
+	s1.RMeta().Context = ctx
+	err := RenderChild(new(bytes.Buffer), nil, s1)
+	require.NoError(t, err)
+	afterwasm := ctx.Scripts(nil, JSPLACE_AFTERWASM)
+	require.Len(t, afterwasm, 1)
+	assert.Equal(t, `console.log("s1")`, afterwasm[0].Inline)
+	assert.Len(t, ctx.Scripts(nil, JSPLACE_HEAD), 1)
+
+	// requirements of the rendering
+	ctx.RequireJSFile("/assets/site.js", JSOptions{Placement: JSPLACE_AFTERWASM})
+	ctx.RequireJSFile("/assets/page.js", JSOptions{Defer: true})
+	ctx.RequireJSScript("page", `let a = "</script>"`, JSOptions{})
+	head = ctx.Scripts(nil, JSPLACE_HEAD)
+	require.Len(t, head, 3)
+	assert.Len(t, ctx.Scripts(nil, JSPLACE_AFTERWASM), 1)
+
+	out := new(bytes.Buffer)
+	for _, s := range head {
+		s.Render(out)
+	}
+	assert.Equal(t, `<script src="/assets/site.js"></script><script src="/assets/page.js" defer></script><script>let a = "<\/script>"</script>`, out.String())
+
+	out.Reset()
+	ctx.Scripts(nil, JSPLACE_BODY)[0].Render(out)
+	assert.Equal(t, `<script src="/assets/mod.js" type="module" async></script>`, out.String())
+
+	// without context
+	var nilctx *RenderContext
+	nilctx.RequireJSFile("/assets/ignored.js", JSOptions{})
+	assert.Len(t, nilctx.Scripts(nil, JSPLACE_HEAD), 1)
+}
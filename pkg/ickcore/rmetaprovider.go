@@ -18,81 +18,116 @@ type ComposerMap map[string]RMetaProvider
 
 // RMetaData is rendering metadata for a single HTMLContentComposer
 type RMetaData struct {
-	Deep      int           // deepness of the HTMLContentComposer
-	VirtualId string        // virtual id allocated to a Composer, always one
-	TagId     string        // the id allocated to the Composer if any
-	Parent    RMetaProvider // optional parent, may be an orphan
-	IsRender  bool          // Indicates the HTMLContentComposer has been rendered at least once
-	IsMounted bool          // Indicates the HTMLContentComposer has been mounted
-	RError    error         // rendering error if any
-
-	childs ComposerMap // embedded child content composer
+	Deep      int            // deepness of the HTMLContentComposer
+	VirtualId string         // virtual id allocated to a Composer, always one
+	TagId     string         // the id allocated to the Composer if any
+	Key       string         // optional user-provided key, unique among the siblings, used to generate the virtual id instead of the composer's position
+	Parent    RMetaProvider  // optional parent, may be an orphan
+	IsRender  bool           // Indicates the HTMLContentComposer has been rendered at least once
+	IsMounted bool           // Indicates the HTMLContentComposer has been mounted
+	RError    error          // rendering error if any
+	Data      any            // optional data context for template expressions, inherited by children. See RenderData.
+	Report    *RenderReport  // optional report collecting rendering diagnostics, inherited by children.
+	Registry  *Registry      // optional registry used to unfold ick-tags and generate ids, inherited by children. DefaultRegistry if nil.
+	Context   *RenderContext // optional context collecting the requirements of the rendered composers, inherited by children.
+	Trace     *RenderTracer  // optional tracer recording the rendering of the composers, inherited by children.
+
+	childs   ComposerMap    // embedded child content composer
+	rindexes map[string]int // index of the next child by composer type, to generate the virtual ids of the children
+	rootvid  string         // unique virtual id allocated the first time the composer is rendered without parent
 }
 
 func (rmeta *RMetaData) RMeta() *RMetaData {
 	return rmeta
 }
 
-// GenerateVirtualId generates a unique id for every rendering composer.
-// The composer may not have a TagBuilder, so the Id is not necessarly the id attribute of the composer. The generated id pattern is:
+// GenerateVirtualId generates the virtual id of cmp, rendered as a child of parent, and saves it in rmeta.
+// Every rendered composer gets a virtual id, even if it's not a TagBuilder and doesn't render an id attribute.
 //
-//	`{{{parentid|orphan}.[cmpname.index]}|[cmpid]}`
+// The virtual id only depends on the position of the composer in the rendering tree, or on a user-provided key,
+// so it's the same whatever the rendering order and whatever has been rendered before. The generated id pattern is:
+//
+//	`[{parentvid}.]{tagid|key|cmpname{index}}`
 //
 // rules:
-//   - if the rendering has a rendering parent, the virtual id starts with the parent's virtualid otherwise it's "orphan" followed by the component name
-//   - if the rendering does not have a rendering parent, the virtual id is the given cmpid if not empty, otherwise it's "orphan"
-//   - the dot "-" seperator is added followed by the cmpname if any
+//   - if the composer has a TagId, an id attribute, its virtual id is its TagId, without the parent's virtual id prefix. HTML ids are expected to be unique within a page.
+//   - otherwise the last segment is the Key of the composer if any. Keys must be unique among the siblings.
+//   - otherwise the last segment is the lowercase name of the composer's type followed by its index among the siblings of the same type,
+//     in the order they are rendered by the parent, starting at 0.
+//   - a composer rendered without parent, a root, gets a unique index from the DefaultRegistry the first time it's rendered, and keeps it.
+//   - if the parent has a virtual id, the last segment is prefixed with the parent's virtual id and a dot.
 //
-// - the cmpname is added
-// TODO: ickcore - GenerateVirtualId need to be reworked
-func (rmeta *RMetaData) GenerateVirtualId(cmp RMetaProvider) string {
-	prefix := "orphan"
-	if rmeta.Parent != nil {
-		if pvid := rmeta.Parent.RMeta().VirtualId; pvid != "" {
-			prefix = pvid
-		}
-	}
-	prefix += "."
-	toporphan := strings.HasPrefix(prefix, "orphan.")
-
-	cmpname := reflect.TypeOf(cmp).Elem().Name()
-	cmpname = strings.ToLower(cmpname)
+// The virtual ids of a page look like this: `ickelem0.ickcard0.htmlstring1.ickbutton0`.
+// Virtual ids are unique within a page as long as TagIds and Keys are unique.
+func (rmeta *RMetaData) GenerateVirtualId(parent RMetaProvider, cmp RMetaProvider) string {
+	return rmeta.generatevid(parent, cmp, DefaultRegistry)
+}
 
-	body := cmpname
+// generatevid generates the virtual id of cmp like GenerateVirtualId, the unique index of a root being generated by reg.
+func (rmeta *RMetaData) generatevid(parent RMetaProvider, cmp RMetaProvider, reg *Registry) string {
 	cmpid := cmp.RMeta().TagId
 	if cmpid != "" {
-		body = cmpid
-		if toporphan {
-			toporphan = false
-			prefix = ""
-		}
+		rmeta.VirtualId = cmpid
+		return rmeta.VirtualId
 	}
 
-	index := 0
-	if rmeta.Parent != nil {
-		index = len(rmeta.Parent.RMeta().Embedded())
-	} else {
-		index, _ = GetUniqueId(cmpname)
+	segment := cmp.RMeta().Key
+	if segment == "" {
+		cmpname := reflect.TypeOf(cmp).Elem().Name()
+		cmpname = strings.ToLower(cmpname)
+		if parent != nil {
+			segment = cmpname + strconv.Itoa(parent.RMeta().nextindex(cmpname))
+		} else {
+			if rmeta.rootvid == "" {
+				index, _ := reg.UniqueId(cmpname)
+				rmeta.rootvid = cmpname + strconv.Itoa(index)
+			}
+			segment = rmeta.rootvid
+		}
 	}
 
-	suffix := ""
-	if cmpid == "" || toporphan {
-		suffix = strconv.Itoa(index)
+	prefix := ""
+	if parent != nil {
+		if pvid := parent.RMeta().VirtualId; pvid != "" {
+			prefix = pvid + "."
+		}
 	}
-
-	rmeta.VirtualId = prefix + body + suffix
+	rmeta.VirtualId = prefix + segment
 
 	// DEBUG: verbose.Debug("GenerateVirtualId: vid=%s, id=%s", rmeta.VirtualId, cmpid)
 
 	return rmeta.VirtualId
 }
 
+// nextindex returns the index of the next child of type cmpname rendered by this parent
+func (rmeta *RMetaData) nextindex(cmpname string) int {
+	if rmeta.rindexes == nil {
+		rmeta.rindexes = make(map[string]int, 1)
+	}
+	index := rmeta.rindexes[cmpname]
+	rmeta.rindexes[cmpname]++
+	return index
+}
+
+// ResetEmbedded clears the embedded children and the indexes used to generate the virtual ids of the children.
+// The rendering process calls it before rendering the content of a composer, so children get the same virtual ids at every rendering.
+// A composer rendering its content outside of the rendering process, like a Page, must call it before rendering its children.
+func (rmeta *RMetaData) ResetEmbedded() {
+	rmeta.childs = nil
+	rmeta.rindexes = nil
+}
+
 // func (rmeta *RMetaData) NeedRendering() bool { return true }
 
 // Embed adds child to the map of embedded components.
 // If a child with the same key has already been embedded it's replaced and a warning is raised in verbose mode.
 // The key is the id of the html element if any, otherwise it's its virtual id.
 func (rmeta *RMetaData) Embed(child RMetaProvider) {
+	rmeta.embed(child)
+}
+
+// embed adds child to the map of embedded components, and returns its key if a child with the same key was already embedded.
+func (rmeta *RMetaData) embed(child RMetaProvider) (duplicate string) {
 	if rmeta.childs == nil {
 		rmeta.childs = make(ComposerMap, 1)
 	}
@@ -104,11 +139,22 @@ func (rmeta *RMetaData) Embed(child RMetaProvider) {
 	if key != "" {
 		if _, f := rmeta.childs[key]; f {
 			verbose.Printf(verbose.WARNING, "Embed: duplicate child id:%q for parent id:%q\n", key, rmeta.VirtualId)
+			duplicate = key
 		}
 		rmeta.childs[key] = child
 	} else {
 		verbose.Debug("Embed: %v key missing", reflect.TypeOf(child).String())
 	}
+	return duplicate
+}
+
+// Unembed removes child from the map of embedded components.
+func (rmeta *RMetaData) Unembed(child RMetaProvider) {
+	for k, v := range rmeta.childs {
+		if v == child {
+			delete(rmeta.childs, k)
+		}
+	}
 }
 
 // Embedded returns the map of embedded components, keyed by their id.
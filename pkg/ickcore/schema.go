@@ -0,0 +1,196 @@
+package ickcore
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SchemaDocumenter can be implemented by a composer to document its ick-tag in its schema.
+type SchemaDocumenter interface {
+	// SchemaDoc returns the doc of the composer and the doc of its properties, by go field's name.
+	SchemaDoc() (doc string, fields map[string]string)
+}
+
+// AttributeDescriber can be implemented by an AttributeUnfolder to describe the attributes it unfolds itself in its schema.
+// A described attribute with the name of a property overrides its kind and its doc, other described attributes are added.
+// The name "*" describes any other attribute of the ick-tag.
+type AttributeDescriber interface {
+	DescribeAttributes() []PropertySchema
+}
+
+// PropertySchema describes an ick-tag attribute of a composer.
+type PropertySchema struct {
+	Name     string   `json:"name"`               // attribute's name
+	Field    string   `json:"field"`              // go field's name
+	Type     string   `json:"type"`               // go type of the field
+	Kind     string   `json:"kind"`               // how the attribute's value is unfolded, see PropertyKind
+	Required bool     `json:"required,omitempty"` // the attribute must be provided
+	Default  *string  `json:"default,omitempty"`  // default attribute's value, nil if none
+	Enum     []string `json:"enum,omitempty"`     // allowed values, declared with RegisterEnum
+	Doc      string   `json:"doc,omitempty"`
+}
+
+// ComposerSchema describes a registered composer and its ick-tag.
+type ComposerSchema struct {
+	IckTagName string           `json:"icktagname"`
+	Type       string           `json:"type"`           // go type of the composer
+	Doc        string           `json:"doc,omitempty"`  // doc of the composer, see SchemaDocumenter
+	Slot       bool             `json:"slot,omitempty"` // the composer receives the content of a paired ick-tag, see SlotReceiver
+	Properties []PropertySchema `json:"properties"`
+}
+
+// Property returns the schema of the attribute's name, or of the go field's name. Returns nil if not found.
+func (s ComposerSchema) Property(name string) *PropertySchema {
+	for i, p := range s.Properties {
+		if p.Field == name || p.Name == strings.ToLower(name) {
+			return &s.Properties[i]
+		}
+	}
+	return nil
+}
+
+var (
+	_enumsmu sync.RWMutex
+	_enums   = make(map[reflect.Type][]string)
+)
+
+// RegisterEnum declares the values allowed for the properties of the type of zero, a string type like ick.COLOR.
+// Every value can be a space separated list of values, like the *_OPTIONS constants. Empty values are ignored.
+// This can be call in the init function of a package defining the type.
+//
+//	ickcore.RegisterEnum(ick.COLOR_NONE, ick.COLOR_OPTIONS)
+func RegisterEnum(zero any, values ...string) {
+	enum := make([]string, 0)
+	for _, v := range values {
+		enum = append(enum, strings.Fields(v)...)
+	}
+	_enumsmu.Lock()
+	defer _enumsmu.Unlock()
+	_enums[reflect.TypeOf(zero)] = enum
+}
+
+// EnumValues returns the values declared with RegisterEnum for typ, nil if none.
+func EnumValues(typ reflect.Type) []string {
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	_enumsmu.RLock()
+	defer _enumsmu.RUnlock()
+	return _enums[typ]
+}
+
+// PropertyKind returns how an attribute's value is unfolded into a field of type typ, like updateproperty does:
+// "text" for encoding.TextUnmarshaler, "html" for HTMLString and interfaces, "duration", "url", "string", "integer", "number", "boolean",
+// "array" or "object" for JSON values.
+func PropertyKind(typ reflect.Type) string {
+	if reflect.PointerTo(typ).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+		return "text"
+	}
+	switch typ.String() {
+	case "ickcore.HTMLString":
+		return "html"
+	case "time.Duration":
+		return "duration"
+	case "url.URL", "*url.URL":
+		return "url"
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Pointer:
+		return PropertyKind(typ.Elem())
+	case reflect.Interface:
+		return "html"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// Schema returns the schema of the registered composer.
+func (_r *RegistryEntry) Schema() ComposerSchema {
+	s := ComposerSchema{IckTagName: _r.icktagname, Properties: make([]PropertySchema, 0)}
+	if _r.cmp == nil {
+		return s
+	}
+	s.Type = reflect.TypeOf(_r.cmp).String()
+	_, s.Slot = _r.cmp.(SlotReceiver)
+
+	var fielddocs map[string]string
+	if doc, is := _r.cmp.(SchemaDocumenter); is {
+		s.Doc, fielddocs = doc.SchemaDoc()
+	}
+
+	for _, p := range _r.props.list {
+		ps := PropertySchema{
+			Name:     p.Name,
+			Field:    p.Field,
+			Type:     p.Type.String(),
+			Kind:     PropertyKind(p.Type),
+			Required: p.Required,
+			Enum:     EnumValues(p.Type),
+			Doc:      fielddocs[p.Field],
+		}
+		if p.HasDefault {
+			def := p.Default
+			ps.Default = &def
+		}
+		s.Properties = append(s.Properties, ps)
+	}
+
+	if d, is := _r.cmp.(AttributeDescriber); is {
+	nextattr:
+		for _, attr := range d.DescribeAttributes() {
+			for i, p := range s.Properties {
+				if p.Name == attr.Name {
+					s.Properties[i].Kind = attr.Kind
+					if attr.Doc != "" {
+						s.Properties[i].Doc = attr.Doc
+					}
+					continue nextattr
+				}
+			}
+			s.Properties = append(s.Properties, attr)
+		}
+	}
+	return s
+}
+
+// Schemas returns the schemas of all the registered composers, by ick-tag name.
+func (reg *Registry) Schemas() map[string]ComposerSchema {
+	schemas := make(map[string]ComposerSchema)
+	for name, entry := range reg.Map() {
+		schemas[name] = entry.Schema()
+	}
+	return schemas
+}
+
+// SchemasJSON returns the schemas of all the registered composers as a JSON array sorted by ick-tag name.
+func (reg *Registry) SchemasJSON() ([]byte, error) {
+	schemas := make([]ComposerSchema, 0)
+	for _, s := range reg.Schemas() {
+		schemas = append(schemas, s)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].IckTagName < schemas[j].IckTagName })
+	return json.MarshalIndent(schemas, "", "  ")
+}
+
+// Schemas returns the schemas of all the composers registered in the DefaultRegistry, by ick-tag name.
+func Schemas() map[string]ComposerSchema {
+	return DefaultRegistry.Schemas()
+}
+
+// SchemasJSON returns the schemas of all the composers registered in the DefaultRegistry as JSON. See Registry.SchemasJSON.
+func SchemasJSON() ([]byte, error) {
+	return DefaultRegistry.SchemasJSON()
+}
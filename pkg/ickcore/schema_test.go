@@ -0,0 +1,123 @@
+package ickcore
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tstCOLOR string
+
+type sniph9 struct {
+	BareSnippet
+	Color   tstCOLOR `ick:",default=red"`
+	Content HTMLString
+}
+
+func (s *sniph9) SchemaDoc() (string, map[string]string) {
+	return "sniph9 is a test snippet", map[string]string{"Color": "the color"}
+}
+
+func (s *sniph9) SetSlot(name string, content *HTMLString) error {
+	s.Content = *content
+	return nil
+}
+
+type sniph11 struct {
+	BareSnippet
+	Level  int               `ick:",default=1"`
+	Params map[string]string `ick:"-"`
+}
+
+func (s *sniph11) UnfoldAttribute(name string, value string) error { return ErrAttributeNotUnfolded }
+
+func (s *sniph11) DescribeAttributes() []PropertySchema {
+	return []PropertySchema{
+		{Name: "level", Kind: "string", Doc: "a level or a range"},
+		{Name: "*", Field: "Params", Type: "map[string]string", Kind: "string"},
+	}
+}
+
+func TestSchema(t *testing.T) {
+	RegisterEnum(tstCOLOR(""), "red green", "", "blue")
+	assert.Equal(t, []string{"red", "green", "blue"}, EnumValues(reflect.TypeOf(tstCOLOR(""))))
+	assert.Nil(t, EnumValues(reflect.TypeOf("")))
+
+	reg := NewRegistry()
+	_, err := reg.Register("ick-tstsniph7", &sniph7{})
+	require.NoError(t, err)
+	_, err = reg.Register("ick-tstsniph9", &sniph9{})
+	require.NoError(t, err)
+
+	schemas := reg.Schemas()
+	require.Len(t, schemas, 2)
+
+	s7 := schemas["ick-tstsniph7"]
+	assert.Equal(t, "*ickcore.sniph7", s7.Type)
+	assert.False(t, s7.Slot)
+	assert.Empty(t, s7.Doc)
+	key := s7.Property("key")
+	require.NotNil(t, key)
+	assert.True(t, key.Required)
+	assert.Nil(t, key.Default)
+	lbl := s7.Property("Label")
+	require.NotNil(t, lbl)
+	assert.Equal(t, "lbl", lbl.Name)
+	require.NotNil(t, lbl.Default)
+	assert.Equal(t, "hello, world", *lbl.Default)
+	assert.Equal(t, "integer", s7.Property("level").Kind)
+	assert.Equal(t, "boolean", s7.Property("is-hidden").Kind)
+	assert.Nil(t, s7.Property("ignored"))
+
+	s9 := schemas["ick-tstsniph9"]
+	assert.True(t, s9.Slot)
+	assert.Equal(t, "sniph9 is a test snippet", s9.Doc)
+	color := s9.Property("color")
+	require.NotNil(t, color)
+	assert.Equal(t, "the color", color.Doc)
+	assert.Equal(t, "ickcore.tstCOLOR", color.Type)
+	assert.Equal(t, []string{"red", "green", "blue"}, color.Enum)
+	assert.Equal(t, "html", s9.Property("content").Kind)
+
+	data, err := reg.SchemasJSON()
+	require.NoError(t, err)
+	var decoded []ComposerSchema
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded, 2)
+	assert.Equal(t, "ick-tstsniph7", decoded[0].IckTagName)
+	assert.Equal(t, s9, decoded[1])
+
+	// described attributes override the kind and the doc of the properties with the same name
+	reg = NewRegistry()
+	entry, err := reg.Register("ick-tstsniph11", &sniph11{})
+	require.NoError(t, err)
+	s11 := entry.Schema()
+	require.Len(t, s11.Properties, 2)
+	level := s11.Property("level")
+	assert.Equal(t, "Level", level.Field)
+	assert.Equal(t, "int", level.Type)
+	assert.Equal(t, "string", level.Kind)
+	assert.Equal(t, "a level or a range", level.Doc)
+	require.NotNil(t, level.Default)
+	assert.Equal(t, "1", *level.Default)
+	assert.Equal(t, "Params", s11.Property("*").Field)
+}
+
+func TestPropertyKind(t *testing.T) {
+	tst := []struct {
+		v    any
+		want string
+	}{
+		{"", "string"}, {tstCOLOR(""), "string"}, {0, "integer"}, {uint8(0), "integer"}, {0.0, "number"}, {false, "boolean"},
+		{time.Second, "duration"}, {time.Time{}, "text"}, {url.URL{}, "url"}, {&url.URL{}, "url"},
+		{HTMLString{}, "html"}, {[]int{}, "array"}, {map[string]int{}, "object"}, {struct{}{}, "object"}, {new(int), "integer"},
+	}
+	for _, tc := range tst {
+		assert.Equal(t, tc.want, PropertyKind(reflect.TypeOf(tc.v)), "%T", tc.v)
+	}
+}
@@ -0,0 +1,257 @@
+package ickcore
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SLOT_TAGNAME is the ick-tag used to name a part of the inner content of a paired ick-tag.
+//
+//	<ick-card><ick-slot name="title">Hello</ick-slot><p>body</p></ick-card>
+const SLOT_TAGNAME string = "ick-slot"
+
+// SlotReceiver can be implemented by a composer willing to handle the inner content of a paired ick-tag itself.
+// SetSlot is called once per named slot, and once with an empty name for the default content if it's not blank.
+// Return an error if the slot can't be received, the ick-tag is then rendered as an HTML comment.
+type SlotReceiver interface {
+	SetSlot(name string, content *HTMLString) error
+}
+
+// slot is a named part of the inner content of a paired ick-tag
+type slot struct {
+	name    string
+	content []byte
+}
+
+// scanicktag scans the ick-tag starting at htmlstring[at], htmlstring[at:] must start with "<ick-" or "</ick-".
+// Returns the lowercase ick-tagname, the raw attributes string and the position of the last byte of the tag, the ending '>'.
+// selfclosing is true if the tag ends with "/>".
+// Quoted attribute's values are skipped so they can contain '>'.
+func scanicktag(htmlstring []byte, at int) (ickname string, rawattrs string, end int, selfclosing bool, err error) {
+	i := at + 1
+	if i < len(htmlstring) && htmlstring[i] == '/' {
+		i++
+	}
+	namefrom := i
+	for ; i < len(htmlstring); i++ {
+		if b := htmlstring[i]; b == ' ' || b == '\n' || b == '\t' || b == '>' || b == '/' {
+			break
+		}
+	}
+	ickname = strings.ToLower(string(htmlstring[namefrom:i]))
+	attrsfrom := i
+
+	var bquote byte
+	for ; i < len(htmlstring); i++ {
+		b := htmlstring[i]
+		switch {
+		case bquote != 0:
+			if b == bquote {
+				bquote = 0
+			}
+		case b == '"' || b == '\'':
+			bquote = b
+		case b == '>':
+			selfclosing = htmlstring[i-1] == '/'
+			attrsto := i
+			if selfclosing {
+				attrsto--
+			}
+			rawattrs = strings.Trim(string(htmlstring[mini(attrsfrom, attrsto):attrsto]), " \n\t")
+			return ickname, rawattrs, i, selfclosing, nil
+		}
+	}
+	return ickname, "", len(htmlstring) - 1, false, &IckTagNameError{TagName: ickname, Message: "tag not closed"}
+}
+
+// matchclosingick looks for the closing tag of the ickname paired tag, starting at position from, just after the opening tag.
+// Nested paired ick-tags are skipped.
+// Returns the position of the first byte of the closing tag (the end of the inner content) and the position of its last byte.
+func matchclosingick(htmlstring []byte, from int, ickname string) (bodyto int, closeto int, err error) {
+	depth := 0
+	for i := from; i < len(htmlstring); i++ {
+		switch {
+		case bytes.HasPrefix(htmlstring[i:], []byte("</ick-")):
+			name, _, end, _, errs := scanicktag(htmlstring, i)
+			if errs != nil {
+				return 0, 0, errs
+			}
+			if depth == 0 {
+				if name != ickname {
+					return 0, 0, &IckTagNameError{TagName: ickname, Message: fmt.Sprintf("closing tag mismatch, found %q", name)}
+				}
+				return i, end, nil
+			}
+			depth--
+			i = end
+		case bytes.HasPrefix(htmlstring[i:], []byte("<ick-")):
+			_, _, end, selfclosing, errs := scanicktag(htmlstring, i)
+			if errs != nil {
+				return 0, 0, errs
+			}
+			if !selfclosing {
+				depth++
+			}
+			i = end
+		}
+	}
+	return 0, 0, &IckTagNameError{TagName: ickname, Message: "closing tag missing"}
+}
+
+// closingickname returns the ick-tagname of the closing tag at the beginning of htmlstring
+func closingickname(htmlstring []byte) string {
+	name, _, _, _, _ := scanicktag(htmlstring, 0)
+	return name
+}
+
+// splitslots extracts the top level named slots from the inner content of a paired ick-tag.
+// The remaining content is returned as the default content.
+func splitslots(body []byte) (dft []byte, slots []slot, err error) {
+	dft = make([]byte, 0, len(body))
+	slots = make([]slot, 0)
+	last := 0
+	for i := 0; i < len(body); i++ {
+		if !bytes.HasPrefix(body[i:], []byte("<ick-")) {
+			continue
+		}
+		name, rawattrs, end, selfclosing, errs := scanicktag(body, i)
+		if errs != nil {
+			return nil, nil, errs
+		}
+		if selfclosing {
+			i = end
+			continue
+		}
+		bodyto, closeto, errs := matchclosingick(body, end+1, name)
+		if errs != nil {
+			return nil, nil, errs
+		}
+		if name == SLOT_TAGNAME {
+			attrs, erra := TryParseAttributes(rawattrs)
+			if erra != nil {
+				return nil, nil, erra
+			}
+			sname := attrs.Name()
+			if sname == "" {
+				return nil, nil, fmt.Errorf("%s: name attribute missing", SLOT_TAGNAME)
+			}
+			slots = append(slots, slot{name: sname, content: body[end+1 : bodyto]})
+			dft = append(dft, body[last:i]...)
+			last = closeto + 1
+		}
+		i = closeto
+	}
+	dft = append(dft, body[last:]...)
+	return dft, slots, nil
+}
+
+// setslots hands the inner content of a paired ick-tag to the composer cmp.
+//
+// If the composer implements the SlotReceiver interface, slots are handed to SetSlot. Otherwise:
+// Named slots `<ick-slot name="{name}">` are assigned to the composer's property matching the name, see ickproperty,
+// or to the exported composer's field matching the name, case insensitive.
+// The default content, what is not in a named slot, is pushed to the first ContentStack found in the composer's fields.
+// The default content is ignored if it's only made of blanks.
+//
+// See setslot for the field types that can receive a slot.
+func setslots(cmp reflect.Value, props *ickproperties, body []byte) error {
+	dft, slots, err := splitslots(body)
+	if err != nil {
+		return err
+	}
+	blank := len(bytes.Trim(dft, " \n\t\r")) == 0
+
+	if receiver, ok := cmp.Interface().(SlotReceiver); ok {
+		for _, s := range slots {
+			if err := receiver.SetSlot(s.name, ToHTML(string(s.content))); err != nil {
+				return fmt.Errorf("slot %q: %w", s.name, err)
+			}
+		}
+		if !blank {
+			return receiver.SetSlot("", ToHTML(string(dft)))
+		}
+		return nil
+	}
+
+	for _, s := range slots {
+		var field reflect.Value
+		if p := props.lookup(s.name); p != nil {
+			field = cmp.Elem().FieldByIndex(p.index)
+		} else {
+			field = slotfield(cmp.Elem(), s.name)
+		}
+		if !field.IsValid() {
+			return fmt.Errorf("slot %q: no matching property", s.name)
+		}
+		if err := setslot(field, s.content); err != nil {
+			return fmt.Errorf("slot %q: %w", s.name, err)
+		}
+	}
+
+	if !blank {
+		stack := contentstackfield(cmp.Elem(), 0)
+		if stack == nil {
+			return fmt.Errorf("composer does not accept content")
+		}
+		stack.Push(ToHTML(string(dft)))
+	}
+	return nil
+}
+
+// setslot assigns content to the field. Managed field's types are:
+//   - HTMLString, ContentComposer or string: the content replaces the value
+//   - ContentStack or a struct embedding a ContentStack: the content is pushed to the stack
+//   - []HTMLString: the content is appended
+func setslot(field reflect.Value, content []byte) error {
+	switch field.Type() {
+	case reflect.TypeOf(HTMLString{}):
+		field.Set(reflect.ValueOf(*ToHTML(string(content))))
+		return nil
+	case reflect.TypeOf([]HTMLString{}):
+		field.Set(reflect.Append(field, reflect.ValueOf(*ToHTML(string(content)))))
+		return nil
+	case reflect.TypeOf((*ContentComposer)(nil)).Elem():
+		field.Set(reflect.ValueOf(ToHTML(string(content))))
+		return nil
+	}
+	if field.Kind() == reflect.String {
+		field.SetString(string(content))
+		return nil
+	}
+	if stack := contentstackfield(field, 0); stack != nil {
+		stack.Push(ToHTML(string(content)))
+		return nil
+	}
+	return fmt.Errorf("unmanaged type %s", field.Type().String())
+}
+
+// slotfield returns the exported field of the struct v matching name, case insensitive.
+// Returns an invalid value if not found.
+func slotfield(v reflect.Value, name string) reflect.Value {
+	name = strings.ToLower(name)
+	return v.FieldByNameFunc(func(fname string) bool {
+		return strings.ToLower(fname) == name
+	})
+}
+
+// contentstackfield returns a reference to v if it's a ContentStack, or to the first ContentStack found in its exported fields.
+// Looks into nested structs up to 2 levels.
+func contentstackfield(v reflect.Value, deep int) *ContentStack {
+	if v.Type() == reflect.TypeOf(ContentStack{}) {
+		return v.Addr().Interface().(*ContentStack)
+	}
+	if v.Kind() != reflect.Struct || deep > 2 {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if stack := contentstackfield(v.Field(i), deep+1); stack != nil {
+			return stack
+		}
+	}
+	return nil
+}
@@ -69,6 +69,11 @@ func (tag *Tag) SetSelfClosing(sc bool) *Tag {
 	return tag
 }
 
+// IsSelfClosing returns true if the tag has no closing tag.
+func (tag Tag) IsSelfClosing() bool {
+	return tag.selfClosing
+}
+
 // ParseAttributes tries to parse attributes to the tag and ignore errors.
 // alist will be added or will update existing tag attributes.
 // errors are logged out if verbose mode is on.
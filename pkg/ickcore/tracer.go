@@ -0,0 +1,285 @@
+package ickcore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceSpan records the rendering of a single composer.
+type TraceSpan struct {
+	Label      string        `json:"label,omitempty"`      // optional label, like the URL of a rendered page
+	Type       string        `json:"type"`                 // the type of the composer
+	IckTagName string        `json:"icktagname,omitempty"` // the ick-tag name of the composer, if it's registered
+	VirtualId  string        `json:"vid,omitempty"`        // the virtual id of the composer
+	Deep       int           `json:"deep"`                 // the rendering depth of the composer
+	Start      time.Time     `json:"start"`                // when the rendering started
+	Duration   time.Duration `json:"duration"`             // rendering duration in nanoseconds, including the children
+	Bytes      int           `json:"bytes"`                // number of bytes written, including the children. 0 if rendered into a NodeBuilder
+	Children   []*TraceSpan  `json:"children,omitempty"`   // spans of the composers rendered by this one
+	Err        string        `json:"error,omitempty"`      // the rendering error, if any
+
+	out *countwriter
+}
+
+// clone returns a deep copy of the span and its children
+func (span *TraceSpan) clone() *TraceSpan {
+	c := *span
+	c.out = nil
+	if span.Children != nil {
+		c.Children = make([]*TraceSpan, len(span.Children))
+		for i, child := range span.Children {
+			c.Children[i] = child.clone()
+		}
+	}
+	return &c
+}
+
+// Self returns the rendering duration of the composer itself, excluding its children.
+func (span *TraceSpan) Self() time.Duration {
+	d := span.Duration
+	for _, c := range span.Children {
+		d -= c.Duration
+	}
+	return d
+}
+
+// String returns the span in the form:
+//
+//	`{label|vid} {type} [{ick-tagname}] {duration} (self {duration}) {bytes}B {n} children`
+func (span *TraceSpan) String() string {
+	name := span.Label
+	if name == "" {
+		name = span.VirtualId
+	}
+	s := name + " " + span.Type
+	if span.IckTagName != "" {
+		s += " <" + span.IckTagName + ">"
+	}
+	s += fmt.Sprintf(" %v (self %v) %dB %d children", span.Duration, span.Self(), span.Bytes, len(span.Children))
+	if span.Err != "" {
+		s += " error: " + span.Err
+	}
+	return s
+}
+
+// RenderTracer records the duration, the number of bytes written and the children of every rendered composer.
+// A tracer is bound to a composer with the Trace field of its rendering metadata, and is inherited by its children.
+// The recorded spans can be written as a tree, as JSON, or in the Chrome trace event format, to be loaded in chrome://tracing or Perfetto.
+//
+// The zero value is an empty tracer ready to use. A nil tracer records nothing. RenderTracer is safe for concurrent use.
+type RenderTracer struct {
+	// MaxRoots is the maximum number of root spans kept by the tracer, the oldest ones are dropped first. No limit if 0.
+	MaxRoots int
+
+	mu    sync.Mutex
+	roots []*TraceSpan
+}
+
+// Begin starts recording the rendering of cmp into out, as a child of the span bound to out if any. label is optional.
+// Returns the span and a writer to use instead of out: it counts the bytes written, unless out is a NodeBuilder,
+// and it's bound to the span so the composers rendered into it are recorded as its children.
+// The rendering process calls it for every composer, a composer rendering its content outside of the rendering process, like a Page, can call it.
+// Every Begin must be followed by an End.
+func (t *RenderTracer) Begin(label string, cmp RMetaProvider, out io.Writer) (*TraceSpan, io.Writer) {
+	if t == nil || cmp == nil {
+		return nil, out
+	}
+	span := &TraceSpan{
+		Label: label,
+		Type:  reflect.TypeOf(cmp).String(),
+		Deep:  cmp.RMeta().Deep,
+		Start: time.Now(),
+	}
+	if entry := CurrentRegistry(out).Lookup(cmp); entry != nil {
+		span.IckTagName = entry.IckTagName()
+	}
+	st := stateof(out).inherit(nil)
+	parent := st.span
+	if st.tracer != t {
+		parent = nil
+	}
+	st.tracer, st.span = t, span
+	if _, isbuilder := out.(NodeBuilder); !isbuilder && out != nil {
+		span.out = &countwriter{w: out}
+		out = span.out
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if parent != nil {
+		parent.Children = append(parent.Children, span)
+	} else {
+		if t.MaxRoots > 0 && len(t.roots) >= t.MaxRoots {
+			t.roots = append(t.roots[:0], t.roots[len(t.roots)-t.MaxRoots+1:]...)
+		}
+		t.roots = append(t.roots, span)
+	}
+	return span, st.bind(out)
+}
+
+// End ends recording the rendering of cmp started with Begin.
+func (t *RenderTracer) End(span *TraceSpan, cmp RMetaProvider) {
+	if t == nil || span == nil || cmp == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span.Duration = time.Since(span.Start)
+	span.VirtualId = cmp.RMeta().VirtualId
+	if span.out != nil {
+		span.Bytes = span.out.n
+		span.out = nil
+	}
+	if err := cmp.RMeta().RError; err != nil {
+		span.Err = err.Error()
+	}
+}
+
+// Roots returns the spans of the composers rendered without traced parent, in rendering order.
+// The returned spans are a copy of the recorded ones, children included, so they can be read while the tracer keeps recording.
+func (t *RenderTracer) Roots() []*TraceSpan {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	roots := make([]*TraceSpan, len(t.roots))
+	for i, span := range t.roots {
+		roots[i] = span.clone()
+	}
+	return roots
+}
+
+// Spans returns a copy of all the recorded spans, depth first in rendering order.
+func (t *RenderTracer) Spans() []*TraceSpan {
+	spans := make([]*TraceSpan, 0)
+	var walk func([]*TraceSpan)
+	walk = func(s []*TraceSpan) {
+		for _, span := range s {
+			spans = append(spans, span)
+			walk(span.Children)
+		}
+	}
+	walk(t.Roots())
+	return spans
+}
+
+// Slowest returns the n spans with the longest Self duration, the slowest first. All the spans are returned if n <= 0.
+func (t *RenderTracer) Slowest(n int) []*TraceSpan {
+	spans := t.Spans()
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Self() > spans[j].Self() })
+	if n > 0 && n < len(spans) {
+		spans = spans[:n]
+	}
+	return spans
+}
+
+// Reset clears all the recorded spans.
+func (t *RenderTracer) Reset() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.roots = nil
+}
+
+// WriteTree writes the spans to w, one per line, indented according to their depth in the tree.
+func (t *RenderTracer) WriteTree(w io.Writer) error {
+	var walk func([]*TraceSpan, int) error
+	walk = func(s []*TraceSpan, level int) error {
+		for _, span := range s {
+			if _, err := io.WriteString(w, strings.Repeat("  ", level)+span.String()+"\n"); err != nil {
+				return err
+			}
+			if err := walk(span.Children, level+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(t.Roots(), 0)
+}
+
+// WriteSlowest writes the n slowest spans to w, one per line, see Slowest.
+func (t *RenderTracer) WriteSlowest(w io.Writer, n int) error {
+	for i, span := range t.Slowest(n) {
+		if _, err := fmt.Fprintf(w, "%2d. %s\n", i+1, span.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the tree of spans to w as a JSON array of the root spans.
+func (t *RenderTracer) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(t.Roots())
+}
+
+// chromeevent is a complete event of the Chrome trace event format
+type chromeevent struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat"`
+	Ph   string         `json:"ph"`
+	Ts   float64        `json:"ts"`  // start in microseconds
+	Dur  float64        `json:"dur"` // duration in microseconds
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// WriteChromeTrace writes the spans to w in the Chrome trace event format.
+// Timestamps are relative to the start of the first span.
+func (t *RenderTracer) WriteChromeTrace(w io.Writer) error {
+	spans := t.Spans()
+	var origin time.Time
+	for _, span := range spans {
+		if origin.IsZero() || span.Start.Before(origin) {
+			origin = span.Start
+		}
+	}
+
+	events := make([]chromeevent, 0, len(spans))
+	for _, span := range spans {
+		name := span.IckTagName
+		if name == "" {
+			name = span.Type
+		}
+		if span.Label != "" {
+			name = span.Label
+		}
+		args := map[string]any{"vid": span.VirtualId, "type": span.Type, "bytes": span.Bytes, "children": len(span.Children)}
+		if span.Err != "" {
+			args["error"] = span.Err
+		}
+		events = append(events, chromeevent{
+			Name: name,
+			Cat:  "render",
+			Ph:   "X",
+			Ts:   float64(span.Start.Sub(origin).Nanoseconds()) / 1e3,
+			Dur:  float64(span.Duration.Nanoseconds()) / 1e3,
+			Pid:  1,
+			Tid:  1,
+			Args: args,
+		})
+	}
+	return json.NewEncoder(w).Encode(map[string]any{"traceEvents": events, "displayTimeUnit": "ms"})
+}
+
+// countwriter counts the bytes written to w
+type countwriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countwriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
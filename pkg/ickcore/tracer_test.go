@@ -0,0 +1,103 @@
+package ickcore
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTracer(t *testing.T) {
+	ResetRegistry()
+	AddRegistryEntry("ick-tstsniph8", &sniph8{})
+
+	tracer := new(RenderTracer)
+	root := ToHTML(`<div><ick-tstsniph8 label="one" href="/1"/><ick-tstsniph8 label="two" href="/2"/></div><ick-unknown/>`)
+	root.RMeta().Trace = tracer
+	failing := new(snipfailing)
+	failing.RMeta().Trace = tracer
+
+	out := new(bytes.Buffer)
+	err := RenderChild(out, nil, root, failing)
+	require.Error(t, err)
+
+	roots := tracer.Roots()
+	require.Len(t, roots, 2)
+	assert.Equal(t, "*ickcore.HTMLString", roots[0].Type)
+	assert.Equal(t, "htmlstring1", roots[0].VirtualId)
+	assert.Equal(t, len(`<div><a name="sniph8" href="/1">one</a><a name="sniph8" href="/2">two</a></div><!--ick-unknown: unregistered ick-tagname-->`), roots[0].Bytes)
+	assert.Equal(t, "broken", roots[1].Err)
+	assert.Equal(t, len("partial"), roots[1].Bytes)
+
+	require.Len(t, roots[0].Children, 2)
+	child := roots[0].Children[0]
+	assert.Equal(t, "ick-tstsniph8", child.IckTagName)
+	assert.Equal(t, "htmlstring1.sniph80", child.VirtualId)
+	assert.Equal(t, 1, child.Deep)
+	assert.Equal(t, len(`<a name="sniph8" href="/1">one</a>`), child.Bytes)
+	require.Len(t, child.Children, 1)
+	assert.Equal(t, 3, child.Children[0].Bytes)
+	assert.GreaterOrEqual(t, roots[0].Duration, child.Duration)
+
+	assert.Len(t, tracer.Spans(), 6)
+	assert.Len(t, tracer.Slowest(3), 3)
+	slowest := tracer.Slowest(0)
+	for i := 1; i < len(slowest); i++ {
+		assert.GreaterOrEqual(t, slowest[i-1].Self(), slowest[i].Self())
+	}
+
+	// tree
+	tree := new(bytes.Buffer)
+	require.NoError(t, tracer.WriteTree(tree))
+	lines := strings.Split(strings.TrimSpace(tree.String()), "\n")
+	require.Len(t, lines, 6)
+	assert.True(t, strings.HasPrefix(lines[1], "  htmlstring1.sniph80 *ickcore.sniph8 <ick-tstsniph8> "))
+	assert.True(t, strings.HasPrefix(lines[2], "    htmlstring1.sniph80.htmlstring0 *ickcore.HTMLString "))
+	assert.Contains(t, lines[5], "error: broken")
+
+	// json
+	js := new(bytes.Buffer)
+	require.NoError(t, tracer.WriteJSON(js))
+	var spans []map[string]any
+	require.NoError(t, json.Unmarshal(js.Bytes(), &spans))
+	require.Len(t, spans, 2)
+	assert.Equal(t, "htmlstring1", spans[0]["vid"])
+	assert.Len(t, spans[0]["children"], 2)
+
+	// chrome trace
+	js.Reset()
+	require.NoError(t, tracer.WriteChromeTrace(js))
+	var chrome struct {
+		TraceEvents []map[string]any `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(js.Bytes(), &chrome))
+	require.Len(t, chrome.TraceEvents, 6)
+	assert.Equal(t, "X", chrome.TraceEvents[0]["ph"])
+	assert.Equal(t, 0.0, chrome.TraceEvents[0]["ts"])
+	assert.Equal(t, "ick-tstsniph8", chrome.TraceEvents[1]["name"])
+
+	// roots are copies
+	roots[0].Children = nil
+	assert.Len(t, tracer.Roots()[0].Children, 2)
+
+	tracer.Reset()
+	assert.Empty(t, tracer.Roots())
+
+	// limited number of roots
+	tracer.MaxRoots = 2
+	for i := 0; i < 3; i++ {
+		err = RenderChild(out, nil, root)
+		require.NoError(t, err)
+	}
+	assert.Len(t, tracer.Roots(), 2)
+
+	// nil tracer
+	var nilt *RenderTracer
+	span, w := nilt.Begin("", root, out)
+	assert.Nil(t, span)
+	assert.Equal(t, out, w)
+	assert.Empty(t, nilt.Spans())
+}
@@ -0,0 +1,40 @@
+package ickcore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The Unfold functions convert an attribute's value like the default mapping of the ick-tag attributes does.
+// They're used by the code generated with `icecake gen`.
+
+// UnfoldBool returns false if value is "false" or "0", case insensitive, otherwise true, even if value is empty.
+func UnfoldBool(value string) bool {
+	s := strings.ToLower(strings.Trim(value, " "))
+	return s != "false" && s != "0"
+}
+
+// UnfoldInt parses value to an integer of bits size, 0 for int.
+func UnfoldInt(value string, bits int) (int64, error) {
+	return strconv.ParseInt(strings.Trim(value, " "), 10, bits)
+}
+
+// UnfoldUint parses value to an unsigned integer of bits size, 0 for uint.
+func UnfoldUint(value string, bits int) (uint64, error) {
+	return strconv.ParseUint(strings.Trim(value, " "), 10, bits)
+}
+
+// UnfoldFloat parses value to a float of bits size.
+func UnfoldFloat(value string, bits int) (float64, error) {
+	return strconv.ParseFloat(strings.Trim(value, " "), bits)
+}
+
+// UnfoldJSON unmarshals value into v, a pointer to a value of type typ.
+func UnfoldJSON(value string, v any, typ string) error {
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return fmt.Errorf("%s: json value expected: %w", typ, err)
+	}
+	return nil
+}
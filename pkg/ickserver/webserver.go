@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
 )
 
 type WebServer struct {
@@ -27,8 +28,16 @@ type WebServer struct {
 
 	WebRouter *mux.Router
 	ApiRouter *mux.Router
+
+	// Tracer records the renderings made by the server, if HTTP_TRACE is true. Only the last traceMaxRoots renderings are kept.
+	// Bind it to the composers rendered by the api handlers with their RMeta().Trace field.
+	// The slowest components are served by /api/trace and printed out when the server shuts down.
+	Tracer *ickcore.RenderTracer
 }
 
+// traceMaxRoots is the number of renderings kept by the Tracer of the server
+const traceMaxRoots int = 1000
+
 func MakeWebserver() WebServer {
 	ws := new(WebServer)
 
@@ -63,12 +72,19 @@ func MakeWebserver() WebServer {
 		ws.http_logger = true
 	}
 
+	if strings.ToLower(strings.Trim(os.Getenv("HTTP_TRACE"), " ")) == "true" {
+		ws.Tracer = &ickcore.RenderTracer{MaxRoots: traceMaxRoots}
+	}
+
 	// configure the server, with or without trailing slash is the same route
 	ws.WebRouter = mux.NewRouter().StrictSlash(true)
 
 	// configure the /api subrouter
 	ws.ApiRouter = ws.WebRouter.PathPrefix("/api").Subrouter()
 	ws.ApiRouter.HandleFunc("/health", GetHealthHandle())
+	if ws.Tracer != nil {
+		ws.ApiRouter.HandleFunc("/trace", GetTraceHandle(ws.Tracer))
+	}
 
 	return *ws
 }
@@ -137,6 +153,11 @@ func (ws WebServer) Run() {
 	srv.Shutdown(ctx)
 
 	fmt.Println("SPA web Server is down")
+
+	if ws.Tracer != nil {
+		fmt.Println("slowest components:")
+		ws.Tracer.WriteSlowest(os.Stdout, 10)
+	}
 }
 
 // GetHealthHandle responds to a GET Health api request
@@ -147,3 +168,29 @@ func GetHealthHandle() func(http.ResponseWriter, *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"health": "live", "counter": strconv.Itoa(counter)})
 	}
 }
+
+// GetTraceHandle responds to a GET trace api request with the renderings recorded by tracer.
+// The format query parameter selects the output: "json", "chrome" for the Chrome trace event format, "tree",
+// or the slowest components by default. The n query parameter is the number of slowest components, 10 by default.
+func GetTraceHandle(tracer *ickcore.RenderTracer) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("format") {
+		case "json":
+			w.Header().Set("content-type", "application/json")
+			tracer.WriteJSON(w)
+		case "chrome":
+			w.Header().Set("content-type", "application/json")
+			tracer.WriteChromeTrace(w)
+		case "tree":
+			w.Header().Set("content-type", "text/plain; charset=utf-8")
+			tracer.WriteTree(w)
+		default:
+			n, err := strconv.Atoi(r.URL.Query().Get("n"))
+			if err != nil || n <= 0 {
+				n = 10
+			}
+			w.Header().Set("content-type", "text/plain; charset=utf-8")
+			tracer.WriteSlowest(w, n)
+		}
+	}
+}
@@ -0,0 +1,544 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BLOCK_TYPE is the type of a block of the document
+type BLOCK_TYPE int
+
+const (
+	BLOCK_PARAGRAPH BLOCK_TYPE = iota
+	BLOCK_HEADING
+	BLOCK_THEMATICBREAK
+	BLOCK_CODE
+	BLOCK_HTML
+	BLOCK_BLOCKQUOTE
+	BLOCK_LIST
+	BLOCK_LISTITEM
+	BLOCK_TABLE
+)
+
+// ALIGN is the alignment of a table column
+type ALIGN int
+
+const (
+	ALIGN_NONE ALIGN = iota
+	ALIGN_LEFT
+	ALIGN_CENTER
+	ALIGN_RIGHT
+)
+
+// block is a block of the document, with its raw inline content or its children.
+type block struct {
+	typ      BLOCK_TYPE
+	text     string   // raw inline content of a paragraph or a heading, content of a code or an html block
+	level    int      // level of a heading
+	info     string   // info string of a fenced code block
+	ordered  bool     // ordered list
+	start    int      // start number of an ordered list
+	tight    bool     // tight list, paragraphs of its items are rendered without <p>
+	aligns   []ALIGN  // alignment of the table columns
+	header   []string // raw inline content of the table header cells
+	rows     [][]string
+	children []*block
+}
+
+// parser parses the blocks of a document and collects its link reference definitions
+type parser struct {
+	refs map[string]linkref
+}
+
+type linkref struct {
+	dest  string
+	title string
+}
+
+var (
+	reATXHeading     = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	reThematicBreak  = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	reSetextHeading  = regexp.MustCompile(`^(?:=+|-+)[ \t]*$`)
+	reFence          = regexp.MustCompile("^(`{3,}|~{3,})[ \t]*(.*)$")
+	reBulletItem     = regexp.MustCompile(`^([-+*])(?:[ \t]|$)`)
+	reOrderedItem    = regexp.MustCompile(`^([0-9]{1,9})([.)])(?:[ \t]|$)`)
+	reTableDelimiter = regexp.MustCompile(`^\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	reHTMLRaw        = regexp.MustCompile(`(?i)^<(?:script|pre|style|textarea)(?:[ \t>]|$)`)
+	reHTMLBlock      = regexp.MustCompile(`(?i)^</?(?:address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[1-6]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(?:[ \t>]|/>|$)`)
+	reHTMLTag        = regexp.MustCompile(`^(?:` + openTag + `|` + closingTag + `)[ \t]*$`)
+	reLinkRefDef     = regexp.MustCompile(`^\[((?:[^\\\[\]]|\\.){1,999})\]:[ \t]*(?:<([^<>\n]*)>|(\S+))(?:[ \t]+(?:"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)'|\(((?:[^()\\]|\\.)*)\)))?[ \t]*$`)
+)
+
+// parseblocks parses the lines of a document, or of the content of a container block.
+func (p *parser) parseblocks(lines []string) []*block {
+	var blocks []*block
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isblank(line) {
+			i++
+			continue
+		}
+
+		indent, rest := indentation(line)
+		if indent >= 4 {
+			b, next := parseindentedcode(lines, i)
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		if b, next := p.parsestart(lines, i, indent, rest); b != nil {
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		if b, next := parsetable(lines, i); b != nil {
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		// paragraph, a setext heading or link reference definitions
+		b, next := p.parseparagraph(lines, i)
+		if b != nil {
+			blocks = append(blocks, b)
+		}
+		i = next
+	}
+	return blocks
+}
+
+// parsestart parses the block starting at lines[i] if it's not a paragraph, an indented code block or a table.
+// Returns a nil block otherwise.
+func (p *parser) parsestart(lines []string, i int, indent int, rest string) (*block, int) {
+	switch {
+	case reThematicBreak.MatchString(rest):
+		return &block{typ: BLOCK_THEMATICBREAK}, i + 1
+
+	case reATXHeading.MatchString(rest):
+		m := reATXHeading.FindStringSubmatch(rest)
+		return &block{typ: BLOCK_HEADING, level: len(m[1]), text: m[2]}, i + 1
+
+	case reFence.MatchString(rest):
+		if b, next := parsefencedcode(lines, i, indent, rest); b != nil {
+			return b, next
+		}
+
+	case strings.HasPrefix(rest, ">"):
+		return p.parseblockquote(lines, i)
+
+	case reBulletItem.MatchString(rest) || reOrderedItem.MatchString(rest):
+		return p.parselist(lines, i)
+
+	case strings.HasPrefix(rest, "<"):
+		if htmlblockstart(rest, true) {
+			return parsehtml(lines, i, rest), htmlblockend(lines, i, rest)
+		}
+	}
+	return nil, i
+}
+
+// interrupts returns true if the line starts a block able to interrupt a paragraph.
+func interrupts(line string) bool {
+	indent, rest := indentation(line)
+	if indent >= 4 {
+		return false
+	}
+	switch {
+	case reThematicBreak.MatchString(rest), reATXHeading.MatchString(rest), strings.HasPrefix(rest, ">"):
+		return true
+	case reFence.MatchString(rest):
+		m := reFence.FindStringSubmatch(rest)
+		return m[1][0] != '`' || !strings.Contains(m[2], "`")
+	case reBulletItem.MatchString(rest):
+		return !isblank(rest[1:])
+	case reOrderedItem.MatchString(rest):
+		m := reOrderedItem.FindStringSubmatch(rest)
+		return m[1] == "1" && !isblank(rest[len(m[0]):])
+	case strings.HasPrefix(rest, "<"):
+		return htmlblockstart(rest, false)
+	}
+	return false
+}
+
+// parseparagraph parses a paragraph starting at lines[i], removing link reference definitions at its beginning.
+// The paragraph becomes a setext heading if it's followed by an underline.
+func (p *parser) parseparagraph(lines []string, i int) (*block, int) {
+	var text []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isblank(line) {
+			break
+		}
+		if len(text) > 0 {
+			indent, rest := indentation(line)
+			if indent < 4 && reSetextHeading.MatchString(rest) {
+				level := 2
+				if rest[0] == '=' {
+					level = 1
+				}
+				text = p.linkrefdefs(text)
+				if len(text) == 0 {
+					// not a heading without content
+					return &block{typ: BLOCK_PARAGRAPH, text: strings.TrimSpace(line)}, i + 1
+				}
+				return &block{typ: BLOCK_HEADING, level: level, text: strings.TrimSpace(strings.Join(text, "\n"))}, i + 1
+			}
+			if interrupts(line) {
+				break
+			}
+		}
+		text = append(text, strings.TrimLeft(line, " \t"))
+	}
+	text = p.linkrefdefs(text)
+	if len(text) == 0 {
+		return nil, i
+	}
+	return &block{typ: BLOCK_PARAGRAPH, text: strings.TrimRight(strings.Join(text, "\n"), " \t")}, i
+}
+
+// linkrefdefs records the link reference definitions at the beginning of the lines of a paragraph and returns the remaining lines.
+func (p *parser) linkrefdefs(lines []string) []string {
+	for len(lines) > 0 {
+		m := reLinkRefDef.FindStringSubmatch(lines[0])
+		if m == nil {
+			break
+		}
+		label := normalizelabel(m[1])
+		if _, found := p.refs[label]; !found && label != "" {
+			dest := m[2] + m[3]
+			title := m[4] + m[5] + m[6]
+			p.refs[label] = linkref{dest: unescape(dest), title: unescape(title)}
+		}
+		lines = lines[1:]
+	}
+	return lines
+}
+
+// parseindentedcode parses an indented code block starting at lines[i]
+func parseindentedcode(lines []string, i int) (*block, int) {
+	var code []string
+	for ; i < len(lines); i++ {
+		indent, _ := indentation(lines[i])
+		if indent < 4 && !isblank(lines[i]) {
+			break
+		}
+		code = append(code, removeindent(lines[i], 4))
+	}
+	// trailing blank lines are not part of the code
+	n := len(code)
+	for n > 0 && isblank(code[n-1]) {
+		n--
+	}
+	return &block{typ: BLOCK_CODE, text: strings.Join(code[:n], "\n") + "\n"}, i - (len(code) - n)
+}
+
+// parsefencedcode parses a fenced code block starting at lines[i]. The block ends at the closing fence or at the end of the lines.
+func parsefencedcode(lines []string, i int, indent int, rest string) (*block, int) {
+	m := reFence.FindStringSubmatch(rest)
+	fence := m[1]
+	info := strings.TrimSpace(m[2])
+	if fence[0] == '`' && strings.Contains(info, "`") {
+		return nil, i
+	}
+	b := &block{typ: BLOCK_CODE, info: unescape(info)}
+
+	var code []string
+	i++
+	for ; i < len(lines); i++ {
+		cindent, crest := indentation(lines[i])
+		if cindent < 4 && strings.HasPrefix(crest, fence) && strings.Trim(crest, string(fence[0])+" \t") == "" {
+			i++
+			break
+		}
+		code = append(code, removeindent(lines[i], indent))
+	}
+	if len(code) > 0 {
+		b.text = strings.Join(code, "\n") + "\n"
+	}
+	return b, i
+}
+
+// parseblockquote parses a block quote starting at lines[i]. Paragraph continuation lines without '>' are part of the quote.
+func (p *parser) parseblockquote(lines []string, i int) (*block, int) {
+	var content []string
+	paragraph := false
+	for ; i < len(lines); i++ {
+		indent, rest := indentation(lines[i])
+		if indent < 4 && strings.HasPrefix(rest, ">") {
+			rest = rest[1:]
+			if strings.HasPrefix(rest, " ") || strings.HasPrefix(rest, "\t") {
+				rest = rest[1:]
+			}
+			content = append(content, rest)
+			ri, rr := indentation(rest)
+			paragraph = !isblank(rest) && (ri >= 4 || !interrupts(rest)) && !reFence.MatchString(rr)
+			continue
+		}
+		if !paragraph || isblank(lines[i]) || interrupts(lines[i]) {
+			break
+		}
+		// lazy continuation line
+		content = append(content, lines[i])
+	}
+	return &block{typ: BLOCK_BLOCKQUOTE, children: p.parseblocks(content)}, i
+}
+
+// listmarker returns the marker of a list item starting the line, the width of the marker and the indentation of the item content.
+func listmarker(line string) (marker string, ordered bool, start int, contentindent int, ok bool) {
+	indent, rest := indentation(line)
+	if indent >= 4 {
+		return
+	}
+	var width int
+	if m := reBulletItem.FindStringSubmatch(rest); m != nil {
+		marker, width = m[1], 1
+	} else if m := reOrderedItem.FindStringSubmatch(rest); m != nil {
+		marker, width, ordered = m[2], len(m[1])+1, true
+		start, _ = strconv.Atoi(m[1])
+	} else {
+		return
+	}
+	after := expandtabs(rest[width:], indent+width)
+	spaces := len(after) - len(strings.TrimLeft(after, " "))
+	if spaces == 0 || spaces > 4 || isblank(after) {
+		spaces = 1
+	}
+	return marker, ordered, start, indent + width + spaces, true
+}
+
+// parselist parses a list starting at lines[i], made of consecutive items with the same marker.
+func (p *parser) parselist(lines []string, i int) (*block, int) {
+	marker, ordered, start, _, _ := listmarker(lines[i])
+	list := &block{typ: BLOCK_LIST, ordered: ordered, start: start, tight: true}
+
+	for i < len(lines) {
+		m, _, _, contentindent, ok := listmarker(lines[i])
+		if !ok || m != marker {
+			break
+		}
+
+		// first line of the item
+		first := expandtabs(lines[i], 0)
+		if contentindent > len(first) {
+			first = ""
+		} else {
+			first = first[contentindent:]
+		}
+		content := []string{first}
+		paragraph := !isblank(first)
+		i++
+
+		// following lines of the item
+		for ; i < len(lines); i++ {
+			line := lines[i]
+			if isblank(line) {
+				// an item can begin with at most one blank line
+				if len(content) == 1 && isblank(content[0]) {
+					break
+				}
+				content = append(content, "")
+				paragraph = false
+				continue
+			}
+			indent, _ := indentation(line)
+			if indent >= contentindent {
+				content = append(content, removeindent(line, contentindent))
+				paragraph = !interrupts(content[len(content)-1])
+				continue
+			}
+			if _, _, _, _, item := listmarker(line); item {
+				break
+			}
+			if paragraph && !interrupts(line) {
+				// lazy continuation line
+				content = append(content, line)
+				continue
+			}
+			break
+		}
+
+		// trailing blank lines belong to the list, not to the item
+		n := len(content)
+		for n > 0 && isblank(content[n-1]) {
+			n--
+		}
+		if n < len(content) && i < len(lines) {
+			if m, _, _, _, next := listmarker(lines[i]); next && m == marker {
+				list.tight = false
+			}
+		}
+		for j := 0; j < n; j++ {
+			if isblank(content[j]) && j > 0 {
+				list.tight = false
+			}
+		}
+		item := &block{typ: BLOCK_LISTITEM, children: p.parseblocks(content[:n])}
+		list.children = append(list.children, item)
+	}
+	return list, i
+}
+
+// htmlblockstart returns true if line starts an html block. Only the blocks with well known tag names can interrupt a paragraph.
+func htmlblockstart(line string, canstart7 bool) bool {
+	switch {
+	case reHTMLRaw.MatchString(line), strings.HasPrefix(line, "<!--"), strings.HasPrefix(line, "<?"), strings.HasPrefix(line, "<![CDATA["):
+		return true
+	case len(line) > 2 && line[1] == '!' && isletter(line[2]):
+		return true
+	case reHTMLBlock.MatchString(line):
+		return true
+	case canstart7:
+		return reHTMLTag.MatchString(line)
+	}
+	return false
+}
+
+// htmlblockend returns the index following the last line of the html block starting at lines[i].
+func htmlblockend(lines []string, i int, rest string) int {
+	var end string
+	switch {
+	case reHTMLRaw.MatchString(rest):
+		tag := strings.ToLower(strings.TrimLeft(strings.Fields(strings.TrimRight(rest[1:], ">"))[0], "<"))
+		tag = strings.TrimRight(tag, ">")
+		end = "</" + tag + ">"
+	case strings.HasPrefix(rest, "<!--"):
+		end = "-->"
+	case strings.HasPrefix(rest, "<?"):
+		end = "?>"
+	case strings.HasPrefix(rest, "<![CDATA["):
+		end = "]]>"
+	case rest[1] == '!':
+		end = ">"
+	}
+	for j := i; j < len(lines); j++ {
+		if end == "" {
+			if isblank(lines[j]) {
+				return j
+			}
+		} else if strings.Contains(strings.ToLower(lines[j]), end) {
+			return j + 1
+		}
+	}
+	return len(lines)
+}
+
+// parsehtml returns the html block starting at lines[i]
+func parsehtml(lines []string, i int, rest string) *block {
+	end := htmlblockend(lines, i, rest)
+	return &block{typ: BLOCK_HTML, text: strings.Join(lines[i:end], "\n") + "\n"}
+}
+
+// parsetable parses a table starting at lines[i]: a header row, a delimiter row, then rows until a blank line or another block.
+// Returns a nil block if there's no table.
+func parsetable(lines []string, i int) (*block, int) {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") || !reTableDelimiter.MatchString(strings.TrimSpace(lines[i+1])) {
+		return nil, i
+	}
+	header := splitrow(lines[i])
+	delims := splitrow(lines[i+1])
+	if len(header) != len(delims) {
+		return nil, i
+	}
+	b := &block{typ: BLOCK_TABLE, header: header}
+	for _, d := range delims {
+		d = strings.TrimSpace(d)
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			b.aligns = append(b.aligns, ALIGN_CENTER)
+		case left:
+			b.aligns = append(b.aligns, ALIGN_LEFT)
+		case right:
+			b.aligns = append(b.aligns, ALIGN_RIGHT)
+		default:
+			b.aligns = append(b.aligns, ALIGN_NONE)
+		}
+	}
+	for i += 2; i < len(lines) && !isblank(lines[i]) && !interrupts(lines[i]); i++ {
+		row := splitrow(lines[i])
+		// rows have the same number of cells than the header
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		b.rows = append(b.rows, row[:len(header)])
+	}
+	return b, i
+}
+
+// splitrow splits a table row into its raw cells. Escaped pipes are kept within the cells.
+func splitrow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, strings.TrimSpace(line[start:i]))
+			start = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(line[start:]))
+}
+
+/******************************************************************************/
+
+func isblank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// indentation returns the number of columns of the indentation of the line, tabs expanded, and the line without its indentation.
+func indentation(line string) (int, string) {
+	col := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			col++
+		case '\t':
+			col += 4 - col%4
+		default:
+			return col, line[i:]
+		}
+	}
+	return col, ""
+}
+
+// removeindent removes up to n columns of indentation from the line, tabs expanded.
+func removeindent(line string, n int) string {
+	line = expandtabs(line, 0)
+	i := 0
+	for i < len(line) && i < n && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
+
+// expandtabs expands the tabs of the leading blanks of s, starting at column col.
+func expandtabs(s string, col int) string {
+	var sb strings.Builder
+	i := 0
+	for ; i < len(s) && (s[i] == ' ' || s[i] == '\t'); i++ {
+		if s[i] == '\t' {
+			n := 4 - col%4
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+		} else {
+			sb.WriteByte(' ')
+			col++
+		}
+	}
+	return sb.String() + s[i:]
+}
+
+// normalizelabel returns the label of a link reference, case insensitive with collapsed blanks.
+func normalizelabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
@@ -0,0 +1,484 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+const (
+	tagName      = `[A-Za-z][A-Za-z0-9-]*`
+	attribute    = `(?:[ \t\n]+[A-Za-z_:][A-Za-z0-9_.:-]*(?:[ \t\n]*=[ \t\n]*(?:[^ \t\n"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)`
+	openTag      = `<` + tagName + attribute + `*[ \t\n]*/?>`
+	closingTag   = `</` + tagName + `[ \t\n]*>`
+	htmlComment  = `<!--(?:-?[^>-])(?:-?[^-])*-->|<!---?>`
+	instruction  = `<\?(?s:.*?)\?>`
+	declaration  = `<![A-Za-z]+[^>]*>`
+	cdata        = `<!\[CDATA\[(?s:.*?)\]\]>`
+	autolinkURI  = `<[A-Za-z][A-Za-z0-9.+-]{1,31}:[^<>\x00-\x20]*>`
+	autolinkMail = `<[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*>`
+)
+
+var (
+	reInlineHTML   = regexp.MustCompile(`^(?:` + openTag + `|` + closingTag + `|` + htmlComment + `|` + instruction + `|` + declaration + `|` + cdata + `)`)
+	reAutolinkURI  = regexp.MustCompile(`^` + autolinkURI)
+	reAutolinkMail = regexp.MustCompile(`^` + autolinkMail)
+	reEntity       = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+)
+
+// token is a piece of inline content: raw html or a run of emphasis delimiters.
+type token struct {
+	html   string // html of the token, rendered as is
+	delim  byte   // '*' or '_' for a run of delimiters
+	n      int    // remaining delimiters in the run
+	open   bool   // the run can open emphasis
+	close  bool   // the run can close emphasis
+	orig   int    // original length of the run
+	before string // closing tags rendered before the remaining delimiters
+	after  string // opening tags rendered after the remaining delimiters
+}
+
+// inline renders the raw inline content s to html.
+func (p *parser) inline(s string) string {
+	var tokens []*token
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			tokens = append(tokens, &token{html: text.String()})
+			text.Reset()
+		}
+	}
+	raw := func(h string) {
+		flush()
+		tokens = append(tokens, &token{html: h})
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			raw("<br />\n")
+			i += 2
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+
+		case c == '\\' && i+1 < len(s) && ispunct(s[i+1]):
+			text.WriteString(escapetext(s[i+1 : i+2]))
+			i += 2
+
+		case c == '\n':
+			// hard line break if preceded by at least two spaces
+			t := text.String()
+			trimmed := strings.TrimRight(t, " ")
+			text.Reset()
+			text.WriteString(trimmed)
+			if len(t)-len(trimmed) >= 2 {
+				raw("<br />\n")
+			} else {
+				text.WriteString("\n")
+			}
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+
+		case c == '`':
+			n := runlength(s, i, '`')
+			if end := closingbackticks(s, i+n, n); end >= 0 {
+				raw("<code>" + ickcore.EscapeString(ickcore.ESCCTX_CODE, codespan(s[i+n:end])) + "</code>")
+				i = end + n
+			} else {
+				text.WriteString(s[i : i+n])
+				i += n
+			}
+
+		case c == '*' || c == '_':
+			n := runlength(s, i, c)
+			flush()
+			open, close := flanking(s, i, n)
+			if c == '_' {
+				open, close = open && (!close || ispunctrune(before(s, i))), close && (!open || ispunctrune(after(s, i+n)))
+			}
+			tokens = append(tokens, &token{delim: c, n: n, orig: n, open: open, close: close})
+			i += n
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if h, next, ok := p.link(s, i+1, true); ok {
+				raw(h)
+				i = next
+			} else {
+				text.WriteString("!")
+				i++
+			}
+
+		case c == '[':
+			if h, next, ok := p.link(s, i, false); ok {
+				raw(h)
+				i = next
+			} else {
+				text.WriteString("[")
+				i++
+			}
+
+		case c == '<':
+			if m := reAutolinkURI.FindString(s[i:]); m != "" {
+				u := m[1 : len(m)-1]
+				raw(`<a href="` + escapeurl(u) + `">` + escapetext(u) + `</a>`)
+				i += len(m)
+			} else if m := reAutolinkMail.FindString(s[i:]); m != "" {
+				u := m[1 : len(m)-1]
+				raw(`<a href="` + escapeurl("mailto:"+u) + `">` + escapetext(u) + `</a>`)
+				i += len(m)
+			} else if m := reInlineHTML.FindString(s[i:]); m != "" {
+				raw(m)
+				i += len(m)
+			} else {
+				text.WriteString("&lt;")
+				i++
+			}
+
+		case c == '&':
+			if m := reEntity.FindString(s[i:]); m != "" && (m[1] == '#' || html.UnescapeString(m) != m) {
+				text.WriteString(m)
+				i += len(m)
+			} else {
+				text.WriteString("&amp;")
+				i++
+			}
+
+		default:
+			// copy the run of ordinary chars
+			j := i + 1
+			for j < len(s) && !strings.ContainsRune("\\\n`*_![<&", rune(s[j])) {
+				j++
+			}
+			text.WriteString(escapetext(s[i:j]))
+			i = j
+		}
+	}
+	flush()
+
+	emphasis(tokens)
+
+	var sb strings.Builder
+	for _, t := range tokens {
+		if t.delim != 0 {
+			sb.WriteString(t.before)
+			sb.WriteString(strings.Repeat(string(t.delim), t.n))
+			sb.WriteString(t.after)
+		} else {
+			sb.WriteString(t.html)
+		}
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// emphasis matches the runs of delimiters and sets up the emphasis tags, following the CommonMark rules.
+func emphasis(tokens []*token) {
+	for ci, closer := range tokens {
+		for closer.delim != 0 && closer.close && closer.n > 0 {
+			// look back for the nearest matching opener
+			oi := -1
+			for j := ci - 1; j >= 0; j-- {
+				o := tokens[j]
+				if o.delim != closer.delim || !o.open || o.n == 0 {
+					continue
+				}
+				// rule of 3
+				if (o.close || closer.open) && (o.orig+closer.orig)%3 == 0 && !(o.orig%3 == 0 && closer.orig%3 == 0) {
+					continue
+				}
+				oi = j
+				break
+			}
+			if oi < 0 {
+				break
+			}
+			opener := tokens[oi]
+			use, tag := 1, "em"
+			if opener.n >= 2 && closer.n >= 2 {
+				use, tag = 2, "strong"
+			}
+			opener.n -= use
+			closer.n -= use
+			opener.after = "<" + tag + ">" + opener.after
+			closer.before = closer.before + "</" + tag + ">"
+
+			// delimiters between the opener and the closer can't match anymore
+			for j := oi + 1; j < ci; j++ {
+				if tokens[j].delim != 0 {
+					tokens[j].open, tokens[j].close = false, false
+				}
+			}
+		}
+	}
+}
+
+// link parses a link, or an image, whose text starts at s[at] with '['.
+// Returns the html of the link and the index following it.
+func (p *parser) link(s string, at int, image bool) (h string, next int, ok bool) {
+	end := closingbracket(s, at)
+	if end < 0 {
+		return "", 0, false
+	}
+	content := s[at+1 : end]
+	next = end + 1
+
+	var dest, title string
+	found := false
+	if next < len(s) && s[next] == '(' {
+		dest, title, next, found = inlinedest(s, next)
+	}
+	if !found {
+		label := content
+		next = end + 1
+		if next < len(s) && s[next] == '[' {
+			if lend := closingbracket(s, next); lend >= 0 {
+				if l := s[next+1 : lend]; l != "" {
+					label = l
+				}
+				next = lend + 1
+			}
+		}
+		ref, exists := p.refs[normalizelabel(label)]
+		if !exists {
+			return "", 0, false
+		}
+		dest, title = ref.dest, ref.title
+	}
+
+	if image {
+		h = `<img src="` + escapeurl(dest) + `" alt="` + escapeattr(plaintext(p.inline(content))) + `"`
+		if title != "" {
+			h += ` title="` + escapeattr(title) + `"`
+		}
+		return h + ` />`, next, true
+	}
+	h = `<a href="` + escapeurl(dest) + `"`
+	if title != "" {
+		h += ` title="` + escapeattr(title) + `"`
+	}
+	return h + ">" + p.inline(content) + "</a>", next, true
+}
+
+// closingbracket returns the index of the ']' closing the '[' at s[at], skipping escaped chars, code spans and nested brackets.
+// Returns -1 if not found.
+func closingbracket(s string, at int) int {
+	depth := 0
+	for i := at; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			n := runlength(s, i, '`')
+			if end := closingbackticks(s, i+n, n); end >= 0 {
+				i = end + n - 1
+			} else {
+				i += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// inlinedest parses the destination and the optional title of an inline link starting at s[at] with '('.
+func inlinedest(s string, at int) (dest string, title string, next int, ok bool) {
+	i := skipblanks(s, at+1)
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i+1:], "<>\n")
+		if end < 0 || s[i+1+end] != '>' {
+			return
+		}
+		dest = s[i+1 : i+1+end]
+		i += end + 2
+	} else {
+		start, depth := i, 0
+	loop:
+		for ; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s) && ispunct(s[i+1]):
+				i++
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case c <= ' ':
+				break loop
+			}
+		}
+		dest = s[start:i]
+	}
+
+	j := skipblanks(s, i)
+	if j < len(s) && j > i && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		q := s[j]
+		if q == '(' {
+			q = ')'
+		}
+		k := j + 1
+		for ; k < len(s) && s[k] != q; k++ {
+			if s[k] == '\\' {
+				k++
+			}
+		}
+		if k >= len(s) {
+			return
+		}
+		title = s[j+1 : k]
+		j = skipblanks(s, k+1)
+	}
+	if j >= len(s) || s[j] != ')' {
+		return
+	}
+	return unescape(dest), unescape(title), j + 1, true
+}
+
+/******************************************************************************/
+
+// runlength returns the length of the run of c starting at s[at]
+func runlength(s string, at int, c byte) int {
+	n := 0
+	for at+n < len(s) && s[at+n] == c {
+		n++
+	}
+	return n
+}
+
+// closingbackticks returns the index of the run of exactly n backticks closing a code span, from s[from].
+// Returns -1 if not found.
+func closingbackticks(s string, from int, n int) int {
+	for i := from; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		m := runlength(s, i, '`')
+		if m == n {
+			return i
+		}
+		i += m
+	}
+	return -1
+}
+
+// codespan returns the content of a code span: line endings are converted to spaces,
+// and one space is stripped from both sides if the content is surrounded by spaces.
+func codespan(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if len(s) >= 2 && s[0] == ' ' && s[len(s)-1] == ' ' && strings.Trim(s, " ") != "" {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// flanking returns if the run of n delimiters at s[at] is left-flanking and right-flanking.
+func flanking(s string, at int, n int) (left bool, right bool) {
+	b, a := before(s, at), after(s, at+n)
+	left = !unicode.IsSpace(a) && (!ispunctrune(a) || unicode.IsSpace(b) || ispunctrune(b))
+	right = !unicode.IsSpace(b) && (!ispunctrune(b) || unicode.IsSpace(a) || ispunctrune(a))
+	return left, right
+}
+
+// before returns the rune before s[at], a space at the beginning of s.
+func before(s string, at int) rune {
+	if at <= 0 {
+		return ' '
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:at])
+	return r
+}
+
+// after returns the rune at s[at], a space at the end of s.
+func after(s string, at int) rune {
+	if at >= len(s) {
+		return ' '
+	}
+	r, _ := utf8.DecodeRuneInString(s[at:])
+	return r
+}
+
+func ispunct(c byte) bool {
+	return c < utf8.RuneSelf && ispunctrune(rune(c))
+}
+
+func ispunctrune(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func isletter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func skipblanks(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+var reEscaped = regexp.MustCompile(`\\[!-/:-@\[-` + "`" + `{-~]`)
+
+// unescape returns s with its backslash escapes and its entities replaced.
+func unescape(s string) string {
+	s = reEscaped.ReplaceAllStringFunc(s, func(m string) string { return m[1:] })
+	return html.UnescapeString(s)
+}
+
+var textReplacer = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
+
+// escapetext escapes a text for an element body
+func escapetext(s string) string {
+	return textReplacer.Replace(s)
+}
+
+// escapeattr escapes a double quoted attribute value
+func escapeattr(s string) string {
+	return textReplacer.Replace(s)
+}
+
+// escapeurl percent-encodes the url and escapes it for a double quoted attribute.
+// URLs with a scheme able to run code are replaced if ickcore.AutoEscape is on.
+func escapeurl(u string) string {
+	var sb strings.Builder
+	for i := 0; i < len(u); i++ {
+		c := u[i]
+		switch {
+		case c == '%' && i+2 < len(u) && ishex(u[i+1]) && ishex(u[i+2]):
+			sb.WriteByte(c)
+		case c > ' ' && c < 0x7f && !strings.ContainsRune(`"<>\^`+"`{|}", rune(c)):
+			sb.WriteByte(c)
+		default:
+			sb.WriteString("%" + strings.ToUpper(string("0123456789abcdef"[c>>4])+string("0123456789abcdef"[c&15])))
+		}
+	}
+	if ickcore.AutoEscape {
+		return strings.ReplaceAll(ickcore.EscapeString(ickcore.ESCCTX_URL, sb.String()), `"`, "&quot;")
+	}
+	return strings.ReplaceAll(sb.String(), "&", "&amp;")
+}
+
+func ishex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+var reTag = regexp.MustCompile(`<[^>]*>`)
+
+// plaintext returns the text of an html fragment, without its tags and unescaped.
+func plaintext(h string) string {
+	return html.UnescapeString(reTag.ReplaceAllString(h, ""))
+}
@@ -0,0 +1,172 @@
+// Package markdown converts CommonMark documents to HTML, with GitHub flavored tables.
+//
+// The output follows the conventions of the Bulma [content] element: it's expected to be rendered within an element with the class "content".
+// Tables get the "table" class, table cells alignments are rendered with the "has-text-*" classes,
+// and headings get an id generated from their text so they can be linked with anchors.
+//
+// Raw HTML is rendered as is, so ick-tags within the document are unfolded when the output is rendered with an ickcore.HTMLString.
+// Braces within code are escaped so template expressions they may contain are never evaluated.
+//
+// [content]: https://bulma.io/documentation/elements/content/
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+// ToHTML converts the markdown document src to HTML.
+func ToHTML(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\x00", "�")
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+
+	p := &parser{refs: make(map[string]linkref)}
+	blocks := p.parseblocks(lines)
+
+	r := &renderer{parser: p, ids: make(map[string]int)}
+	r.blocks(blocks, false)
+	return r.sb.String()
+}
+
+// renderer writes the html of the blocks
+type renderer struct {
+	*parser
+	sb  strings.Builder
+	ids map[string]int // heading ids already used
+}
+
+func (r *renderer) blocks(blocks []*block, tight bool) {
+	for _, b := range blocks {
+		r.block(b, tight)
+	}
+}
+
+func (r *renderer) block(b *block, tight bool) {
+	switch b.typ {
+	case BLOCK_PARAGRAPH:
+		if tight {
+			r.sb.WriteString(r.inline(b.text) + "\n")
+		} else {
+			r.sb.WriteString("<p>" + r.inline(b.text) + "</p>\n")
+		}
+
+	case BLOCK_HEADING:
+		h := r.inline(strings.TrimSpace(b.text))
+		level := strconv.Itoa(b.level)
+		r.sb.WriteString(`<h` + level + ` id="` + r.headingid(h) + `">` + h + "</h" + level + ">\n")
+
+	case BLOCK_THEMATICBREAK:
+		r.sb.WriteString("<hr />\n")
+
+	case BLOCK_CODE:
+		r.sb.WriteString("<pre><code")
+		if lang := strings.Fields(b.info); len(lang) > 0 {
+			r.sb.WriteString(` class="language-` + escapeattr(lang[0]) + `"`)
+		}
+		r.sb.WriteString(">" + ickcore.EscapeString(ickcore.ESCCTX_CODE, b.text) + "</code></pre>\n")
+
+	case BLOCK_HTML:
+		r.sb.WriteString(b.text)
+
+	case BLOCK_BLOCKQUOTE:
+		r.sb.WriteString("<blockquote>\n")
+		r.blocks(b.children, false)
+		r.sb.WriteString("</blockquote>\n")
+
+	case BLOCK_LIST:
+		tag := "ul"
+		if b.ordered {
+			tag = "ol"
+		}
+		r.sb.WriteString("<" + tag)
+		if b.ordered && b.start != 1 {
+			r.sb.WriteString(` start="` + strconv.Itoa(b.start) + `"`)
+		}
+		r.sb.WriteString(">\n")
+		for _, item := range b.children {
+			r.sb.WriteString("<li>")
+			if len(item.children) > 0 && !(b.tight && item.children[0].typ == BLOCK_PARAGRAPH) {
+				r.sb.WriteString("\n")
+			}
+			r.blocks(item.children, b.tight)
+			r.trimnewline(b.tight && len(item.children) > 0 && item.children[len(item.children)-1].typ == BLOCK_PARAGRAPH)
+			r.sb.WriteString("</li>\n")
+		}
+		r.sb.WriteString("</" + tag + ">\n")
+
+	case BLOCK_TABLE:
+		r.sb.WriteString("<table class=\"table\">\n<thead>\n")
+		r.row(b.header, b.aligns, "th")
+		r.sb.WriteString("</thead>\n")
+		if len(b.rows) > 0 {
+			r.sb.WriteString("<tbody>\n")
+			for _, row := range b.rows {
+				r.row(row, b.aligns, "td")
+			}
+			r.sb.WriteString("</tbody>\n")
+		}
+		r.sb.WriteString("</table>\n")
+	}
+}
+
+// trimnewline removes the last newline written, if trim is true.
+func (r *renderer) trimnewline(trim bool) {
+	if !trim {
+		return
+	}
+	s := r.sb.String()
+	if strings.HasSuffix(s, "\n") {
+		r.sb.Reset()
+		r.sb.WriteString(s[:len(s)-1])
+	}
+}
+
+// row writes a row of a table
+func (r *renderer) row(cells []string, aligns []ALIGN, tag string) {
+	r.sb.WriteString("<tr>\n")
+	for i, cell := range cells {
+		r.sb.WriteString("<" + tag)
+		switch aligns[i] {
+		case ALIGN_LEFT:
+			r.sb.WriteString(` class="has-text-left"`)
+		case ALIGN_CENTER:
+			r.sb.WriteString(` class="has-text-centered"`)
+		case ALIGN_RIGHT:
+			r.sb.WriteString(` class="has-text-right"`)
+		}
+		r.sb.WriteString(">" + r.inline(strings.ReplaceAll(cell, `\|`, "|")) + "</" + tag + ">\n")
+	}
+	r.sb.WriteString("</tr>\n")
+}
+
+// headingid returns a unique id for the heading, made of the lowercase letters and digits of its text separated by dashes.
+func (r *renderer) headingid(h string) string {
+	var sb strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(plaintext(h)) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '_', c > 0x7f && unicode.IsLetter(c):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(c)
+		case c == ' ' || c == '-':
+			dash = true
+		}
+	}
+	id := sb.String()
+	if id == "" {
+		id = "section"
+	}
+	n := r.ids[id]
+	r.ids[id] = n + 1
+	if n > 0 {
+		id += "-" + strconv.Itoa(n)
+	}
+	return escapeattr(id)
+}
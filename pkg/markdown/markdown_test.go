@@ -0,0 +1,57 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		html string
+	}{
+		{"paragraph", "Hello\nWorld", "<p>Hello\nWorld</p>\n"},
+		{"escape", `a < b & "c" \*d\*`, "<p>a &lt; b &amp; &quot;c&quot; *d*</p>\n"},
+		{"entity", "&copy; &nope;", "<p>&copy; &amp;nope;</p>\n"},
+		{"atx heading", "## Hello *World* ##", `<h2 id="hello-world">Hello <em>World</em></h2>` + "\n"},
+		{"setext heading", "Title\n===", `<h1 id="title">Title</h1>` + "\n"},
+		{"heading ids", "# A\n# A\n# &", `<h1 id="a">A</h1>` + "\n" + `<h1 id="a-1">A</h1>` + "\n" + `<h1 id="section">&amp;</h1>` + "\n"},
+		{"thematic break", "a\n\n***\n", "<p>a</p>\n<hr />\n"},
+		{"emphasis", "*a* _b_ **c** __d__ ***e*** **f *g* h**", "<p><em>a</em> <em>b</em> <strong>c</strong> <strong>d</strong> <em><strong>e</strong></em> <strong>f <em>g</em> h</strong></p>\n"},
+		{"intraword underscore", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"unmatched", "a * b **c", "<p>a * b **c</p>\n"},
+		{"code span", "`a <b>` `` c`d ``", "<p><code>a &lt;b&gt;</code> <code>c`d</code></p>\n"},
+		{"code braces", "`{{.Name}}`", "<p><code>&#123;&#123;.Name}}</code></p>\n"},
+		{"hard break", "a  \nb\\\nc", "<p>a<br />\nb<br />\nc</p>\n"},
+		{"link", `[a *b*](/url "title")`, `<p><a href="/url" title="title">a <em>b</em></a></p>` + "\n"},
+		{"link reference", "[x]\n\n[X]: /u 'T'", `<p><a href="/u" title="T">x</a></p>` + "\n"},
+		{"not a link", "[x] (y)", "<p>[x] (y)</p>\n"},
+		{"unsafe link", "[x](javascript:alert(1))", `<p><a href="#ick-unsafe-url">x</a></p>` + "\n"},
+		{"link encoding", "[x](/a b)", "<p>[x](/a b)</p>\n"},
+		{"link percent", "[x](</a b?c=1&d=é>)", `<p><a href="/a%20b?c=1&amp;d=%C3%A9">x</a></p>` + "\n"},
+		{"image", `![an *image*](/a.png "t")`, `<p><img src="/a.png" alt="an image" title="t" /></p>` + "\n"},
+		{"autolink", "<https://icecake.dev> <me@icecake.dev>", `<p><a href="https://icecake.dev">https://icecake.dev</a> <a href="mailto:me@icecake.dev">me@icecake.dev</a></p>` + "\n"},
+		{"inline html", `a <ick-icon key="bi bi-x"/> <b>b</b>`, `<p>a <ick-icon key="bi bi-x"/> <b>b</b></p>` + "\n"},
+		{"html block", "<div>\n*a*\n</div>\n\n*b*", "<div>\n*a*\n</div>\n<p><em>b</em></p>\n"},
+		{"ick-tag block", "<ick-button title=\"ok\"/>\ntext", "<ick-button title=\"ok\"/>\ntext\n"},
+		{"html comment", "<!-- a\n\nb -->\nc", "<!-- a\n\nb -->\n<p>c</p>\n"},
+		{"fenced code", "```go\nfunc() {\n  <x>\n}\n```", `<pre><code class="language-go">func() &#123;` + "\n  &lt;x&gt;\n}\n</code></pre>\n"},
+		{"unclosed fence", "~~~\na", "<pre><code>a\n</code></pre>\n"},
+		{"indented code", "    a\n\n    b\n\nc", "<pre><code>a\n\nb\n</code></pre>\n<p>c</p>\n"},
+		{"blockquote", "> a\nb\n> > c", "<blockquote>\n<p>a\nb</p>\n<blockquote>\n<p>c</p>\n</blockquote>\n</blockquote>\n"},
+		{"tight list", "- a\n- b\n  - c\n+ d", "<ul>\n<li>a</li>\n<li>b\n<ul>\n<li>c</li>\n</ul>\n</li>\n</ul>\n<ul>\n<li>d</li>\n</ul>\n"},
+		{"loose list", "1. a\n\n2. b", "<ol>\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ol>\n"},
+		{"ordered start", "3) a\n4) b", "<ol start=\"3\">\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"list item paragraphs", "- a\n\n  b", "<ul>\n<li>\n<p>a</p>\n<p>b</p>\n</li>\n</ul>\n"},
+		{"list after paragraph", "a\n- b", "<p>a</p>\n<ul>\n<li>b</li>\n</ul>\n"},
+		{"table", "| a | b | c |\n|:-|:-:|-:|\n| 1 | `x` | 2 \\| 3 |\n| 4 |", "<table class=\"table\">\n<thead>\n<tr>\n<th class=\"has-text-left\">a</th>\n<th class=\"has-text-centered\">b</th>\n<th class=\"has-text-right\">c</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td class=\"has-text-left\">1</td>\n<td class=\"has-text-centered\"><code>x</code></td>\n<td class=\"has-text-right\">2 | 3</td>\n</tr>\n<tr>\n<td class=\"has-text-left\">4</td>\n<td class=\"has-text-centered\"></td>\n<td class=\"has-text-right\"></td>\n</tr>\n</tbody>\n</table>\n"},
+		{"not a table", "a | b\n-- | -- | --", "<p>a | b\n-- | -- | --</p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.html, ToHTML(tt.md))
+		})
+	}
+}
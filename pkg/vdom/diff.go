@@ -0,0 +1,90 @@
+package vdom
+
+import (
+	"html"
+)
+
+// PATCH_OP is the operation of a patch
+type PATCH_OP int
+
+const (
+	PATCH_SETATTR    PATCH_OP = iota // set the attribute Name of the element at Path to Value
+	PATCH_REMOVEATTR                 // remove the attribute Name of the element at Path
+	PATCH_TEXT                       // set the content of the text or the comment at Path to Value
+	PATCH_INSERT                     // insert HTML as the child Index of the node at Path
+	PATCH_REMOVE                     // remove the node at Path
+	PATCH_REPLACE                    // replace the node at Path with HTML
+)
+
+// Patch is a minimal change to apply to the DOM.
+//
+// Path is the list of the indexes of the nodes, from the root to the patched node, in the childNodes of their parent.
+// Patches must be applied in the order they're returned by Diff, the paths take into account the previous patches.
+type Patch struct {
+	Op    PATCH_OP
+	Path  []int
+	Type  NODE_TYPE // the expected type of the patched node, or of the parent for PATCH_INSERT
+	Tag   string    // the expected tag of the patched element, or of the parent for PATCH_INSERT, empty for a text or a comment or the root
+	Name  string    // the name of the attribute for PATCH_SETATTR and PATCH_REMOVEATTR
+	Value string    // the unescaped value of the attribute for PATCH_SETATTR, or the content for PATCH_TEXT
+	Index int       // the index of the inserted node for PATCH_INSERT
+	HTML  string    // the HTML of the node to insert or to replace with
+}
+
+// Diff returns the patches to apply to the DOM rendered from old to get the DOM rendered from new.
+//
+// Nodes are compared by position. Two elements at the same position are patched if they have the same tag and the same Key,
+// otherwise the old element is replaced. Children added at the end are inserted, children missing at the end are removed.
+// An element whose output didn't change is never touched.
+func Diff(old *Node, new *Node) []Patch {
+	patches := make([]Patch, 0)
+	return diffnode(patches, nil, old, new)
+}
+
+func diffnode(patches []Patch, path []int, old *Node, new *Node) []Patch {
+	switch {
+	case old.Type != new.Type || old.Tag != new.Tag || old.Key() != new.Key():
+		return append(patches, Patch{Op: PATCH_REPLACE, Path: clonepath(path), Type: old.Type, Tag: old.Tag, HTML: new.HTML()})
+
+	case old.Type == NODE_TEXT:
+		if old.Text != new.Text {
+			patches = append(patches, Patch{Op: PATCH_TEXT, Path: clonepath(path), Type: NODE_TEXT, Value: html.UnescapeString(new.Text)})
+		}
+		return patches
+
+	case old.Type == NODE_COMMENT:
+		if old.Text != new.Text {
+			patches = append(patches, Patch{Op: PATCH_TEXT, Path: clonepath(path), Type: NODE_COMMENT, Value: new.Text})
+		}
+		return patches
+	}
+
+	// attributes
+	for _, na := range new.Attrs {
+		if ov, found := old.Attribute(na.Name); !found || ov != na.Value {
+			patches = append(patches, Patch{Op: PATCH_SETATTR, Path: clonepath(path), Type: new.Type, Tag: new.Tag, Name: na.Name, Value: html.UnescapeString(na.Value)})
+		}
+	}
+	for _, oa := range old.Attrs {
+		if _, found := new.Attribute(oa.Name); !found {
+			patches = append(patches, Patch{Op: PATCH_REMOVEATTR, Path: clonepath(path), Type: new.Type, Tag: new.Tag, Name: oa.Name})
+		}
+	}
+
+	// children
+	nold, nnew := len(old.Children), len(new.Children)
+	for i := 0; i < nold && i < nnew; i++ {
+		patches = diffnode(patches, append(path, i), old.Children[i], new.Children[i])
+	}
+	for i := nold; i < nnew; i++ {
+		patches = append(patches, Patch{Op: PATCH_INSERT, Path: clonepath(path), Type: new.Type, Tag: new.Tag, Index: i, HTML: new.Children[i].HTML()})
+	}
+	for i := nold - 1; i >= nnew; i-- {
+		patches = append(patches, Patch{Op: PATCH_REMOVE, Path: clonepath(append(path, i)), Type: old.Children[i].Type, Tag: old.Children[i].Tag})
+	}
+	return patches
+}
+
+func clonepath(path []int) []int {
+	return append([]int{}, path...)
+}
@@ -0,0 +1,110 @@
+// Package vdom provides a lightweight tree of the HTML rendered by the composers, and the diff between two renderings.
+// The diff is a list of patches that can be applied to the DOM, see dom.UI.RefreshContent.
+package vdom
+
+import (
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+// NODE_TYPE is the type of a node of the tree
+type NODE_TYPE int
+
+const (
+	NODE_FRAGMENT NODE_TYPE = iota // the root of a tree, a list of nodes without tag
+	NODE_ELEMENT                   // an html element
+	NODE_TEXT                      // a text
+	NODE_COMMENT                   // an html comment
+)
+
+// Attribute is an attribute of an element. Value is raw, like in the HTML source.
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// Node is a node of the tree.
+type Node struct {
+	Type     NODE_TYPE
+	Tag      string      // lowercase tag name of an element
+	Attrs    []Attribute // attributes of an element, in the order of the source
+	Text     string      // raw content of a text or a comment
+	Children []*Node
+}
+
+// Attribute returns the raw value of the attribute aname and if it has been found.
+func (n *Node) Attribute(aname string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name == aname {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// Key returns the key identifying an element among its siblings: its ickcore.KEY_DATA_ATTRIBUTE if any, otherwise its id.
+func (n *Node) Key() string {
+	if n.Type != NODE_ELEMENT {
+		return ""
+	}
+	if key, found := n.Attribute(ickcore.KEY_DATA_ATTRIBUTE); found {
+		return key
+	}
+	id, _ := n.Attribute("id")
+	return id
+}
+
+// HTML returns the HTML string of the node.
+func (n *Node) HTML() string {
+	var sb strings.Builder
+	n.write(&sb)
+	return sb.String()
+}
+
+func (n *Node) write(sb *strings.Builder) {
+	switch n.Type {
+	case NODE_TEXT:
+		sb.WriteString(n.Text)
+		return
+	case NODE_COMMENT:
+		sb.WriteString("<!--" + n.Text + "-->")
+		return
+	case NODE_ELEMENT:
+		sb.WriteString("<" + n.Tag)
+		for _, a := range n.Attrs {
+			sb.WriteString(" " + a.Name + `="` + strings.ReplaceAll(a.Value, `"`, "&#34;") + `"`)
+		}
+		sb.WriteString(">")
+		if IsVoidElement(n.Tag) {
+			return
+		}
+	}
+	for _, c := range n.Children {
+		c.write(sb)
+	}
+	if n.Type == NODE_ELEMENT {
+		sb.WriteString("</" + n.Tag + ">")
+	}
+}
+
+// voidelements are the elements without closing tag
+var voidelements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawtextelements are the elements with a content not parsed as HTML
+var rawtextelements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// IsVoidElement returns true if the lowercase tag is an element without closing tag, like br or img.
+func IsVoidElement(tag string) bool {
+	return voidelements[tag]
+}
+
+// IsRawTextElement returns true if the content of the element with the lowercase tag is not parsed as HTML, like script or textarea.
+func IsRawTextElement(tag string) bool {
+	return rawtextelements[tag]
+}
@@ -0,0 +1,162 @@
+package vdom
+
+import (
+	"strings"
+)
+
+// Parse parses an HTML string rendered by the composers and returns a tree of its nodes, under a NODE_FRAGMENT root.
+//
+// Parse is lenient and never fails: closing tags without opening tag are ignored and elements not closed are closed at the end of the string.
+// Unlike browsers Parse does not close elements implicitly, so the HTML is expected to be well formed.
+func Parse(s string) *Node {
+	root := &Node{Type: NODE_FRAGMENT}
+	stack := []*Node{root}
+	current := func() *Node { return stack[len(stack)-1] }
+	addtext := func(txt string) {
+		if txt != "" {
+			current().Children = append(current().Children, &Node{Type: NODE_TEXT, Text: txt})
+		}
+	}
+
+	i := 0
+	for i < len(s) {
+		lt := strings.IndexByte(s[i:], '<')
+		if lt < 0 {
+			addtext(s[i:])
+			break
+		}
+		addtext(s[i : i+lt])
+		i += lt
+
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			end := strings.Index(s[i+4:], "-->")
+			if end < 0 {
+				current().Children = append(current().Children, &Node{Type: NODE_COMMENT, Text: s[i+4:]})
+				i = len(s)
+				break
+			}
+			current().Children = append(current().Children, &Node{Type: NODE_COMMENT, Text: s[i+4 : i+4+end]})
+			i += 4 + end + 3
+
+		case strings.HasPrefix(s[i:], "</"):
+			gt := strings.IndexByte(s[i:], '>')
+			if gt < 0 {
+				addtext(s[i:])
+				i = len(s)
+				break
+			}
+			tag := strings.ToLower(strings.TrimSpace(s[i+2 : i+gt]))
+			i += gt + 1
+			// close up to the matching element, ignore the closing tag if there's none
+			for j := len(stack) - 1; j > 0; j-- {
+				if stack[j].Tag == tag {
+					stack = stack[:j]
+					break
+				}
+			}
+
+		case i+1 < len(s) && IsLetter(s[i+1]):
+			n, next := ParseTag(s, i)
+			current().Children = append(current().Children, n)
+			i = next
+			if IsVoidElement(n.Tag) || (next >= 2 && s[next-2] == '/') {
+				break
+			}
+			if IsRawTextElement(n.Tag) {
+				end := IndexFold(s[i:], "</"+n.Tag)
+				if end < 0 {
+					end = len(s) - i
+				}
+				if end > 0 {
+					n.Children = append(n.Children, &Node{Type: NODE_TEXT, Text: s[i : i+end]})
+				}
+				i += end
+				if gt := strings.IndexByte(s[i:], '>'); gt >= 0 {
+					i += gt + 1
+				}
+				break
+			}
+			stack = append(stack, n)
+
+		default:
+			// a single '<' within a text
+			addtext("<")
+			i++
+		}
+	}
+	return root
+}
+
+// ParseTag parses the opening tag starting at s[at] and returns the element and the position following the tag.
+// A '>' within a quoted attribute value does not end the tag. The element has no children.
+func ParseTag(s string, at int) (n *Node, next int) {
+	n = &Node{Type: NODE_ELEMENT}
+	i := at + 1
+	start := i
+	for i < len(s) && !isspace(s[i]) && s[i] != '>' && s[i] != '/' {
+		i++
+	}
+	n.Tag = strings.ToLower(s[start:i])
+
+	for i < len(s) {
+		for i < len(s) && (isspace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '>' {
+			return n, i + 1
+		}
+
+		// attribute name
+		start = i
+		for i < len(s) && !isspace(s[i]) && s[i] != '=' && s[i] != '>' && !(s[i] == '/' && i+1 < len(s) && s[i+1] == '>') {
+			i++
+		}
+		a := Attribute{Name: strings.ToLower(s[start:i])}
+		for i < len(s) && isspace(s[i]) {
+			i++
+		}
+
+		// attribute value
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isspace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				q := s[i]
+				end := strings.IndexByte(s[i+1:], q)
+				if end < 0 {
+					end = len(s) - i - 1
+				}
+				a.Value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start = i
+				for i < len(s) && !isspace(s[i]) && s[i] != '>' {
+					i++
+				}
+				a.Value = s[start:i]
+			}
+		}
+		n.Attrs = append(n.Attrs, a)
+	}
+	return n, len(s)
+}
+
+// IsLetter returns true if b is an ASCII letter, the first character of a tag name.
+func IsLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isspace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+// IndexFold returns the index of the first case insensitive occurrence of substr in s, -1 if not found.
+func IndexFold(s string, substr string) int {
+	return strings.Index(strings.ToLower(s), strings.ToLower(substr))
+}
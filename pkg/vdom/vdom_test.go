@@ -0,0 +1,80 @@
+package vdom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		In   string
+		Want string
+	}{
+		{In: ``, Want: ``},
+		{In: `text`, Want: `text`},
+		{In: `<p>a &amp; b</p>`, Want: `<p>a &amp; b</p>`},
+		{In: `<DIV class='c "q"' hidden data-x=1><br><img src="i.png"/>x</div>`, Want: `<div class="c &#34;q&#34;" hidden="" data-x="1"><br><img src="i.png">x</div>`},
+		{In: `<!-- c --><span>s</span>`, Want: `<!-- c --><span>s</span>`},
+		{In: `<script>if (a<b) {}</script><p>`, Want: `<script>if (a<b) {}</script><p></p>`},
+		{In: `a < b </i><b>c`, Want: `a < b <b>c</b>`},
+		{In: "<ul>\n  <li>1</li>\n</ul>", Want: "<ul>\n  <li>1</li>\n</ul>"},
+	}
+	for i, tst := range tests {
+		assert.Equal(t, tst.Want, Parse(tst.In).HTML(), "test %d", i)
+	}
+
+	n := Parse(`<div id="d1" data-ick-key="k1">x</div><p id="p1"></p>`)
+	require.Len(t, n.Children, 2)
+	assert.Equal(t, "k1", n.Children[0].Key())
+	assert.Equal(t, "p1", n.Children[1].Key())
+	assert.Equal(t, NODE_TEXT, n.Children[0].Children[0].Type)
+}
+
+func TestDiff(t *testing.T) {
+
+	// no change
+	old := Parse(`<div class="a"><button id="b1">ok</button>text</div>`)
+	assert.Empty(t, Diff(old, Parse(`<div class="a"><button id="b1">ok</button>text</div>`)))
+
+	// attributes and text
+	patches := Diff(old, Parse(`<div class="b" hidden><button id="b1">ok</button>new &amp; text</div>`))
+	assert.Equal(t, []Patch{
+		{Op: PATCH_SETATTR, Path: []int{0}, Type: NODE_ELEMENT, Tag: "div", Name: "class", Value: "b"},
+		{Op: PATCH_SETATTR, Path: []int{0}, Type: NODE_ELEMENT, Tag: "div", Name: "hidden", Value: ""},
+		{Op: PATCH_TEXT, Path: []int{0, 1}, Type: NODE_TEXT, Value: "new & text"},
+	}, patches)
+
+	patches = Diff(old, Parse(`<div><button id="b1">ok</button>text</div>`))
+	assert.Equal(t, []Patch{{Op: PATCH_REMOVEATTR, Path: []int{0}, Type: NODE_ELEMENT, Tag: "div", Name: "class"}}, patches)
+
+	// replace a node with another tag or another key
+	patches = Diff(old, Parse(`<div class="a"><button id="b2">ok</button><i>text</i></div>`))
+	assert.Equal(t, []Patch{
+		{Op: PATCH_REPLACE, Path: []int{0, 0}, Type: NODE_ELEMENT, Tag: "button", HTML: `<button id="b2">ok</button>`},
+		{Op: PATCH_REPLACE, Path: []int{0, 1}, Type: NODE_TEXT, HTML: `<i>text</i>`},
+	}, patches)
+
+	// insert and remove
+	old = Parse(`<ul><li>1</li></ul>`)
+	patches = Diff(old, Parse(`<ul><li>1</li><li>2</li><li>3</li></ul>`))
+	assert.Equal(t, []Patch{
+		{Op: PATCH_INSERT, Path: []int{0}, Type: NODE_ELEMENT, Tag: "ul", Index: 1, HTML: `<li>2</li>`},
+		{Op: PATCH_INSERT, Path: []int{0}, Type: NODE_ELEMENT, Tag: "ul", Index: 2, HTML: `<li>3</li>`},
+	}, patches)
+
+	old = Parse(`<ul><li>1</li><li>2</li><li>3</li></ul>x`)
+	patches = Diff(old, Parse(`<ul><li>1</li></ul>`))
+	assert.Equal(t, []Patch{
+		{Op: PATCH_REMOVE, Path: []int{0, 2}, Type: NODE_ELEMENT, Tag: "li"},
+		{Op: PATCH_REMOVE, Path: []int{0, 1}, Type: NODE_ELEMENT, Tag: "li"},
+		{Op: PATCH_REMOVE, Path: []int{1}, Type: NODE_TEXT},
+	}, patches)
+
+	// comments
+	old = Parse(`<!--a-->`)
+	patches = Diff(old, Parse(`<!--b-->`))
+	assert.Equal(t, []Patch{{Op: PATCH_TEXT, Path: []int{0}, Type: NODE_COMMENT, Value: "b"}}, patches)
+}
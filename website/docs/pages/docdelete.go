@@ -37,6 +37,9 @@ func (sec *SectionDocDelete) RenderContent(out io.Writer) error {
 		&ick.ICKDelete{TargetId: "Idone"},
 		&ick.ICKDelete{TargetId: "Idtwo", SIZE: ick.SIZE_LARGE})
 	ickcore.RenderString(out, `</div>`)
+	sec.RenderExample(out, `ickcore.RenderChild(out, parent,
+	&ick.ICKDelete{TargetId: "Idone"},
+	&ick.ICKDelete{TargetId: "Idtwo", SIZE: ick.SIZE_LARGE})`)
 
 	return nil
 }
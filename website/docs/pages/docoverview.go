@@ -1,16 +1,20 @@
 package webdocs
 
 import (
+	_ "embed"
 	"io"
 
 	"github.com/icecake-framework/icecake/pkg/ick"
 	"github.com/icecake-framework/icecake/pkg/ickcore"
 )
 
+//go:embed docoverview.md
+var docoverview string
+
 type SectionDocOverview struct{ SectionDocIcecake }
 
 func (sec *SectionDocOverview) RenderContent(out io.Writer) error {
-	ickcore.RenderChild(out, sec, ick.Title(3, "Overview"))
+	ickcore.RenderChild(out, sec, ick.Markdown(docoverview))
 
 	return nil
 }
@@ -0,0 +1,223 @@
+package webdocs
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/icecake-framework/icecake/pkg/i18n"
+	"github.com/icecake-framework/icecake/pkg/ick"
+	"github.com/icecake-framework/icecake/pkg/ickcore"
+)
+
+func init() {
+	i18n.DefaultBundle.Add("en", "docs.hello", i18n.Message{Other: "Hello <b>{name}</b>"})
+}
+
+// overrides maps the ick-tag names of registered composers to their hand-written doc page.
+var overrides = map[string]DocPage{
+	"ick-button":  {Key: "docbutton", Label: "Button"},
+	"ick-card":    {Key: "doccard", Label: "Card"},
+	"ick-delete":  {Key: "docdelete", Label: "Delete"},
+	"ick-hero":    {Key: "dochero", Label: "Hero"},
+	"ick-image":   {Key: "docimage", Label: "Image"},
+	"ick-input":   {Key: "docinput", Label: "Input"},
+	"ick-message": {Key: "docmessage", Label: "Message"},
+	"ick-tag":     {Key: "doctaglabel", Label: "Tag Label"},
+}
+
+// handwritten lists the hand-written doc pages of composers without ick-tag, by key.
+var handwritten = map[string]string{
+	"docicon":   "Icon",
+	"docmenu":   "Menu",
+	"docnavbar": "Navbar",
+	"docmedia":  "media",
+}
+
+// examples maps the ick-tag names of registered composers to the example of their generated reference page,
+// when the default values of the properties are not enough to render something.
+var examples = map[string]string{
+	"ick-codeblock":    "<ick-codeblock language=\"go\" line-numbers highlight=\"2\">\n\tfunc main() {\n\t\tfmt.Println(\"hello\")\n\t}\n</ick-codeblock>",
+	"ick-datetime":     `<ick-datetime time="2023-03-05T14:07:00Z" date-style="long" with-time/>`,
+	"ick-markdown":     `<ick-markdown>Some **markdown** text with a [link](https://github.com/icecake-framework/icecake).</ick-markdown>`,
+	"ick-number":       `<ick-number value="1234.5" decimals="2"/>`,
+	"ick-relativetime": `<ick-relativetime time="2023-03-05T14:07:00Z"/>`,
+	"ick-t":            `<ick-t key="docs.hello" name="Bob"/>`,
+}
+
+// DocPage is an entry of the documentation menu
+type DocPage struct {
+	Key   string // the key of the page, the name of its html file
+	Label string // the label of the page in the menu
+}
+
+// ComponentPages returns the doc pages of the components sorted by label: one page per registered ick-tag,
+// the hand-written one if any otherwise a generated reference page, and the hand-written pages of the composers without ick-tag.
+func ComponentPages() []DocPage {
+	pages := make([]DocPage, 0)
+	for name, schema := range ickcore.Schemas() {
+		if page, found := overrides[name]; found {
+			pages = append(pages, page)
+			continue
+		}
+		pages = append(pages, DocPage{Key: ReferenceKey(name), Label: typename(schema.Type)})
+	}
+	for key, label := range handwritten {
+		pages = append(pages, DocPage{Key: key, Label: label})
+	}
+	sort.Slice(pages, func(i, j int) bool { return strings.ToLower(pages[i].Label) < strings.ToLower(pages[j].Label) })
+	return pages
+}
+
+// ReferenceKey returns the key of the doc page of the registered icktagname,
+// the key of its hand-written page if any, otherwise `doc{name}` with name the ick-tag name without the `ick-` prefix and dashes.
+func ReferenceKey(icktagname string) string {
+	if page, found := overrides[icktagname]; found {
+		return page.Key
+	}
+	return "doc" + strings.ReplaceAll(strings.TrimPrefix(icktagname, "ick-"), "-", "")
+}
+
+// referenceSection returns the generated reference section of the registered composer with the key, nil if none.
+func referenceSection(key string) *SectionDocReference {
+	for name, schema := range ickcore.Schemas() {
+		if ReferenceKey(name) == key {
+			s := new(SectionDocReference)
+			s.Title = typename(schema.Type) + " snippet - icecake framework documentation"
+			s.Schema = schema
+			return s
+		}
+	}
+	return nil
+}
+
+/******************************************************************************/
+
+// SectionDocReference is a reference page generated from the schema of a registered composer,
+// with the ick-tag syntax, an example and the table of the properties.
+type SectionDocReference struct {
+	SectionDocIcecake
+	Schema ickcore.ComposerSchema
+}
+
+func (sec *SectionDocReference) RenderContent(out io.Writer) error {
+	gostruct := sec.Schema.Type[strings.LastIndex(sec.Schema.Type, ".")+1:]
+	ickcore.RenderString(out, `<div class="is-flex is-justify-content-space-between">`)
+	ickcore.RenderChild(out, sec, ick.Title(3, typename(sec.Schema.Type), `style="white-space: nowrap;"`))
+	ickcore.RenderChild(out, sec, ick.Button(gostruct+" Go pkg").
+		SetSize(ick.SIZE_SMALL).
+		SetColor(ick.COLOR_LINK).
+		SetOutlined(true).
+		ParseHRef(href_GoPkg+"/"+pkgname(sec.Schema.Type)+"#"+gostruct).
+		SetIcon(*ick.Icon("bi bi-book"), false))
+	ickcore.RenderString(out, `</div>`)
+
+	ickcore.RenderString(out, `<div class="block">`)
+	if sec.Schema.Doc != "" {
+		ickcore.RenderString(out, `<p>`)
+		ickcore.RenderText(out, sec.Schema.Doc)
+		ickcore.RenderString(out, `</p>`)
+	}
+	ickcore.RenderString(out, `<p>`, html.EscapeString(sec.Schema.Type), ` is registered with the ick-tag <code>`, sec.Schema.IckTagName, `</code>.</p>`)
+	ickcore.RenderString(out, `</div>`)
+
+	// syntax
+	ickcore.RenderChild(out, sec, ick.Title(3, "Syntax"))
+	ickcore.RenderChild(out, sec, ick.CodeBlock("html", Syntax(sec.Schema)))
+
+	// example, skipped if it renders nothing
+	example := Example(sec.Schema)
+	rendered := new(bytes.Buffer)
+	ickcore.RenderChild(ickcore.Redirect(rendered, out), sec, ickcore.ToHTML(example))
+	if strings.TrimSpace(rendered.String()) != "" {
+		ickcore.RenderChild(out, sec, ick.Title(3, "Example"))
+		ickcore.RenderString(out, `<div class="box spaceout">`, rendered.String(), `</div>`)
+		ickcore.RenderChild(out, sec, ick.CodeBlock("html", example))
+	}
+
+	// properties
+	ickcore.RenderChild(out, sec, ick.Title(3, "Properties"))
+	if len(sec.Schema.Properties) == 0 {
+		ickcore.RenderString(out, `<p>No property.</p>`)
+		return nil
+	}
+	ickcore.RenderString(out, `<div class="table-container"><table class="table is-striped is-narrow is-fullwidth">`,
+		`<thead><tr><th>Attribute</th><th>Type</th><th>Default</th><th>Values</th><th>Description</th></tr></thead><tbody>`)
+	for _, p := range sec.Schema.Properties {
+		ickcore.RenderString(out, `<tr><td><code>`, p.Name, `</code>`)
+		ickcore.RenderStringIf(p.Required, out, ` <span class="tag is-warning is-light">required</span>`)
+		ickcore.RenderString(out, `</td><td>`, p.Kind, ` <span class="has-text-grey">`, html.EscapeString(p.Type), `</span></td><td>`)
+		if p.Default != nil {
+			ickcore.RenderString(out, `<code>`, html.EscapeString(*p.Default), `</code>`)
+		}
+		ickcore.RenderString(out, `</td><td>`)
+		for i, v := range p.Enum {
+			ickcore.RenderStringIf(i > 0, out, " ")
+			ickcore.RenderString(out, `<code>`, html.EscapeString(v), `</code>`)
+		}
+		ickcore.RenderString(out, `</td><td>`)
+		ickcore.RenderText(out, p.Doc)
+		ickcore.RenderString(out, `</td></tr>`)
+	}
+	ickcore.RenderString(out, `</tbody></table></div>`)
+	return nil
+}
+
+// Syntax returns the ick-tag syntax of the composer, with every attribute and its kind.
+func Syntax(schema ickcore.ComposerSchema) string {
+	var sb strings.Builder
+	sb.WriteString("<" + schema.IckTagName)
+	for _, p := range schema.Properties {
+		sb.WriteString("\n\t" + p.Name)
+		if p.Kind == "boolean" {
+			continue
+		}
+		value := p.Kind
+		if len(p.Enum) > 0 {
+			value = strings.Join(p.Enum, "|")
+		}
+		sb.WriteString(`="` + value + `"`)
+	}
+	if schema.Slot {
+		sb.WriteString(">\n\tcontent\n</" + schema.IckTagName + ">")
+	} else {
+		sb.WriteString("/>")
+	}
+	return sb.String()
+}
+
+// Example returns the example ick-tag of the composer if any, otherwise an ick-tag with the default values of its properties.
+func Example(schema ickcore.ComposerSchema) string {
+	if example, found := examples[schema.IckTagName]; found {
+		return example
+	}
+	var sb strings.Builder
+	sb.WriteString("<" + schema.IckTagName)
+	for _, p := range schema.Properties {
+		if p.Default != nil {
+			sb.WriteString(" " + p.Name + `="` + html.EscapeString(*p.Default) + `"`)
+		}
+	}
+	if schema.Slot {
+		sb.WriteString(">Content</" + schema.IckTagName + ">")
+	} else {
+		sb.WriteString("/>")
+	}
+	return sb.String()
+}
+
+// typename returns the name of the type without its package and the ICK prefix, `*ick.ICKCodeBlock` gives `CodeBlock`.
+func typename(typ string) string {
+	return strings.TrimPrefix(typ[strings.LastIndex(typ, ".")+1:], "ICK")
+}
+
+// pkgname returns the package of the type, `*ick.ICKCodeBlock` gives `ick`.
+func pkgname(typ string) string {
+	typ = strings.TrimPrefix(typ, "*")
+	if i := strings.LastIndex(typ, "."); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
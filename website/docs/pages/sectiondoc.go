@@ -78,6 +78,9 @@ func SectionDoc(section string) ickcore.ContentComposer {
 		s.Title = "Media Snippet - icecake framework documentation"
 		return s
 	}
+	if s := referenceSection(section); s != nil {
+		return s
+	}
 	s := new(SectionDocIcecake)
 	s.Title = "icecake framework documentation"
 	return s
@@ -133,3 +136,8 @@ func (sec *SectionDocIcecake) RenderHead(out io.Writer, title string, gitpkg str
 	ickcore.RenderString(out, `</div>`)
 	return nil
 }
+
+// RenderExample renders an example of go code with syntax highlighting.
+func (sec *SectionDocIcecake) RenderExample(out io.Writer, code string) error {
+	return ickcore.RenderChild(out, sec, ick.CodeBlock("go", code))
+}